@@ -0,0 +1,73 @@
+package checkpoint
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+type badgerStore struct{ db *badger.DB }
+
+func NewBadgerStore(db *badger.DB) *badgerStore { return &badgerStore{db} }
+
+var _ (Store) = (*badgerStore)(nil)
+
+func (s *badgerStore) Read(jobID string) (*Checkpoint, error) {
+	var model *checkpointModel
+
+	if err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(jobID))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return &errors.Error{
+					Code:            errors.CodeNotFound,
+					Message:         "Checkpoint not found",
+					UnderlyingError: err,
+				}
+			} else {
+				return err
+			}
+		}
+
+		if err := item.Value(func(encodedModel []byte) error {
+			model, err = decodeCheckpointModel(encodedModel)
+
+			return err
+		}); err != nil {
+			return fmt.Errorf("could not decode Badger checkpoint model: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return mapModelToCheckpoint(model), nil
+}
+
+func (s *badgerStore) Write(checkpoint *Checkpoint) error {
+	encodedModel, err := mapCheckpointToModel(checkpoint).encode()
+	if err != nil {
+		return fmt.Errorf("could not encode Badger checkpoint model: %w", err)
+	}
+
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(badgerKey(checkpoint.JobID), encodedModel)
+	}); err != nil {
+		return fmt.Errorf("could not write checkpoint model to Badger DB: %w", err)
+	}
+
+	return nil
+}
+
+func (s *badgerStore) Delete(jobID string) error {
+	if err := s.db.Update(func(txn *badger.Txn) error { return txn.Delete(badgerKey(jobID)) }); err != nil {
+		return fmt.Errorf("could not delete checkpoint model from Badger DB: %w", err)
+	}
+
+	return nil
+}
+
+// badgerKey of the given job. Keys are prefixed so the checkpoints can share a database with other data.
+func badgerKey(jobID string) []byte { return []byte("checkpoint:" + jobID) }
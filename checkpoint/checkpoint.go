@@ -0,0 +1,191 @@
+// Package checkpoint makes long scans through a pagination.Pager resumable. After each processed page, the last key of
+// the keyset reader and the number of processed elements are committed to a store, so a job restarted after a crash
+// resumes from its last checkpoint instead of from scratch. Pages are processed at least once: the pages processed
+// after the last checkpoint are processed again when resuming.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/pagination"
+)
+
+// Checkpoint of a job.
+type Checkpoint struct {
+	JobID     string
+	LastKey   json.RawMessage
+	Processed int
+	UpdatedAt time.Time
+}
+
+// Store of checkpoints. Reading a missing checkpoint must return an error with the errors.CodeNotFound code.
+type Store interface {
+	Read(jobID string) (*Checkpoint, error)
+	Write(checkpoint *Checkpoint) error
+	Delete(jobID string) error
+}
+
+// TaskProgressSetter is implemented by restasks.Server.
+type TaskProgressSetter interface {
+	SetTaskProgress(rid string, progress int) error
+}
+
+// Job scanning the pages of a keyset pager.
+type Job[S ~[]E, E, K any, R KeysetPageReader[S, E, K]] struct {
+	id    string
+	store Store
+	pager *pagination.Pager[S, E, R]
+
+	taskProgressSetter TaskProgressSetter
+	taskRID            string
+	total              int
+	now                func() time.Time
+}
+
+// KeysetPageReader is a page reader whose position can be saved and restored.
+type KeysetPageReader[S ~[]E, E, K any] interface {
+	pagination.PageReader[S, E]
+	pagination.KeysetReader[K]
+}
+
+type JobOption func(*jobConfig)
+
+type jobConfig struct {
+	taskProgressSetter TaskProgressSetter
+	taskRID            string
+	total              int
+	now                func() time.Time
+}
+
+// WithJobTaskProgress reports the progress of the job to the given task (e.g. with a restasks.Server), based on the
+// expected total number of elements.
+func WithJobTaskProgress(setter TaskProgressSetter, taskRID string, total int) JobOption {
+	return func(config *jobConfig) {
+		config.taskProgressSetter = setter
+		config.taskRID = taskRID
+		config.total = total
+	}
+}
+
+func WithJobNow(now func() time.Time) JobOption {
+	return func(config *jobConfig) { config.now = now }
+}
+
+// NewJob creates a job with the given ID, which must be stable across restarts (e.g. derived from the scanned entity).
+func NewJob[S ~[]E, E, K any, R KeysetPageReader[S, E, K]](
+	id string,
+	store Store,
+	pager *pagination.Pager[S, E, R],
+	options ...JobOption,
+) *Job[S, E, K, R] {
+	config := &jobConfig{now: time.Now}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return &Job[S, E, K, R]{
+		id:                 id,
+		store:              store,
+		pager:              pager,
+		taskProgressSetter: config.taskProgressSetter,
+		taskRID:            config.taskRID,
+		total:              config.total,
+		now:                config.now,
+	}
+}
+
+// Run the job from its last checkpoint, calling processPageFunc for each page. The checkpoint is deleted when the job
+// completes, so the next run starts from scratch. It returns the total number of processed elements.
+func (j *Job[S, E, K, R]) Run(ctx context.Context, processPageFunc func(ctx context.Context, page S) error) (int, error) {
+	processed, err := j.restore()
+	if err != nil {
+		return processed, err
+	}
+
+	if err := j.setTaskProgress(processed); err != nil {
+		return processed, err
+	}
+
+	for page, err := range j.pager.Pages() {
+		if err != nil {
+			return processed, err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+
+		if err := processPageFunc(ctx, page); err != nil {
+			return processed, err
+		}
+
+		processed += len(page)
+
+		if err := j.commit(processed); err != nil {
+			return processed, err
+		}
+
+		if err := j.setTaskProgress(processed); err != nil {
+			return processed, err
+		}
+	}
+
+	if err := j.store.Delete(j.id); err != nil {
+		return processed, fmt.Errorf("could not delete checkpoint: %w", err)
+	}
+
+	return processed, nil
+}
+
+// restore the position of the reader from the last checkpoint, and returns the number of processed elements.
+func (j *Job[S, E, K, R]) restore() (int, error) {
+	checkpoint, err := j.store.Read(j.id)
+	if err != nil {
+		if errors.ErrorCode(err) == errors.CodeNotFound {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+
+	var lastKey K
+	if err := json.Unmarshal(checkpoint.LastKey, &lastKey); err != nil {
+		return 0, fmt.Errorf("could not decode checkpoint last key: %w", err)
+	}
+
+	j.pager.Reader.SetLastKey(lastKey)
+
+	return checkpoint.Processed, nil
+}
+
+func (j *Job[S, E, K, R]) commit(processed int) error {
+	lastKey, err := json.Marshal(j.pager.Reader.GetLastKey())
+	if err != nil {
+		return fmt.Errorf("could not encode checkpoint last key: %w", err)
+	}
+
+	if err := j.store.Write(&Checkpoint{
+		JobID:     j.id,
+		LastKey:   lastKey,
+		Processed: processed,
+		UpdatedAt: j.now(),
+	}); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// setTaskProgress up to 99, as the task is completed by the caller.
+func (j *Job[S, E, K, R]) setTaskProgress(processed int) error {
+	if j.taskProgressSetter == nil || j.total <= 0 {
+		return nil
+	}
+
+	return j.taskProgressSetter.SetTaskProgress(j.taskRID, min(processed*100/j.total, 99))
+}
@@ -0,0 +1,151 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJob(t *testing.T) {
+	store := newMemoryStore()
+	progressSetter := &taskProgressSetterMock{}
+	processed := []int{}
+
+	newJob := func() *Job[[]int, int, int, *pagination.KeysetPageReader[[]int, int, int]] {
+		return NewJob("job", store, pagination.NewPager(
+			pagination.NewKeysetPageReader[[]int](readIntsPageAfter(10)),
+			pagination.WithPageSize(3),
+		), WithJobTaskProgress(progressSetter, "tasks.1", 10))
+	}
+
+	// The first run crashes while processing the third page.
+	_, err := newJob().Run(context.Background(), func(_ context.Context, page []int) error {
+		if page[0] == 7 {
+			return assert.AnError
+		}
+
+		processed = append(processed, page...)
+
+		return nil
+	})
+	require.ErrorIs(t, err, assert.AnError)
+
+	checkpoint, err := store.Read("job")
+	require.NoError(t, err)
+	assert.JSONEq(t, `6`, string(checkpoint.LastKey))
+	assert.Equal(t, 6, checkpoint.Processed)
+
+	// The second run resumes after the last checkpoint.
+	got, err := newJob().Run(context.Background(), func(_ context.Context, page []int) error {
+		processed = append(processed, page...)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, got)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, processed)
+	assert.Equal(t, []int{0, 30, 60, 60, 90, 99}, progressSetter.progresses)
+
+	_, err = store.Read("job")
+	assert.Equal(t, errors.CodeNotFound, errors.ErrorCode(err), "the checkpoint must be deleted on completion")
+}
+
+func TestBadgerStore(t *testing.T) {
+	store := NewBadgerStore(openTestBadgerDB(t))
+
+	_, err := store.Read("job")
+	assert.Equal(t, errors.CodeNotFound, errors.ErrorCode(err))
+
+	in := &Checkpoint{
+		JobID:     "job",
+		LastKey:   []byte(`["2024-03-15","7b5d9c4e-1f2a-4b3c-8d9e-0a1b2c3d4e5f"]`),
+		Processed: 50,
+		UpdatedAt: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Write(in))
+
+	got, err := store.Read("job")
+	require.NoError(t, err)
+	assert.Equal(t, in, got)
+
+	require.NoError(t, store.Delete("job"))
+
+	_, err = store.Read("job")
+	assert.Equal(t, errors.CodeNotFound, errors.ErrorCode(err))
+}
+
+// readIntsPageAfter reads the integers from 1 to the given maximum.
+func readIntsPageAfter(maximum int) func(size, lastKey int) ([]int, int, error) {
+	return func(size, lastKey int) ([]int, int, error) {
+		result := []int{}
+		for i := lastKey + 1; i <= min(lastKey+size, maximum); i++ {
+			result = append(result, i)
+		}
+
+		if len(result) == 0 {
+			return result, lastKey, nil
+		}
+
+		return result, result[len(result)-1], nil
+	}
+}
+
+func newMemoryStore() *MockStore {
+	mu := sync.Mutex{}
+	checkpoints := map[string]*Checkpoint{}
+
+	return &MockStore{
+		ReadFunc: func(jobID string) (*Checkpoint, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if result, ok := checkpoints[jobID]; ok {
+				return result, nil
+			}
+
+			return nil, &errors.Error{Code: errors.CodeNotFound}
+		},
+		WriteFunc: func(checkpoint *Checkpoint) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			checkpoints[checkpoint.JobID] = checkpoint
+
+			return nil
+		},
+		DeleteFunc: func(jobID string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			delete(checkpoints, jobID)
+
+			return nil
+		},
+	}
+}
+
+type taskProgressSetterMock struct{ progresses []int }
+
+func (m *taskProgressSetterMock) SetTaskProgress(_ string, progress int) error {
+	m.progresses = append(m.progresses, progress)
+
+	return nil
+}
+
+func openTestBadgerDB(t *testing.T) *badger.DB {
+	path, err := os.MkdirTemp("/tmp/", "checkpoint-badger-store-")
+	require.NoError(t, err)
+
+	result, err := badger.Open(badger.DefaultOptions(path))
+	require.NoError(t, err)
+
+	return result
+}
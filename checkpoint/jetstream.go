@@ -0,0 +1,56 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type jetStreamKeyValueStore struct{ store jetstream.KeyValue }
+
+func NewJetStreamKeyValueStore(store jetstream.KeyValue) *jetStreamKeyValueStore {
+	return &jetStreamKeyValueStore{store}
+}
+
+var _ (Store) = (*jetStreamKeyValueStore)(nil)
+
+func (s *jetStreamKeyValueStore) Read(jobID string) (*Checkpoint, error) {
+	entry, err := s.store.Get(context.Background(), jobID)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, &errors.Error{Code: errors.CodeNotFound}
+		} else {
+			return nil, err
+		}
+	}
+
+	model, err := decodeCheckpointModel(entry.Value())
+	if err != nil {
+		return nil, fmt.Errorf("could not decode JetStream checkpoint model: %w", err)
+	}
+
+	return mapModelToCheckpoint(model), nil
+}
+
+func (s *jetStreamKeyValueStore) Write(checkpoint *Checkpoint) error {
+	encodedModel, err := mapCheckpointToModel(checkpoint).encode()
+	if err != nil {
+		return fmt.Errorf("could not encode JetStream checkpoint model: %w", err)
+	}
+
+	if _, err := s.store.Put(context.Background(), checkpoint.JobID, encodedModel); err != nil {
+		return fmt.Errorf("could not write checkpoint model to JetStream: %w", err)
+	}
+
+	return nil
+}
+
+func (s *jetStreamKeyValueStore) Delete(jobID string) error {
+	if err := s.store.Delete(context.Background(), jobID); err != nil && !errors.Is(err, jetstream.ErrKeyNotFound) {
+		return fmt.Errorf("could not delete checkpoint model from JetStream: %w", err)
+	}
+
+	return nil
+}
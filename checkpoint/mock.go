@@ -0,0 +1,13 @@
+package checkpoint
+
+type MockStore struct {
+	ReadFunc   func(jobID string) (*Checkpoint, error)
+	WriteFunc  func(checkpoint *Checkpoint) error
+	DeleteFunc func(jobID string) error
+}
+
+var _ (Store) = (*MockStore)(nil)
+
+func (s *MockStore) Read(jobID string) (*Checkpoint, error) { return s.ReadFunc(jobID) }
+func (s *MockStore) Write(checkpoint *Checkpoint) error     { return s.WriteFunc(checkpoint) }
+func (s *MockStore) Delete(jobID string) error              { return s.DeleteFunc(jobID) }
@@ -0,0 +1,60 @@
+package checkpoint
+
+import (
+	"database/sql"
+
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+// Schema of the checkpoints table. It must be applied by the migrations of the services using the Postgres store.
+const Schema = `CREATE TABLE IF NOT EXISTS checkpoints (
+	job_id TEXT PRIMARY KEY,
+	last_key JSONB NOT NULL,
+	processed INTEGER NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);`
+
+type postgresStore struct{ db *sql.DB }
+
+func NewPostgresStore(db *sql.DB) *postgresStore { return &postgresStore{db} }
+
+var _ (Store) = (*postgresStore)(nil)
+
+func (s *postgresStore) Read(jobID string) (*Checkpoint, error) {
+	result := &Checkpoint{JobID: jobID}
+
+	var lastKey []byte
+	if err := s.db.QueryRow(
+		`SELECT last_key, processed, updated_at FROM checkpoints WHERE job_id = $1`,
+		jobID,
+	).Scan(&lastKey, &result.Processed, &result.UpdatedAt); err != nil {
+		return nil, errors.MapSQLError(err)
+	}
+
+	result.LastKey = lastKey
+
+	return result, nil
+}
+
+func (s *postgresStore) Write(checkpoint *Checkpoint) error {
+	if _, err := s.db.Exec(
+		`INSERT INTO checkpoints (job_id, last_key, processed, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (job_id) DO UPDATE SET
+			last_key = EXCLUDED.last_key,
+			processed = EXCLUDED.processed,
+			updated_at = EXCLUDED.updated_at`,
+		checkpoint.JobID, []byte(checkpoint.LastKey), checkpoint.Processed, checkpoint.UpdatedAt,
+	); err != nil {
+		return errors.MapSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *postgresStore) Delete(jobID string) error {
+	if _, err := s.db.Exec(`DELETE FROM checkpoints WHERE job_id = $1`, jobID); err != nil {
+		return errors.MapSQLError(err)
+	}
+
+	return nil
+}
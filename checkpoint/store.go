@@ -0,0 +1,43 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// checkpointModel is the JSON model of the checkpoints in key-value stores.
+type checkpointModel struct {
+	JobID     string          `json:"jobId"`
+	LastKey   json.RawMessage `json:"lastKey"`
+	Processed int             `json:"processed"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+func (m *checkpointModel) encode() ([]byte, error) { return json.Marshal(m) }
+
+func decodeCheckpointModel(encodedModel []byte) (*checkpointModel, error) {
+	result := &checkpointModel{}
+	if err := json.Unmarshal(encodedModel, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func mapModelToCheckpoint(model *checkpointModel) *Checkpoint {
+	return &Checkpoint{
+		JobID:     model.JobID,
+		LastKey:   model.LastKey,
+		Processed: model.Processed,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func mapCheckpointToModel(checkpoint *Checkpoint) *checkpointModel {
+	return &checkpointModel{
+		JobID:     checkpoint.JobID,
+		LastKey:   checkpoint.LastKey,
+		Processed: checkpoint.Processed,
+		UpdatedAt: checkpoint.UpdatedAt,
+	}
+}
@@ -49,7 +49,7 @@ type collection struct {
 
 func TestReadEntity(t *testing.T) {
 	uuid := uuid.New()
-	entityAccount := createEntity(uuid.String())
+	entityAccount := createEntity(uuid)
 
 	resClient, cache := initTest(t)
 
@@ -100,12 +100,12 @@ func TestReadEntityAccounts(t *testing.T) {
 	account1UUID := uuid.New()
 	account2UUID := uuid.New()
 
-	_ = createEntity(parentAccountUUID.String())
+	_ = createEntity(parentAccountUUID)
 
-	account1 := createEntity(account1UUID.String())
+	account1 := createEntity(account1UUID)
 	account1.Chain = res.SoftRef(parentAccountUUID.String())
 
-	account2 := createEntity(account2UUID.String())
+	account2 := createEntity(account2UUID)
 	account2.Chain = res.SoftRef(parentAccountUUID.String())
 
 	resClient, _ := initTest(t)
@@ -231,11 +231,11 @@ func TestReadAccountParents(t *testing.T) {
 
 		uuidAccount := uuid.New()
 
-		chainEntity := createEntity(uuidAccount.String())
-		accountEntity := createEntity(uuidAccount.String())
+		chainEntity := createEntity(uuidAccount)
+		accountEntity := createEntity(uuidAccount)
 
-		resourceID := "authority.entities." + accountEntity.ID
-		chainResourceID := "authority.entities." + chainEntity.ID
+		resourceID := "authority.entities." + accountEntity.ID.String()
+		chainResourceID := "authority.entities." + chainEntity.ID.String()
 
 		accountEntity.Chain = res.SoftRef(chainResourceID)
 
@@ -256,11 +256,11 @@ func TestReadAccountParents(t *testing.T) {
 
 		uuidAccount := uuid.New()
 
-		groupEntity := createEntity(uuid.New().String())
-		accountEntity := createEntity(uuidAccount.String())
+		groupEntity := createEntity(uuid.New())
+		accountEntity := createEntity(uuidAccount)
 
-		resourceID := "authority.entities." + accountEntity.ID
-		groupResourceID := "authority.entities." + groupEntity.ID
+		resourceID := "authority.entities." + accountEntity.ID.String()
+		groupResourceID := "authority.entities." + groupEntity.ID.String()
 
 		accountEntity.Group = res.SoftRef(groupResourceID)
 
@@ -281,13 +281,13 @@ func TestReadAccountParents(t *testing.T) {
 
 		uuidAccount := uuid.New()
 
-		groupEntity := createEntity(uuid.New().String())
-		chainEntity := createEntity(uuidAccount.String())
-		accountEntity := createEntity(uuidAccount.String())
+		groupEntity := createEntity(uuid.New())
+		chainEntity := createEntity(uuidAccount)
+		accountEntity := createEntity(uuidAccount)
 
-		resourceID := "authority.entities." + accountEntity.ID
-		chainResourceID := "authority.entities." + chainEntity.ID
-		groupResourceID := "authority.entities." + groupEntity.ID
+		resourceID := "authority.entities." + accountEntity.ID.String()
+		chainResourceID := "authority.entities." + chainEntity.ID.String()
+		groupResourceID := "authority.entities." + groupEntity.ID.String()
 
 		accountEntity.Chain = res.SoftRef(chainResourceID)
 		accountEntity.Group = res.SoftRef(groupResourceID)
@@ -311,14 +311,14 @@ func TestReadAccountParents(t *testing.T) {
 		transportClient := newTransport(resClient, nil)
 
 		uuidAccount := uuid.New()
-		accountEntity := createEntity(uuidAccount.String())
-		resourceID := "authority.entities." + accountEntity.ID
+		accountEntity := createEntity(uuidAccount)
+		resourceID := "authority.entities." + accountEntity.ID.String()
 
-		chainEntity := createEntity(uuid.New().String())
-		accountEntity.Chain = res.SoftRef(chainEntity.ID)
+		chainEntity := createEntity(uuid.New())
+		accountEntity.Chain = res.SoftRef(chainEntity.ID.String())
 
 		resClient.EXPECT().Request("get."+resourceID, resprot.Request{}).Return(entityToRESresp(accountEntity))
-		resClient.EXPECT().Request("get."+chainEntity.ID, resprot.Request{}).Return(resprot.Response{
+		resClient.EXPECT().Request("get."+chainEntity.ID.String(), resprot.Request{}).Return(resprot.Response{
 			Error: &res.Error{
 				Code:    "internal",
 				Message: "bruh this is a error",
@@ -333,14 +333,14 @@ func TestReadAccountParents(t *testing.T) {
 		transportClient := newTransport(resClient, nil)
 
 		uuidAccount := uuid.New()
-		accountEntity := createEntity(uuidAccount.String())
-		resourceID := "authority.entities." + accountEntity.ID
+		accountEntity := createEntity(uuidAccount)
+		resourceID := "authority.entities." + accountEntity.ID.String()
 
-		groupEntity := createEntity(uuid.New().String())
-		accountEntity.Group = res.SoftRef(groupEntity.ID)
+		groupEntity := createEntity(uuid.New())
+		accountEntity.Group = res.SoftRef(groupEntity.ID.String())
 
 		resClient.EXPECT().Request("get."+resourceID, resprot.Request{}).Return(entityToRESresp(accountEntity))
-		resClient.EXPECT().Request("get."+groupEntity.ID, resprot.Request{}).Return(resprot.Response{
+		resClient.EXPECT().Request("get."+groupEntity.ID.String(), resprot.Request{}).Return(resprot.Response{
 			Error: &res.Error{
 				Code:    "internal",
 				Message: "bruh this is a error",
@@ -355,8 +355,8 @@ func TestReadAccountParents(t *testing.T) {
 		transportClient := newTransport(resClient, nil)
 
 		uuidAccount := uuid.New()
-		accountEntity := createEntity(uuidAccount.String())
-		resourceID := "authority.entities." + accountEntity.ID
+		accountEntity := createEntity(uuidAccount)
+		resourceID := "authority.entities." + accountEntity.ID.String()
 
 		resClient.EXPECT().Request("get."+resourceID, resprot.Request{}).Return(resprot.Response{
 			Error: &res.Error{
@@ -373,9 +373,9 @@ func TestReadAccountParents(t *testing.T) {
 		transportClient := newTransport(resClient, nil)
 
 		uuidAccount := uuid.New()
-		entity := createEntity(uuidAccount.String())
+		entity := createEntity(uuidAccount)
 		entity.Type = resmodels.EntityTypeGroup
-		resourceID := "authority.entities." + entity.ID
+		resourceID := "authority.entities." + entity.ID.String()
 
 		resClient.EXPECT().Request("get."+resourceID, resprot.Request{}).Return(entityToRESresp(entity))
 
@@ -391,7 +391,7 @@ func TestReadEntityCustomFields(t *testing.T) {
 		transportClient := newTransport(transport, nil)
 
 		entityID := uuid.New()
-		createEntity(entityID.String())
+		createEntity(entityID)
 		resourceID := "proxy-db.entities." + entityID.String() + ".custom-fields"
 
 		cFields := resmodels.EntityCustomFields{
@@ -419,7 +419,7 @@ func TestReadEntityCustomFields(t *testing.T) {
 		transportClient := newTransport(transport, cache)
 
 		entityID := uuid.New()
-		createEntity(entityID.String())
+		createEntity(entityID)
 		resourceID := "proxy-db.entities." + entityID.String() + ".custom-fields"
 
 		cFields := resmodels.EntityCustomFields{
@@ -446,7 +446,7 @@ func TestReadEntityCustomFields(t *testing.T) {
 		transportClient := newTransport(transport, cache)
 
 		entityID := uuid.New()
-		createEntity(entityID.String())
+		createEntity(entityID)
 		resourceID := "proxy-db.entities." + entityID.String() + ".custom-fields"
 
 		cFields := resmodels.EntityCustomFields{
@@ -477,7 +477,7 @@ func TestReadEntityCustomFields(t *testing.T) {
 		transportClient := newTransport(transport, nil)
 
 		entityID := uuid.New()
-		createEntity(entityID.String())
+		createEntity(entityID)
 		resourceID := "proxy-db.entities." + entityID.String() + ".custom-fields"
 
 		transport.EXPECT().Request("get."+resourceID, resprot.Request{}).Return(resprot.Response{})
@@ -590,11 +590,11 @@ func TestBuildESQueryEntity(t *testing.T) {
 	})
 }
 
-func createEntity(uuid string) resmodels.Entity {
+func createEntity(id uuid.UUID) resmodels.Entity {
 	lang := res.NewDataValue([]string{"en"})
 
 	return resmodels.Entity{
-		ID:             uuid,
+		ID:             id,
 		LegacyID:       1,
 		Type:           resmodels.EntityTypeAccount,
 		Name:           "Test Account",
@@ -0,0 +1,409 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+// SearchValueType of the value of a search criteria.
+type SearchValueType string
+
+const (
+	// SearchValueTypeAny accepts any value. It is used by fields with dynamic values, like custom fields.
+	SearchValueTypeAny     SearchValueType = "any"
+	SearchValueTypeDate    SearchValueType = "date"
+	SearchValueTypeNone    SearchValueType = "none"
+	SearchValueTypeNumber  SearchValueType = "number"
+	SearchValueTypeString  SearchValueType = "string"
+	SearchValueTypeWeekday SearchValueType = "weekday"
+)
+
+// SearchFieldSchema describes the operators and the values accepted by a search field.
+type SearchFieldSchema struct {
+	Operators []SearchCriteriaOperator
+	ValueType SearchValueType
+
+	// EnumValues accepted by the field. Any value of the right type is accepted when empty.
+	EnumValues []string
+}
+
+// Accepts returns true if the field accepts the given operator. The anonymous operators are accepted by every field.
+func (s *SearchFieldSchema) Accepts(operator SearchCriteriaOperator) bool {
+	return operator == SearchOperatorAnonymous ||
+		operator == SearchOperatorNotAnonymous ||
+		slices.Contains(s.Operators, operator)
+}
+
+var (
+	searchKeywordOperators = []SearchCriteriaOperator{
+		SearchOperatorEquals,
+		SearchOperatorNotEquals,
+		SearchOperatorContains,
+		SearchOperatorNotContains,
+		SearchOperatorStartsWith,
+		SearchOperatorNotStartsWith,
+		SearchOperatorFilled,
+		SearchOperatorNotFilled,
+	}
+	searchNumberOperators = []SearchCriteriaOperator{
+		SearchOperatorEquals,
+		SearchOperatorNotEquals,
+		SearchOperatorInferior,
+		SearchOperatorSuperior,
+		SearchOperatorRange,
+		SearchOperatorFilled,
+		SearchOperatorNotFilled,
+	}
+	searchDateOperators = []SearchCriteriaOperator{
+		SearchOperatorDateEqual,
+		SearchOperatorDateAfterThan,
+		SearchOperatorDateAfterThanEqual,
+		SearchOperatorDateAfterThanKeywordDay,
+		SearchOperatorDateBeforeThan,
+		SearchOperatorDateBeforeThanEqual,
+		SearchOperatorDateBeforeThanKeywordDay,
+		SearchOperatorDateAwayMore,
+		SearchOperatorDateAwayLess,
+		SearchOperatorRange,
+		SearchOperatorFilled,
+		SearchOperatorNotFilled,
+	}
+	searchBooleanOperators  = []SearchCriteriaOperator{SearchOperatorYes, SearchOperatorNo}
+	searchCampaignOperators = []SearchCriteriaOperator{
+		SearchOperatorCampaignAnswered,
+		SearchOperatorCampaignNotAnswered,
+		SearchOperatorCampaignOpened,
+		SearchOperatorCampaignNotOpened,
+		SearchOperatorCampaignReceived,
+		SearchOperatorCampaignNotReceived,
+		SearchOperatorCampaignScheduled,
+		SearchOperatorCampaignNotScheduled,
+	}
+	searchSessionOperators = []SearchCriteriaOperator{
+		SearchOperatorHadSession,
+		SearchOperatorNotHadSession,
+		SearchOperatorHasCurrentSession,
+	}
+)
+
+var (
+	searchKeywordFieldSchema = &SearchFieldSchema{Operators: searchKeywordOperators, ValueType: SearchValueTypeString}
+	searchNumberFieldSchema  = &SearchFieldSchema{Operators: searchNumberOperators, ValueType: SearchValueTypeNumber}
+	searchDateFieldSchema    = &SearchFieldSchema{Operators: searchDateOperators, ValueType: SearchValueTypeDate}
+	searchBooleanFieldSchema = &SearchFieldSchema{Operators: searchBooleanOperators, ValueType: SearchValueTypeNone}
+	searchWeekdayFieldSchema = &SearchFieldSchema{
+		Operators:  []SearchCriteriaOperator{SearchOperatorDow},
+		ValueType:  SearchValueTypeWeekday,
+		EnumValues: []string{"1", "2", "3", "4", "5", "6", "7"},
+	}
+	searchCampaignFieldSchema = &SearchFieldSchema{Operators: searchCampaignOperators, ValueType: SearchValueTypeString}
+	searchCustomFieldSchema   = &SearchFieldSchema{
+		Operators: slices.Concat(searchKeywordOperators, searchNumberOperators, searchDateOperators),
+		ValueType: SearchValueTypeAny,
+	}
+)
+
+var searchFieldSchemas = map[SearchCriteriaField]*SearchFieldSchema{
+	SearchCriteriaFieldApp:                  {Operators: searchSessionOperators, ValueType: SearchValueTypeNone},
+	SearchCriteriaFieldArrival:              searchDateFieldSchema,
+	SearchCriteriaFieldArrivalDOW:           searchWeekdayFieldSchema,
+	SearchCriteriaFieldBalance:              searchNumberFieldSchema,
+	SearchCriteriaFieldBirthdate:            searchDateFieldSchema,
+	SearchCriteriaFieldBirthday:             searchDateFieldSchema,
+	SearchCriteriaFieldBookingDate:          searchDateFieldSchema,
+	SearchCriteriaFieldBookingID:            searchNumberFieldSchema,
+	SearchCriteriaFieldBookingPurpose:       searchKeywordFieldSchema,
+	SearchCriteriaFieldBookingStatus:        searchKeywordFieldSchema,
+	SearchCriteriaFieldBookingStatusDiff:    searchKeywordFieldSchema,
+	SearchCriteriaFieldBookingTags:          searchKeywordFieldSchema,
+	SearchCriteriaFieldBookingWindow:        searchNumberFieldSchema,
+	SearchCriteriaFieldCampaignApp:          searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignEmail:        searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignNewsletter:   searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignScheduled:    searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignSearch:       searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignSMS:          searchCampaignFieldSchema,
+	SearchCriteriaFieldCampaignWhatsApp:     searchCampaignFieldSchema,
+	SearchCriteriaFieldChannel:              searchKeywordFieldSchema,
+	SearchCriteriaFieldCity:                 searchKeywordFieldSchema,
+	SearchCriteriaFieldCompany:              searchKeywordFieldSchema,
+	SearchCriteriaFieldCountry:              searchKeywordFieldSchema,
+	SearchCriteriaFieldCustomFieldsBooking:  searchCustomFieldSchema,
+	SearchCriteriaFieldCustomFieldsGuest:    searchCustomFieldSchema,
+	SearchCriteriaFieldDeparture:            searchDateFieldSchema,
+	SearchCriteriaFieldDepartureDOW:         searchWeekdayFieldSchema,
+	SearchCriteriaFieldEmail:                searchKeywordFieldSchema,
+	SearchCriteriaFieldEmailCollected:       searchDateFieldSchema,
+	SearchCriteriaFieldEmailDomain:          searchKeywordFieldSchema,
+	SearchCriteriaFieldEntityGuestUUIDs:     searchKeywordFieldSchema,
+	SearchCriteriaFieldEntityID:             searchKeywordFieldSchema,
+	SearchCriteriaFieldEntityObjectUUID:     searchKeywordFieldSchema,
+	SearchCriteriaFieldFare:                 searchNumberFieldSchema,
+	SearchCriteriaFieldFareAvg:              searchNumberFieldSchema,
+	SearchCriteriaFieldFareCode:             searchKeywordFieldSchema,
+	SearchCriteriaFieldFareRatio:            searchNumberFieldSchema,
+	SearchCriteriaFieldFareSum:              searchNumberFieldSchema,
+	SearchCriteriaFieldFidelity:             searchKeywordFieldSchema,
+	SearchCriteriaFieldFidelityStatus:       searchKeywordFieldSchema,
+	SearchCriteriaFieldGuestTags:            searchKeywordFieldSchema,
+	SearchCriteriaFieldGuestUUID:            searchKeywordFieldSchema,
+	SearchCriteriaFieldHasFacebook:          searchBooleanFieldSchema,
+	SearchCriteriaFieldHasLinkedIn:          searchBooleanFieldSchema,
+	SearchCriteriaFieldHasPersonnalEmail:    searchBooleanFieldSchema,
+	SearchCriteriaFieldHasTwitter:           searchBooleanFieldSchema,
+	SearchCriteriaFieldIDMasterResa:         searchKeywordFieldSchema,
+	SearchCriteriaFieldIDResa:               searchKeywordFieldSchema,
+	SearchCriteriaFieldInStay:               searchBooleanFieldSchema,
+	SearchCriteriaFieldInStayDate:           searchDateFieldSchema,
+	SearchCriteriaFieldInStayDates:          searchDateFieldSchema,
+	SearchCriteriaFieldLangs:                searchKeywordFieldSchema,
+	SearchCriteriaFieldLastConnexion:        searchDateFieldSchema,
+	SearchCriteriaFieldLastName:             searchKeywordFieldSchema,
+	SearchCriteriaFieldLinkedInFollowers:    searchNumberFieldSchema,
+	SearchCriteriaFieldMetadata:             searchCustomFieldSchema,
+	SearchCriteriaFieldNationality:          searchKeywordFieldSchema,
+	SearchCriteriaFieldNbNextStays:          searchNumberFieldSchema,
+	SearchCriteriaFieldNbPreviousStays:      searchNumberFieldSchema,
+	SearchCriteriaFieldNbStays:              searchNumberFieldSchema,
+	SearchCriteriaFieldNextStay:             searchDateFieldSchema,
+	SearchCriteriaFieldOptinAuto:            searchBooleanFieldSchema,
+	SearchCriteriaFieldOptinCustomerAccount: searchBooleanFieldSchema,
+	SearchCriteriaFieldOptinLoyalty:         searchBooleanFieldSchema,
+	SearchCriteriaFieldOptinMarketing:       searchBooleanFieldSchema,
+	SearchCriteriaFieldOptinSendInBlue:      searchBooleanFieldSchema,
+	SearchCriteriaFieldPartner:              searchKeywordFieldSchema,
+	SearchCriteriaFieldPaxAdults:            searchNumberFieldSchema,
+	SearchCriteriaFieldPaxBabies:            searchNumberFieldSchema,
+	SearchCriteriaFieldPaxChildren:          searchNumberFieldSchema,
+	SearchCriteriaFieldPhone:                searchKeywordFieldSchema,
+	SearchCriteriaFieldPreferredEmail:       searchKeywordFieldSchema,
+	SearchCriteriaFieldPreviousStay:         searchDateFieldSchema,
+	SearchCriteriaFieldPushNotification:     searchBooleanFieldSchema,
+	SearchCriteriaFieldRoomNumber:           searchKeywordFieldSchema,
+	SearchCriteriaFieldRoomType:             searchKeywordFieldSchema,
+	SearchCriteriaFieldSearch:               searchKeywordFieldSchema,
+	SearchCriteriaFieldSegment: {
+		Operators: []SearchCriteriaOperator{SearchOperatorEquals, SearchOperatorNotEquals},
+		ValueType: SearchValueTypeAny,
+	},
+	SearchCriteriaFieldSourceImport:     searchKeywordFieldSchema,
+	SearchCriteriaFieldSourceType:       searchKeywordFieldSchema,
+	SearchCriteriaFieldStayLength:       searchNumberFieldSchema,
+	SearchCriteriaFieldTags:             searchKeywordFieldSchema,
+	SearchCriteriaFieldTouristTax:       searchNumberFieldSchema,
+	SearchCriteriaFieldTwitterFollowers: searchNumberFieldSchema,
+	SearchCriteriaFieldUpdatedAt:        searchDateFieldSchema,
+	SearchCriteriaFieldWeekend:          searchBooleanFieldSchema,
+	SearchCriteriaFieldZipCode:          searchKeywordFieldSchema,
+}
+
+// LookupSearchFieldSchema returns the schema of the given field, if it is known.
+func LookupSearchFieldSchema(field SearchCriteriaField) (*SearchFieldSchema, bool) {
+	result, ok := searchFieldSchemas[field]
+
+	return result, ok
+}
+
+// SearchValidationProblem found by SearchConditions.Validate.
+type SearchValidationProblem struct {
+	// Location of the problem as a JSON path (e.g. "$.criteria[0].criteria[1].operator").
+	Location string
+	Message  string
+}
+
+func (p *SearchValidationProblem) String() string { return p.Location + ": " + p.Message }
+
+// SearchValidationError lists every problem found by SearchConditions.Validate.
+type SearchValidationError struct {
+	Problems []*SearchValidationProblem
+}
+
+func (e *SearchValidationError) Error() string {
+	problems := []string{}
+	for _, problem := range e.Problems {
+		problems = append(problems, problem.String())
+	}
+
+	return strings.Join(problems, "; ")
+}
+
+// Validate the conditions against the schemas of their fields. The returned error is an errors.Error with the
+// CodeInvalid code, wrapping a SearchValidationError with the location of every problem.
+func (c *SearchConditions) Validate() error {
+	problems := []*SearchValidationProblem{}
+	addProblem := func(location, format string, args ...any) {
+		problems = append(problems, &SearchValidationProblem{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !isValidSearchLogic(c.Logic) {
+		addProblem("$.logic", "unknown logic %q", c.Logic)
+	}
+
+	for i, criterion := range c.Criteria {
+		criterionLocation := "$.criteria[" + strconv.Itoa(i) + "]"
+
+		if criterion == nil {
+			addProblem(criterionLocation, "criterion must not be null")
+
+			continue
+		}
+
+		if !isValidSearchLogic(criterion.Logic) {
+			addProblem(criterionLocation+".logic", "unknown logic %q", criterion.Logic)
+		}
+
+		for j, criteria := range criterion.Criteria {
+			criteriaLocation := criterionLocation + ".criteria[" + strconv.Itoa(j) + "]"
+
+			if criteria == nil {
+				addProblem(criteriaLocation, "criteria must not be null")
+
+				continue
+			}
+
+			schema, ok := LookupSearchFieldSchema(criteria.Field)
+			if !ok {
+				addProblem(criteriaLocation+".field", "unknown field %q", criteria.Field)
+
+				continue
+			}
+
+			if !schema.Accepts(criteria.Operator) {
+				addProblem(criteriaLocation+".operator", "operator %q is not allowed on field %q",
+					criteria.Operator, criteria.Field,
+				)
+
+				continue
+			}
+
+			if message := schema.validateValue(criteria.Operator, criteria.Value); message != "" {
+				addProblem(criteriaLocation+".value", "%s", message)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	validationError := &SearchValidationError{Problems: problems}
+
+	return &errors.Error{
+		Code:            errors.CodeInvalid,
+		Message:         "Invalid search conditions: " + validationError.Error(),
+		UnderlyingError: validationError,
+	}
+}
+
+func isValidSearchLogic(logic string) bool {
+	switch SearchGuestsLogic(strings.ToUpper(logic)) {
+	case "", SearchGuestsLogicAnd, SearchGuestsLogicOr:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateValue returns a message describing why the given value is invalid for the given operator, or an empty
+// string if it is valid.
+func (s *SearchFieldSchema) validateValue(operator SearchCriteriaOperator, value any) string {
+	switch operator {
+	case SearchOperatorAnonymous, SearchOperatorNotAnonymous, SearchOperatorFilled, SearchOperatorNotFilled,
+		SearchOperatorYes, SearchOperatorNo, SearchOperatorHadSession, SearchOperatorNotHadSession,
+		SearchOperatorHasCurrentSession:
+		return ""
+	case SearchOperatorRange:
+		values, ok := ParseSearchRange(value)
+		if !ok {
+			return "range value must be an array of two elements"
+		}
+
+		for _, element := range values {
+			if message := s.validateScalarValue(element); message != "" {
+				return message
+			}
+		}
+
+		return ""
+	case SearchOperatorDateAwayMore, SearchOperatorDateAwayLess:
+		if !isSearchNumberValue(value) {
+			return "value must be a number of days"
+		}
+
+		return ""
+	case SearchOperatorDateAfterThanKeywordDay, SearchOperatorDateBeforeThanKeywordDay:
+		if ParseSearchKeywordDate(value) == SearchKeywordDateUnknown {
+			return "value must be a keyword date (today, tomorrow or yesterday)"
+		}
+
+		return ""
+	case SearchOperatorEquals, SearchOperatorNotEquals, SearchOperatorDow,
+		SearchOperatorContains, SearchOperatorNotContains, SearchOperatorStartsWith, SearchOperatorNotStartsWith:
+		if values, ok := searchValueElements(value); ok {
+			if len(values) == 0 {
+				return "value must not be an empty array"
+			}
+
+			for _, element := range values {
+				if message := s.validateScalarValue(element); message != "" {
+					return message
+				}
+			}
+
+			return ""
+		}
+	}
+
+	return s.validateScalarValue(value)
+}
+
+func (s *SearchFieldSchema) validateScalarValue(value any) string {
+	switch s.ValueType {
+	case SearchValueTypeAny:
+		return ""
+	case SearchValueTypeNone:
+		if value != nil {
+			return "value must not be set"
+		}
+	case SearchValueTypeNumber:
+		if !isSearchNumberValue(value) {
+			return "value must be a number"
+		}
+	case SearchValueTypeDate:
+		if !isSearchDateValue(value) {
+			return "value must be a date (YYYY-MM-DD) or a keyword date (today, tomorrow or yesterday)"
+		}
+	case SearchValueTypeString, SearchValueTypeWeekday:
+		rawValue, ok := parseSearchString(value)
+		if !ok {
+			return "value must be a string"
+		}
+
+		if rawValue == "" {
+			return "value must not be empty"
+		}
+
+		if len(s.EnumValues) > 0 && !slices.Contains(s.EnumValues, rawValue) {
+			return fmt.Sprintf("value %q must be one of %s", rawValue, strings.Join(s.EnumValues, ", "))
+		}
+	}
+
+	return ""
+}
+
+func isSearchNumberValue(value any) bool {
+	_, ok := ParseSearchNumber(value)
+
+	return ok
+}
+
+func isSearchDateValue(value any) bool {
+	_, ok := ParseSearchDate(value, time.Time{})
+
+	return ok
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchConditionsValidate(t *testing.T) {
+	newConditions := func(criteria ...*SearchCriteria) *SearchConditions {
+		return &SearchConditions{Criteria: []*SearchCriterion{{Criteria: criteria}}}
+	}
+
+	tests := map[string]struct {
+		in            *SearchConditions
+		wantLocations []string
+	}{
+		"valid": {
+			in: &SearchConditions{
+				Logic: "OR",
+				Criteria: []*SearchCriterion{
+					{Logic: "and", Criteria: []*SearchCriteria{
+						{Field: SearchCriteriaFieldCity, Operator: SearchOperatorEquals, Value: "Paris"},
+						{Field: SearchCriteriaFieldLangs, Operator: SearchOperatorEquals, Value: []any{"fr", "en"}},
+						{Field: SearchCriteriaFieldFare, Operator: SearchOperatorRange, Value: []any{float64(10), "20"}},
+						{Field: SearchCriteriaFieldArrival, Operator: SearchOperatorDateAfterThanEqual, Value: "today"},
+						{Field: SearchCriteriaFieldArrival, Operator: SearchOperatorDateAwayLess, Value: float64(7)},
+						{Field: SearchCriteriaFieldDeparture, Operator: SearchOperatorDateEqual, Value: "2024-01-01"},
+						{Field: SearchCriteriaFieldArrivalDOW, Operator: SearchOperatorDow, Value: []string{"6", "7"}},
+						{Field: SearchCriteriaFieldWeekend, Operator: SearchOperatorYes},
+						{Field: SearchCriteriaFieldEmail, Operator: SearchOperatorAnonymous},
+						{Field: SearchCriteriaFieldCompany, Operator: SearchOperatorFilled},
+					}},
+				},
+			},
+		},
+		"contains many": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldLastName,
+				Operator: SearchOperatorContains,
+				Value:    []any{"dup", "x"},
+			}),
+		},
+		"not contains many": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldLastName,
+				Operator: SearchOperatorNotContains,
+				Value:    []any{"dup", "x"},
+			}),
+		},
+		"starts with many": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldLastName,
+				Operator: SearchOperatorStartsWith,
+				Value:    []any{"dup", "x"},
+			}),
+		},
+		"not starts with many": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldLastName,
+				Operator: SearchOperatorNotStartsWith,
+				Value:    []string{"dup", "x"},
+			}),
+		},
+		"unknown logic": {
+			in: &SearchConditions{
+				Logic:    "XOR",
+				Criteria: []*SearchCriterion{{Logic: "NAND"}},
+			},
+			wantLocations: []string{"$.logic", "$.criteria[0].logic"},
+		},
+		"unknown field": {
+			in:            newConditions(&SearchCriteria{Field: "unknown", Operator: SearchOperatorEquals}),
+			wantLocations: []string{"$.criteria[0].criteria[0].field"},
+		},
+		"operator not allowed": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldEmail,
+				Operator: SearchOperatorDow,
+				Value:    "1",
+			}),
+			wantLocations: []string{"$.criteria[0].criteria[0].operator"},
+		},
+		"range with scalar": {
+			in: newConditions(&SearchCriteria{
+				Field:    SearchCriteriaFieldFare,
+				Operator: SearchOperatorRange,
+				Value:    float64(10),
+			}),
+			wantLocations: []string{"$.criteria[0].criteria[0].value"},
+		},
+		"invalid values": {
+			in: newConditions(
+				&SearchCriteria{Field: SearchCriteriaFieldArrivalDOW, Operator: SearchOperatorDow, Value: "8"},
+				&SearchCriteria{Field: SearchCriteriaFieldArrival, Operator: SearchOperatorDateEqual, Value: "01/02/2024"},
+				&SearchCriteria{
+					Field:    SearchCriteriaFieldArrival,
+					Operator: SearchOperatorDateAfterThanKeywordDay,
+					Value:    "2024-01-01",
+				},
+				&SearchCriteria{Field: SearchCriteriaFieldPaxAdults, Operator: SearchOperatorSuperior, Value: "two"},
+				&SearchCriteria{Field: SearchCriteriaFieldCity, Operator: SearchOperatorEquals, Value: []any{}},
+				&SearchCriteria{Field: SearchCriteriaFieldWeekend, Operator: SearchOperatorEquals, Value: true},
+			),
+			wantLocations: []string{
+				"$.criteria[0].criteria[0].value",
+				"$.criteria[0].criteria[1].value",
+				"$.criteria[0].criteria[2].value",
+				"$.criteria[0].criteria[3].value",
+				"$.criteria[0].criteria[4].value",
+				"$.criteria[0].criteria[5].operator",
+			},
+		},
+		"null criteria": {
+			in:            &SearchConditions{Criteria: []*SearchCriterion{nil, {Criteria: []*SearchCriteria{nil}}}},
+			wantLocations: []string{"$.criteria[0]", "$.criteria[1].criteria[0]"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.in.Validate()
+			if len(tt.wantLocations) == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+
+			appError := &errors.Error{}
+			require.ErrorAs(t, err, &appError)
+
+			validationError, ok := appError.UnderlyingError.(*SearchValidationError)
+			require.True(t, ok)
+
+			gotLocations := []string{}
+			for _, problem := range validationError.Problems {
+				gotLocations = append(gotLocations, problem.Location)
+			}
+
+			assert.Equal(t, tt.wantLocations, gotLocations)
+		})
+	}
+}
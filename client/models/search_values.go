@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parsers of the values of search criteria. They are shared by SearchConditions.Validate, the compiler of the esutil
+// package and the evaluator of the search package, so they accept the same values.
+
+// ParseSearchStrings from a string, a number or a slice of them. Numbers are formatted as strings.
+func ParseSearchStrings(value any) ([]string, bool) {
+	if result, ok := parseSearchString(value); ok {
+		return []string{result}, true
+	}
+
+	elements, ok := searchValueElements(value)
+	if !ok {
+		return nil, false
+	}
+
+	result := []string{}
+
+	for _, element := range elements {
+		elementString, ok := parseSearchString(element)
+		if !ok {
+			return nil, false
+		}
+
+		result = append(result, elementString)
+	}
+
+	return result, true
+}
+
+func parseSearchString(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case float64, int:
+		return fmt.Sprint(value), true
+	default:
+		return "", false
+	}
+}
+
+// ParseSearchNumber from a number or a string representing a number.
+func ParseSearchNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case string:
+		result, err := strconv.ParseFloat(value, 64)
+
+		return result, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// ParseSearchRange from a slice of two bounds.
+func ParseSearchRange(value any) ([2]any, bool) {
+	elements, ok := searchValueElements(value)
+	if !ok || len(elements) != 2 { //nolint:mnd
+		return [2]any{}, false
+	}
+
+	return [2]any{elements[0], elements[1]}, true
+}
+
+// ParseSearchDate from a keyword date relative to today, or from a date formatted as "2006-01-02" (the time is
+// ignored).
+func ParseSearchDate(value any, today time.Time) (time.Time, bool) {
+	if duration := ParseSearchKeywordDate(value).Duration(); duration != nil {
+		return today.Add(*duration), true
+	}
+
+	rawDate, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	result, err := time.Parse(time.DateOnly, strings.Split(rawDate, "T")[0])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return result, true
+}
+
+// searchValueElements returns the elements of the given value if it is a slice.
+func searchValueElements(value any) ([]any, bool) {
+	switch value := value.(type) {
+	case []any:
+		return value, true
+	case []string:
+		return toAnySlice(value), true
+	case []float64:
+		return toAnySlice(value), true
+	case []int:
+		return toAnySlice(value), true
+	default:
+		return nil, false
+	}
+}
+
+func toAnySlice[T any](values []T) []any {
+	result := []any{}
+	for _, value := range values {
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchStrings(t *testing.T) {
+	tests := map[string]struct {
+		in     any
+		want   []string
+		wantOK bool
+	}{
+		"string":         {in: "FR", want: []string{"FR"}, wantOK: true},
+		"number":         {in: float64(75), want: []string{"75"}, wantOK: true},
+		"mixed elements": {in: []any{"vip", 1}, want: []string{"vip", "1"}, wantOK: true},
+		"numbers":        {in: []float64{1, 2.5}, want: []string{"1", "2.5"}, wantOK: true},
+		"nested slice":   {in: []any{[]any{"vip"}}},
+		"boolean":        {in: true},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, ok := ParseSearchStrings(tt.in)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSearchRange(t *testing.T) {
+	tests := map[string]struct {
+		in     any
+		want   [2]any
+		wantOK bool
+	}{
+		"any":       {in: []any{"2024-01-01", "today"}, want: [2]any{"2024-01-01", "today"}, wantOK: true},
+		"numbers":   {in: []float64{1, 2}, want: [2]any{float64(1), float64(2)}, wantOK: true},
+		"integers":  {in: []int{1, 2}, want: [2]any{1, 2}, wantOK: true},
+		"one bound": {in: []string{"1"}},
+		"scalar":    {in: "1"},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, ok := ParseSearchRange(tt.in)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSearchDate(t *testing.T) {
+	today := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		in     any
+		want   time.Time
+		wantOK bool
+	}{
+		"date":      {in: "2024-01-31T10:00:00Z", want: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), wantOK: true},
+		"keyword":   {in: "yesterday", want: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), wantOK: true},
+		"malformed": {in: "31/01/2024"},
+		"number":    {in: float64(1)},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, ok := ParseSearchDate(tt.in, today)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
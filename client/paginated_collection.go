@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/resprot"
+	"github.com/loungeup/go-loungeup/pagination"
+	"github.com/loungeup/go-loungeup/resmodels"
+	"github.com/loungeup/go-loungeup/transport"
+)
+
+// PaginatedCollectionPageReader reads the pages of a paginated collection, served by a handler created with
+// resutil.UseGetPaginatedCollectionHandler. The references of each page are mapped to elements with the given function
+// (e.g. reading the referenced models).
+type PaginatedCollectionPageReader[S ~[]E, E any] struct {
+	resClient  transport.RESRequester
+	rid        string
+	query      url.Values
+	mapRefFunc func(ref res.Ref) (E, error)
+
+	lastCollection *resmodels.PaginatedCollection
+}
+
+func NewPaginatedCollectionPageReader[S ~[]E, E any](
+	resClient transport.RESRequester,
+	rid string,
+	query url.Values,
+	mapRefFunc func(ref res.Ref) (E, error),
+) *PaginatedCollectionPageReader[S, E] {
+	return &PaginatedCollectionPageReader[S, E]{
+		resClient:  resClient,
+		rid:        rid,
+		query:      query,
+		mapRefFunc: mapRefFunc,
+	}
+}
+
+var _ pagination.PageReader[[]any, any] = (*PaginatedCollectionPageReader[[]any, any])(nil)
+
+func (r *PaginatedCollectionPageReader[S, E]) ReadPage(size int) (S, error) {
+	if r.lastCollection != nil && !r.lastCollection.HasMore {
+		return nil, nil
+	}
+
+	selector := &pagination.KeysetSelector[string]{Size: size}
+	if r.lastCollection != nil {
+		selector.LastKey = r.lastCollection.NextCursor
+	}
+
+	query := maps.Clone(r.query)
+	if query == nil {
+		query = url.Values{}
+	}
+
+	maps.Copy(query, selector.Query())
+
+	collection, err := transport.GetRESModel[*resmodels.PaginatedCollection](
+		r.resClient,
+		r.rid,
+		resprot.Request{Query: query.Encode()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading the next page without a cursor would read the first page again, forever.
+	if collection.HasMore && collection.NextCursor == "" {
+		return nil, fmt.Errorf("could not read the next page of %q: collection has more items without next cursor", r.rid)
+	}
+
+	result := S{}
+
+	if collection.Items != nil {
+		for _, ref := range collection.Items.Data {
+			element, err := r.mapRefFunc(ref)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, element)
+		}
+	}
+
+	r.lastCollection = collection
+
+	return result, nil
+}
+
+func (r *PaginatedCollectionPageReader[S, E]) Reset() { r.lastCollection = nil }
+
+// Total returns the total, or else the estimated total, of the last page read. The returned boolean reports whether
+// the total is exact. It returns -1 if the collection does not provide any.
+func (r *PaginatedCollectionPageReader[S, E]) Total() (int, bool) {
+	switch {
+	case r.lastCollection == nil:
+		return -1, false
+	case r.lastCollection.Total != nil:
+		return *r.lastCollection.Total, true
+	case r.lastCollection.EstimatedTotal != nil:
+		return *r.lastCollection.EstimatedTotal, false
+	default:
+		return -1, false
+	}
+}
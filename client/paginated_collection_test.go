@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/resprot"
+	"github.com/loungeup/go-loungeup/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginatedCollectionPageReader(t *testing.T) {
+	resClient, _ := initTest(t)
+
+	resClient.EXPECT().
+		Request("get.authority.entities", resprot.Request{Query: "lastKey=&name=foo&size=2"}).
+		Return(resprot.Response{Result: json.RawMessage(`{"model": {
+			"items": {"data": [{"rid": "authority.entities.1"}, {"rid": "authority.entities.2"}]},
+			"nextCursor": "c1",
+			"hasMore": true,
+			"estimatedTotal": 3
+		}}`)})
+	resClient.EXPECT().
+		Request("get.authority.entities", resprot.Request{Query: "lastKey=c1&name=foo&size=2"}).
+		Return(resprot.Response{Result: json.RawMessage(`{"model": {
+			"items": {"data": [{"rid": "authority.entities.3"}]},
+			"hasMore": false,
+			"total": 3
+		}}`)})
+
+	reader := NewPaginatedCollectionPageReader[[]string](
+		resClient,
+		"authority.entities",
+		map[string][]string{"name": {"foo"}},
+		func(ref res.Ref) (string, error) { return strings.TrimPrefix(string(ref), "authority.entities."), nil },
+	)
+
+	got := []string{}
+
+	for id, err := range pagination.NewPager(reader, pagination.WithPageSize(2)).All() {
+		require.NoError(t, err)
+
+		got = append(got, id)
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, got)
+
+	total, exact := reader.Total()
+	assert.Equal(t, 3, total)
+	assert.True(t, exact)
+}
+
+func TestPaginatedCollectionPageReaderWithoutNextCursor(t *testing.T) {
+	resClient, _ := initTest(t)
+
+	resClient.EXPECT().
+		Request("get.authority.entities", resprot.Request{Query: "lastKey=&size=2"}).
+		Return(resprot.Response{Result: json.RawMessage(`{"model": {
+			"items": {"data": [{"rid": "authority.entities.1"}, {"rid": "authority.entities.2"}]},
+			"hasMore": true
+		}}`)})
+
+	reader := NewPaginatedCollectionPageReader[[]res.Ref](
+		resClient,
+		"authority.entities",
+		nil,
+		func(ref res.Ref) (res.Ref, error) { return ref, nil },
+	)
+
+	_, err := reader.ReadPage(2)
+	assert.ErrorContains(t, err, "without next cursor")
+}
@@ -27,17 +27,17 @@ var (
 
 	// Maintenant définir les entités complètes
 	EntityChain = &resmodels.Entity{
-		ID:   EntityChainID.String(),
+		ID:   EntityChainID,
 		Type: resmodels.EntityTypeChain,
 	}
 
 	EntityGroup = &resmodels.Entity{
-		ID:   EntityGroupID.String(),
+		ID:   EntityGroupID,
 		Type: resmodels.EntityTypeGroup,
 	}
 
 	Entity = &resmodels.Entity{
-		ID:       EntityID.String(),
+		ID:       EntityID,
 		LegacyID: 1,
 		Type:     resmodels.EntityTypeAccount,
 		Name:     "Test Account",
@@ -1,7 +1,6 @@
 package testdata
 
 import (
-	"github.com/google/uuid"
 	"github.com/jirenius/go-res"
 	"github.com/loungeup/go-loungeup/resmodels"
 )
@@ -42,6 +41,6 @@ var (
 	}`
 
 	EntityCustomFieldsSelector = &resmodels.EntityCustomFieldsSelector{
-		EntityID: uuid.MustParse(Entity.ID),
+		EntityID: Entity.ID,
 	}
 )
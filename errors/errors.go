@@ -29,6 +29,18 @@ type Error struct {
 
 	// UnderlyingError that caused this error, if any.
 	UnderlyingError error
+
+	// Fields that caused this error, if any (e.g. invalid request params).
+	Fields []*FieldError
+}
+
+// FieldError describes why a field (e.g. a request param) is invalid.
+type FieldError struct {
+	// Field is the path of the field (e.g. "address.postalCode" or "items[0].quantity").
+	Field string `json:"field"`
+
+	// Message is human-readable.
+	Message string `json:"message"`
 }
 
 func (e *Error) Error() string {
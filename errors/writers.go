@@ -18,8 +18,9 @@ type errorWriter interface {
 }
 
 type logContext struct {
-	LogID             string `json:"logId"`
-	UnderlyingMessage string `json:"underlyingMessage,omitempty"`
+	LogID             string        `json:"logId"`
+	UnderlyingMessage string        `json:"underlyingMessage,omitempty"`
+	Fields            []*FieldError `json:"fields,omitempty"`
 }
 
 func (c *logContext) Attributes() []slog.Attr {
@@ -28,6 +29,10 @@ func (c *logContext) Attributes() []slog.Attr {
 		result = append(result, slog.String("underlyingMessage", c.UnderlyingMessage))
 	}
 
+	if len(c.Fields) > 0 {
+		result = append(result, slog.Any("fields", c.Fields))
+	}
+
 	return result
 }
 
@@ -42,8 +47,12 @@ func LogAndWriteRESError(l errorLogger, w errorWriter, err error) {
 
 	logContext := newLogContext()
 
-	if err, ok := err.(*Error); ok && err.UnderlyingError != nil {
-		logContext.UnderlyingMessage = err.UnderlyingError.Error()
+	if err, ok := err.(*Error); ok {
+		if err.UnderlyingError != nil {
+			logContext.UnderlyingMessage = err.UnderlyingError.Error()
+		}
+
+		logContext.Fields = err.Fields
 	}
 
 	logAttributes := append(logContext.Attributes(), extractLogAttributes(w)...)
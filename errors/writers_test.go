@@ -1,12 +1,14 @@
 package errors
 
 import (
+	"encoding/json"
 	"io"
 	"log/slog"
 	"testing"
 
 	"github.com/jirenius/go-res"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLogAndWriteRESError(t *testing.T) {
@@ -45,6 +47,29 @@ func TestLogAndWriteRESError(t *testing.T) {
 				assert.EqualError(t, err, "Could not find the resource")
 			},
 		},
+		"invalid fields": {
+			in: &Error{
+				Code:    CodeInvalid,
+				Message: "Invalid params",
+				Fields:  []*FieldError{{Field: "name", Message: "Required"}},
+			},
+			assertLog: func(t *testing.T, msg string, args ...slog.Attr) {
+				assert.Equal(t, "<invalid> Invalid params", msg)
+				assert.Len(t, args, 2)
+			},
+			assertWrite: func(t *testing.T, err error) {
+				resError, ok := err.(*res.Error)
+				require.True(t, ok)
+				assert.Equal(t, res.CodeInvalidParams, resError.Code)
+
+				encodedData, err := json.Marshal(resError.Data)
+				require.NoError(t, err)
+
+				data := struct{ Fields json.RawMessage }{}
+				require.NoError(t, json.Unmarshal(encodedData, &data))
+				assert.JSONEq(t, `[{"field": "name", "message": "Required"}]`, string(data.Fields))
+			},
+		},
 	}
 
 	for test, tt := range tests {
@@ -0,0 +1,225 @@
+package esutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// ErrUnexpectedAggregate is returned when an aggregate does not have the expected shape.
+var ErrUnexpectedAggregate = errors.New("unexpected aggregate")
+
+// DecodeAggregate asserts the type of the given aggregate without panicking.
+func DecodeAggregate[T estypes.Aggregate](aggregate estypes.Aggregate) (T, error) {
+	result, ok := aggregate.(T)
+	if !ok {
+		return result, fmt.Errorf("%w: got %T, want %T", ErrUnexpectedAggregate, aggregate, result)
+	}
+
+	return result, nil
+}
+
+// TermsBucket of a terms aggregation, whatever the type of its keys.
+type TermsBucket struct {
+	// Key is a string, an int64 or a float64.
+	Key      any
+	DocCount int64
+}
+
+// DecodeTermsBuckets from a string, long, double or unmapped terms aggregate. Keyed buckets are supported.
+func DecodeTermsBuckets(aggregate estypes.Aggregate) ([]*TermsBucket, error) {
+	result := []*TermsBucket{}
+
+	switch aggregate := aggregate.(type) {
+	case *estypes.StringTermsAggregate:
+		buckets, err := decodeBuckets[estypes.StringTermsBucket](aggregate.Buckets)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range buckets {
+			result = append(result, &TermsBucket{Key: bucket.Key, DocCount: bucket.DocCount})
+		}
+	case *estypes.LongTermsAggregate:
+		buckets, err := decodeBuckets[estypes.LongTermsBucket](aggregate.Buckets)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range buckets {
+			result = append(result, &TermsBucket{Key: bucket.Key, DocCount: bucket.DocCount})
+		}
+	case *estypes.DoubleTermsAggregate:
+		buckets, err := decodeBuckets[estypes.DoubleTermsBucket](aggregate.Buckets)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range buckets {
+			result = append(result, &TermsBucket{Key: float64(bucket.Key), DocCount: bucket.DocCount})
+		}
+	case *estypes.UnmappedTermsAggregate:
+	default:
+		return nil, fmt.Errorf("%w: got %T, want a terms aggregate", ErrUnexpectedAggregate, aggregate)
+	}
+
+	return result, nil
+}
+
+// DecodeMetricValue from a single-value metric aggregate (avg, min, max, sum, value count or cardinality). The value
+// is nil when no document was aggregated.
+func DecodeMetricValue(aggregate estypes.Aggregate) (*float64, error) {
+	var value *estypes.Float64
+
+	switch aggregate := aggregate.(type) {
+	case *estypes.AvgAggregate:
+		value = aggregate.Value
+	case *estypes.MinAggregate:
+		value = aggregate.Value
+	case *estypes.MaxAggregate:
+		value = aggregate.Value
+	case *estypes.SumAggregate:
+		value = aggregate.Value
+	case *estypes.ValueCountAggregate:
+		value = aggregate.Value
+	case *estypes.CardinalityAggregate:
+		result := float64(aggregate.Value)
+
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("%w: got %T, want a single-value metric aggregate", ErrUnexpectedAggregate, aggregate)
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	result := float64(*value)
+
+	return &result, nil
+}
+
+// Stats of a stats aggregate. Min, Max and Avg are nil when no document was aggregated.
+type Stats struct {
+	Count int64
+	Min   *float64
+	Max   *float64
+	Avg   *float64
+	Sum   float64
+}
+
+func DecodeStats(aggregate estypes.Aggregate) (*Stats, error) {
+	stats, err := DecodeAggregate[*estypes.StatsAggregate](aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		Count: stats.Count,
+		Min:   (*float64)(stats.Min),
+		Max:   (*float64)(stats.Max),
+		Avg:   (*float64)(stats.Avg),
+		Sum:   float64(stats.Sum),
+	}, nil
+}
+
+// DateHistogramBucket of a date histogram aggregation.
+type DateHistogramBucket struct {
+	// Key is the start of the bucket, in milliseconds since the epoch.
+	Key         int64
+	KeyAsString string
+	DocCount    int64
+}
+
+// DecodeDateHistogramBuckets from a date histogram aggregate. Keyed buckets are supported.
+func DecodeDateHistogramBuckets(aggregate estypes.Aggregate) ([]*DateHistogramBucket, error) {
+	histogram, err := DecodeAggregate[*estypes.DateHistogramAggregate](aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := decodeBuckets[estypes.DateHistogramBucket](histogram.Buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*DateHistogramBucket{}
+	for _, bucket := range buckets {
+		keyAsString := ""
+		if bucket.KeyAsString != nil {
+			keyAsString = *bucket.KeyAsString
+		}
+
+		result = append(result, &DateHistogramBucket{
+			Key:         bucket.Key,
+			KeyAsString: keyAsString,
+			DocCount:    bucket.DocCount,
+		})
+	}
+
+	return result, nil
+}
+
+// DecodeTopHitsSources decodes the source of each hit of a top hits aggregate.
+func DecodeTopHitsSources[T any](aggregate estypes.Aggregate) ([]T, error) {
+	topHits, err := DecodeAggregate[*estypes.TopHitsAggregate](aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []T{}
+
+	for _, hit := range topHits.Hits.Hits {
+		var source T
+		if err := json.Unmarshal(hit.Source_, &source); err != nil {
+			return nil, fmt.Errorf("could not decode top hit source: %w", err)
+		}
+
+		result = append(result, source)
+	}
+
+	return result, nil
+}
+
+// DecodeScriptedMetric decodes the value of a scripted metric aggregate.
+func DecodeScriptedMetric[T any](aggregate estypes.Aggregate) (T, error) {
+	var result T
+
+	scriptedMetric, err := DecodeAggregate[*estypes.ScriptedMetricAggregate](aggregate)
+	if err != nil {
+		return result, err
+	}
+
+	if len(scriptedMetric.Value) == 0 {
+		return result, nil
+	}
+
+	if err := json.Unmarshal(scriptedMetric.Value, &result); err != nil {
+		return result, fmt.Errorf("could not decode scripted metric value: %w", err)
+	}
+
+	return result, nil
+}
+
+// decodeBuckets returns the buckets of an aggregate, which are either a slice or a map when the aggregation is keyed.
+func decodeBuckets[T any](buckets any) ([]T, error) {
+	switch buckets := buckets.(type) {
+	case nil:
+		return []T{}, nil
+	case []T:
+		return buckets, nil
+	case map[string]T:
+		result := []T{}
+		for _, key := range slices.Sorted(maps.Keys(buckets)) {
+			result = append(result, buckets[key])
+		}
+
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: got buckets of type %T", ErrUnexpectedAggregate, buckets)
+	}
+}
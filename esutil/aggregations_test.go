@@ -0,0 +1,90 @@
+package esutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/esutil"
+	"github.com/loungeup/go-loungeup/pointer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTermsBuckets(t *testing.T) {
+	got, err := esutil.DecodeTermsBuckets(&estypes.LongTermsAggregate{Buckets: map[string]estypes.LongTermsBucket{
+		"b": {Key: 2, DocCount: 5},
+		"a": {Key: 1, DocCount: 3},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []*esutil.TermsBucket{
+		{Key: int64(1), DocCount: 3},
+		{Key: int64(2), DocCount: 5},
+	}, got)
+
+	_, err = esutil.DecodeTermsBuckets(&estypes.StatsAggregate{})
+	assert.ErrorIs(t, err, esutil.ErrUnexpectedAggregate)
+}
+
+func TestDecodeStats(t *testing.T) {
+	got, err := esutil.DecodeStats(&estypes.StatsAggregate{
+		Count: 2,
+		Min:   pointer.From(estypes.Float64(1)),
+		Max:   pointer.From(estypes.Float64(3)),
+		Avg:   pointer.From(estypes.Float64(2)),
+		Sum:   4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &esutil.Stats{
+		Count: 2,
+		Min:   pointer.From(1.0),
+		Max:   pointer.From(3.0),
+		Avg:   pointer.From(2.0),
+		Sum:   4,
+	}, got)
+
+	_, err = esutil.DecodeStats(&estypes.AvgAggregate{})
+	assert.ErrorIs(t, err, esutil.ErrUnexpectedAggregate)
+}
+
+func TestDecodeDateHistogramBuckets(t *testing.T) {
+	got, err := esutil.DecodeDateHistogramBuckets(&estypes.DateHistogramAggregate{
+		Buckets: []estypes.DateHistogramBucket{
+			{Key: 1704067200000, KeyAsString: pointer.From("2024-01-01"), DocCount: 4},
+			{Key: 1706745600000, DocCount: 0},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*esutil.DateHistogramBucket{
+		{Key: 1704067200000, KeyAsString: "2024-01-01", DocCount: 4},
+		{Key: 1706745600000, DocCount: 0},
+	}, got)
+}
+
+func TestDecodeTopHitsSources(t *testing.T) {
+	type source struct {
+		ID int `json:"id"`
+	}
+
+	got, err := esutil.DecodeTopHitsSources[source](&estypes.TopHitsAggregate{Hits: estypes.HitsMetadata{
+		Hits: []estypes.Hit{{Source_: json.RawMessage(`{"id":1}`)}, {Source_: json.RawMessage(`{"id":2}`)}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []source{{ID: 1}, {ID: 2}}, got)
+}
+
+func TestDecodeScriptedMetric(t *testing.T) {
+	got, err := esutil.DecodeScriptedMetric[map[string]int](&estypes.ScriptedMetricAggregate{
+		Value: json.RawMessage(`{"total":3}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"total": 3}, got)
+
+	_, err = esutil.DecodeScriptedMetric[map[string]int](&estypes.ScriptedMetricAggregate{
+		Value: json.RawMessage(`"foo"`),
+	})
+	assert.Error(t, err)
+
+	_, err = esutil.DecodeScriptedMetric[map[string]int](nil)
+	assert.ErrorIs(t, err, esutil.ErrUnexpectedAggregate)
+}
@@ -0,0 +1,601 @@
+package esutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/loungeup/go-loungeup/log"
+)
+
+// BulkIndexerItem to send to Elasticsearch. The document is usually a GuestCardDocument or a GuestBookingDocument.
+type BulkIndexerItem struct {
+	Index    string
+	ID       string
+	Document any
+
+	// flushAttempts counts the flushes which failed before the item could be sent.
+	flushAttempts int
+}
+
+// BulkIndexerItemError describes why an item could not be indexed.
+type BulkIndexerItemError struct {
+	Item   *BulkIndexerItem
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *BulkIndexerItemError) Error() string {
+	return fmt.Sprintf("could not index document %s in %s (%d): %s: %s",
+		e.Item.ID, e.Item.Index, e.Status, e.Type, e.Reason,
+	)
+}
+
+// BulkIndexerError is returned by a flush when some items could not be indexed.
+type BulkIndexerError struct {
+	Items []*BulkIndexerItemError
+}
+
+func (e *BulkIndexerError) Error() string {
+	return fmt.Sprintf("could not index %d document(s), first error: %s", len(e.Items), e.Items[0].Error())
+}
+
+// BulkIndexerStats of the items processed by a bulk indexer.
+type BulkIndexerStats struct {
+	Indexed int
+	Failed  int
+	Retried int
+	Flushed int
+	Paused  time.Duration
+}
+
+// loadTester is implemented by LoadTester.
+type loadTester interface {
+	Test() (bool, error)
+}
+
+// BulkIndexer batches documents and sends them with the bulk API. Batches are flushed when they reach a number of
+// items or bytes, or at a regular interval. Requests rejected with a 429 status code, and failed items with a 429
+// status code, are retried with a backoff.
+//
+// When a flush fails with a transient error (e.g. a network error or a 5xx status code), the items which were not
+// indexed are requeued for the next flush, until they reach the maximum number of flush attempts. Other errors (e.g. a
+// 400 or 413 status code) fail the items, which are reported like the items rejected by Elasticsearch.
+//
+// When a load tester is set, flushes are paused while the load of the cluster is too high, and the indexer slows
+// down: each high load test halves the size of the bulk requests, down to the flush items divided by the maximum
+// throttle, and each acceptable one doubles it back. The load is tested before each bulk request while slowed down.
+type BulkIndexer struct {
+	client            *elasticsearch.Client
+	loadTester        loadTester
+	loadCheckInterval time.Duration
+	loadPause         time.Duration
+	maxThrottle       int
+	flushItems        int
+	flushBytes        int
+	flushInterval     time.Duration
+	maxRetries        int
+	maxFlushAttempts  int
+	retryBackoff      func(attempt int) time.Duration
+	onError           func(err *BulkIndexerItemError)
+	logger            *log.Logger
+
+	mu          sync.Mutex
+	items       []*BulkIndexerItem
+	bytes       int
+	stats       BulkIndexerStats
+	flushMu     sync.Mutex
+	lastLoadAt  time.Time // Guarded by flushMu.
+	throttle    int       // Guarded by flushMu.
+	stopTicker  chan struct{}
+	stopOnce    sync.Once
+	tickerEnded chan struct{}
+}
+
+type BulkIndexerOption func(*BulkIndexer)
+
+func NewBulkIndexer(client *elasticsearch.Client, options ...BulkIndexerOption) *BulkIndexer {
+	//nolint:mnd
+	result := &BulkIndexer{
+		client:            client,
+		loadCheckInterval: 10 * time.Second,
+		loadPause:         5 * time.Second,
+		maxThrottle:       8,
+		flushItems:        500,
+		flushBytes:        5 * 1024 * 1024,
+		flushInterval:     5 * time.Second,
+		maxRetries:        5,
+		maxFlushAttempts:  3,
+		retryBackoff: func(attempt int) time.Duration {
+			return min(time.Duration(attempt+1)*time.Second, 10*time.Second)
+		},
+		logger:      log.Default().With(slog.String("component", "bulkIndexer")),
+		throttle:    1,
+		stopTicker:  make(chan struct{}),
+		tickerEnded: make(chan struct{}),
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	go result.runTicker()
+
+	return result
+}
+
+// WithBulkIndexerLoadTester pauses flushes, and sends smaller bulk requests, while the given load tester reports a high
+// load.
+func WithBulkIndexerLoadTester(tester loadTester) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.loadTester = tester }
+}
+
+// WithBulkIndexerLoadCheckInterval sets the minimum interval between two load tests while the load is acceptable.
+func WithBulkIndexerLoadCheckInterval(interval time.Duration) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.loadCheckInterval = interval }
+}
+
+// WithBulkIndexerLoadPause sets the duration to wait before testing the load again when it is too high.
+func WithBulkIndexerLoadPause(pause time.Duration) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.loadPause = pause }
+}
+
+// WithBulkIndexerMaxThrottle sets the factor by which the size of the bulk requests can be divided while the load is
+// high. A factor of 1 disables the slow-down, so flushes are only paused.
+func WithBulkIndexerMaxThrottle(factor int) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.maxThrottle = max(factor, 1) }
+}
+
+func WithBulkIndexerFlushItems(items int) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.flushItems = items }
+}
+
+func WithBulkIndexerFlushBytes(bytes int) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.flushBytes = bytes }
+}
+
+// WithBulkIndexerFlushInterval sets the interval between two flushes. A non-positive interval disables the interval
+// flushes, so items are only flushed when a limit is reached or on Flush and Close.
+func WithBulkIndexerFlushInterval(interval time.Duration) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.flushInterval = interval }
+}
+
+func WithBulkIndexerMaxRetries(retries int) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.maxRetries = retries }
+}
+
+// WithBulkIndexerMaxFlushAttempts sets the number of flushes an item can fail with a transient error before it is
+// reported as failed instead of being requeued.
+func WithBulkIndexerMaxFlushAttempts(attempts int) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.maxFlushAttempts = attempts }
+}
+
+func WithBulkIndexerRetryBackoff(backoff func(attempt int) time.Duration) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.retryBackoff = backoff }
+}
+
+// WithBulkIndexerOnError sets the function called for each item that could not be indexed, including during the
+// flushes triggered by the interval.
+func WithBulkIndexerOnError(onError func(err *BulkIndexerItemError)) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.onError = onError }
+}
+
+func WithBulkIndexerLogger(logger *log.Logger) BulkIndexerOption {
+	return func(b *BulkIndexer) { b.logger = logger }
+}
+
+// Add an item to the current batch. The batch is flushed when it is full.
+func (b *BulkIndexer) Add(ctx context.Context, item *BulkIndexerItem) error {
+	encodedItem, err := encodeBulkIndexerItem(item)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.items = append(b.items, item)
+	b.bytes += len(encodedItem)
+	isFull := len(b.items) >= b.flushItems || b.bytes >= b.flushBytes
+	b.mu.Unlock()
+
+	if isFull {
+		return b.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Flush the current batch. It returns a BulkIndexerError when some items could not be indexed, joined with the error
+// of the flush if it failed.
+func (b *BulkIndexer) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	items := b.items
+	b.items = nil
+	b.bytes = 0
+	b.mu.Unlock()
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemErrors := []*BulkIndexerItemError{}
+
+	var flushError error
+
+	for len(items) > 0 {
+		if err := b.waitForAcceptableLoad(ctx); err != nil {
+			flushError = err
+
+			break
+		}
+
+		batch := items[:min(b.batchSize(), len(items))]
+		items = items[len(batch):]
+
+		batchErrors, pendingItems, err := b.send(ctx, batch)
+		itemErrors = append(itemErrors, batchErrors...)
+
+		b.mu.Lock()
+		b.stats.Indexed += len(batch) - len(batchErrors) - len(pendingItems)
+		b.mu.Unlock()
+
+		if err != nil {
+			flushError = err
+			items = append(pendingItems, items...)
+
+			break
+		}
+	}
+
+	// The items which could not be sent because of the error are requeued, or failed after too many attempts.
+	if flushError != nil {
+		itemErrors = append(itemErrors, b.requeue(items, flushError)...)
+	}
+
+	b.mu.Lock()
+	if flushError == nil {
+		b.stats.Flushed++
+	}
+	b.stats.Failed += len(itemErrors)
+	b.mu.Unlock()
+
+	if b.onError != nil {
+		for _, itemError := range itemErrors {
+			b.onError(itemError)
+		}
+	}
+
+	if len(itemErrors) == 0 {
+		return flushError
+	}
+
+	return errors.Join(flushError, &BulkIndexerError{Items: itemErrors})
+}
+
+// Close stops the interval flushes and flushes the remaining items. It can be called several times.
+func (b *BulkIndexer) Close(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.stopTicker) })
+	<-b.tickerEnded
+
+	return b.Flush(ctx)
+}
+
+func (b *BulkIndexer) Stats() BulkIndexerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stats
+}
+
+// requeue the items which could not be sent because of the given error, so they are part of the next flush. The items
+// which reached the maximum number of flush attempts are returned as errors instead.
+func (b *BulkIndexer) requeue(items []*BulkIndexerItem, err error) []*BulkIndexerItemError {
+	result := []*BulkIndexerItemError{}
+	requeuedItems := []*BulkIndexerItem{}
+	requeuedBytes := 0
+
+	for _, item := range items {
+		item.flushAttempts++
+
+		if item.flushAttempts >= b.maxFlushAttempts {
+			result = append(result, &BulkIndexerItemError{Item: item, Type: "flush_failed", Reason: err.Error()})
+
+			continue
+		}
+
+		encodedItem, _ := encodeBulkIndexerItem(item)
+
+		requeuedItems = append(requeuedItems, item)
+		requeuedBytes += len(encodedItem)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items = append(requeuedItems, b.items...)
+	b.bytes += requeuedBytes
+
+	return result
+}
+
+// batchSize returns the maximum number of items of a bulk request, which is reduced while the load is high.
+func (b *BulkIndexer) batchSize() int { return max(b.flushItems/b.throttle, 1) }
+
+func (b *BulkIndexer) runTicker() {
+	defer close(b.tickerEnded)
+
+	if b.flushInterval <= 0 {
+		<-b.stopTicker
+
+		return
+	}
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopTicker:
+			return
+		case <-ticker.C:
+			if err := b.Flush(context.Background()); err != nil {
+				b.logger.Error("Could not flush bulk indexer", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// waitForAcceptableLoad blocks while the load tester reports a high load, and adapts the throttle to the load. The load
+// is not tested more than once per load check interval while it is acceptable and the indexer is not slowed down.
+func (b *BulkIndexer) waitForAcceptableLoad(ctx context.Context) error {
+	if b.loadTester == nil || (b.throttle == 1 && time.Since(b.lastLoadAt) < b.loadCheckInterval) {
+		return nil
+	}
+
+	for {
+		isAcceptable, err := b.loadTester.Test()
+		if err != nil {
+			b.logger.Error("Could not test load, flushing anyway", slog.String("error", err.Error()))
+
+			return nil
+		}
+
+		if isAcceptable {
+			b.lastLoadAt = time.Now()
+			b.throttle = max(b.throttle/2, 1) //nolint:mnd
+
+			return nil
+		}
+
+		b.throttle = min(b.throttle*2, b.maxThrottle) //nolint:mnd
+
+		b.logger.Debug("Pausing bulk indexer because of a high load",
+			slog.String("pause", b.loadPause.String()),
+			slog.Int("throttle", b.throttle),
+		)
+
+		if err := sleepWithContext(ctx, b.loadPause); err != nil {
+			return err
+		}
+
+		b.mu.Lock()
+		b.stats.Paused += b.loadPause
+		b.mu.Unlock()
+	}
+}
+
+// send the given items, retrying the ones rejected because of too many requests. It returns the errors of the items
+// which could not be indexed and, if the bulk request failed with a transient error, the items still pending.
+func (b *BulkIndexer) send(
+	ctx context.Context,
+	items []*BulkIndexerItem,
+) ([]*BulkIndexerItemError, []*BulkIndexerItem, error) {
+	result := []*BulkIndexerItemError{}
+	pendingItems := items
+
+	for attempt := 0; ; attempt++ {
+		body, encodedItems, encodeErrors := encodeBulkIndexerItems(pendingItems)
+		result = append(result, encodeErrors...)
+		pendingItems = encodedItems
+
+		if len(pendingItems) == 0 {
+			return result, nil, nil
+		}
+
+		response, err := b.execute(ctx, body)
+
+		requestError := &bulkRequestError{}
+		if errors.As(err, &requestError) && !requestError.isTransient() {
+			for _, item := range pendingItems {
+				result = append(result, &BulkIndexerItemError{
+					Item:   item,
+					Status: requestError.status,
+					Type:   "bulk_request_rejected",
+					Reason: requestError.Error(),
+				})
+			}
+
+			return result, nil, nil
+		} else if err != nil {
+			return result, pendingItems, err
+		}
+
+		retryableItems := []*BulkIndexerItem{}
+
+		for i, item := range pendingItems {
+			itemError := response.itemError(i, item)
+
+			switch {
+			case itemError == nil:
+				continue
+			case itemError.Status == http.StatusTooManyRequests && attempt < b.maxRetries:
+				retryableItems = append(retryableItems, item)
+			default:
+				result = append(result, itemError)
+			}
+		}
+
+		if len(retryableItems) == 0 {
+			return result, nil, nil
+		}
+
+		b.mu.Lock()
+		b.stats.Retried += len(retryableItems)
+		b.mu.Unlock()
+
+		if err := sleepWithContext(ctx, b.retryBackoff(attempt)); err != nil {
+			return result, retryableItems, err
+		}
+
+		pendingItems = retryableItems
+	}
+}
+
+// bulkRequestError is returned when a whole bulk request is rejected by Elasticsearch.
+type bulkRequestError struct{ status int }
+
+func (e *bulkRequestError) Error() string {
+	return fmt.Sprintf("bulk request rejected with status code %d", e.status)
+}
+
+// isTransient returns whether the request may succeed if it is sent again later.
+func (e *bulkRequestError) isTransient() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= http.StatusInternalServerError
+}
+
+// execute a bulk request. When the whole request is rejected with a 429 status code, it is retried.
+func (b *BulkIndexer) execute(ctx context.Context, body []byte) (*bulkResponse, error) {
+	for attempt := 0; ; attempt++ {
+		response, err := b.client.Bulk(bytes.NewReader(body), b.client.Bulk.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("could not execute bulk request: %w", err)
+		}
+
+		if response.StatusCode == http.StatusTooManyRequests && attempt < b.maxRetries {
+			response.Body.Close()
+
+			if err := sleepWithContext(ctx, b.retryBackoff(attempt)); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if response.IsError() {
+			response.Body.Close()
+
+			return nil, &bulkRequestError{status: response.StatusCode}
+		}
+
+		bodyAsString, err := ParseResponseBody(response)
+		if err != nil {
+			return nil, fmt.Errorf("could not execute bulk request: %w", err)
+		}
+
+		result := &bulkResponse{}
+		if err := json.Unmarshal([]byte(bodyAsString), result); err != nil {
+			return nil, fmt.Errorf("could not decode bulk response: %w", err)
+		}
+
+		return result, nil
+	}
+}
+
+// encodeBulkIndexerItems in the body of a bulk request. It returns the encoded items, and the errors of the items which
+// could not be encoded.
+func encodeBulkIndexerItems(items []*BulkIndexerItem) ([]byte, []*BulkIndexerItem, []*BulkIndexerItemError) {
+	body := &bytes.Buffer{}
+	encodedItems := []*BulkIndexerItem{}
+	itemErrors := []*BulkIndexerItemError{}
+
+	for _, item := range items {
+		encodedItem, err := encodeBulkIndexerItem(item)
+		if err != nil {
+			itemErrors = append(itemErrors, &BulkIndexerItemError{Item: item, Type: "encoding_error", Reason: err.Error()})
+
+			continue
+		}
+
+		body.Write(encodedItem)
+		encodedItems = append(encodedItems, item)
+	}
+
+	return body.Bytes(), encodedItems, itemErrors
+}
+
+func encodeBulkIndexerItem(item *BulkIndexerItem) ([]byte, error) {
+	type action struct {
+		Index string `json:"_index"`
+		ID    string `json:"_id,omitempty"`
+	}
+
+	encodedAction, err := json.Marshal(map[string]action{"index": {Index: item.Index, ID: item.ID}})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode bulk action: %w", err)
+	}
+
+	encodedDocument, err := json.Marshal(item.Document)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode document: %w", err)
+	}
+
+	return bytes.Join([][]byte{encodedAction, encodedDocument, nil}, []byte("\n")), nil
+}
+
+// Reference: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html#bulk-api-response-body
+type bulkResponse struct {
+	Errors bool                                 `json:"errors"`
+	Items  []map[string]*bulkResponseItemResult `json:"items"`
+}
+
+type bulkResponseItemResult struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// itemError returns the error of the item at the given position, if any.
+func (r *bulkResponse) itemError(i int, item *BulkIndexerItem) *BulkIndexerItemError {
+	if i >= len(r.Items) {
+		return &BulkIndexerItemError{Item: item, Type: "missing_item", Reason: "missing item in bulk response"}
+	}
+
+	for _, itemResult := range r.Items[i] {
+		if itemResult.Status < http.StatusMultipleChoices {
+			return nil
+		}
+
+		result := &BulkIndexerItemError{Item: item, Status: itemResult.Status}
+		if itemResult.Error != nil {
+			result.Type = itemResult.Error.Type
+			result.Reason = itemResult.Error.Reason
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
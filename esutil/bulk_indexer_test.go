@@ -0,0 +1,280 @@
+package esutil
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkIndexer(t *testing.T) {
+	server := newBulkServerMock(t, []string{
+		// First request: the first item is rejected because of too many requests, the second one is invalid.
+		`{"errors":true,"items":[
+			{"index":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"rejected"}}},
+			{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"invalid"}}},
+			{"index":{"status":201}}
+		]}`,
+		// Second request: the retried item is indexed.
+		`{"errors":false,"items":[{"index":{"status":201}}]}`,
+	})
+	defer server.Close()
+
+	itemErrors := []*BulkIndexerItemError{}
+	tester := &loadTesterMock{results: []bool{false, true}}
+
+	indexer := NewBulkIndexer(newTestClient(t, server.URL),
+		WithBulkIndexerFlushItems(3),
+		WithBulkIndexerFlushInterval(time.Hour),
+		WithBulkIndexerLoadTester(tester),
+		WithBulkIndexerLoadPause(time.Millisecond),
+		WithBulkIndexerRetryBackoff(func(int) time.Duration { return time.Millisecond }),
+		WithBulkIndexerOnError(func(err *BulkIndexerItemError) { itemErrors = append(itemErrors, err) }),
+	)
+
+	ctx := context.Background()
+
+	require.NoError(t, indexer.Add(ctx, &BulkIndexerItem{Index: "guests", ID: "1", Document: &GuestCardDocument{}}))
+	require.NoError(t, indexer.Add(ctx, &BulkIndexerItem{Index: "guests", ID: "2", Document: &GuestCardDocument{}}))
+
+	err := indexer.Add(ctx, &BulkIndexerItem{Index: "bookings", ID: "3", Document: &GuestBookingDocument{}})
+
+	bulkIndexerError := &BulkIndexerError{}
+	require.ErrorAs(t, err, &bulkIndexerError)
+	require.Len(t, bulkIndexerError.Items, 1)
+	assert.Equal(t, "2", bulkIndexerError.Items[0].Item.ID)
+	assert.Equal(t, "mapper_parsing_exception", bulkIndexerError.Items[0].Type)
+	assert.Equal(t, bulkIndexerError.Items, itemErrors)
+
+	assert.Equal(t, [][]string{{"1", "2", "3"}, {"1"}}, server.requestedIDs)
+	assert.Equal(t, 2, tester.calls)
+
+	require.NoError(t, indexer.Close(ctx))
+
+	assert.Equal(t, BulkIndexerStats{
+		Indexed: 2,
+		Failed:  1,
+		Retried: 1,
+		Flushed: 1,
+		Paused:  time.Millisecond,
+	}, indexer.Stats())
+}
+
+func TestBulkIndexerFlushInterval(t *testing.T) {
+	server := newBulkServerMock(t, []string{`{"errors":false,"items":[{"index":{"status":201}}]}`})
+	defer server.Close()
+
+	indexer := NewBulkIndexer(newTestClient(t, server.URL), WithBulkIndexerFlushInterval(time.Millisecond))
+
+	require.NoError(t, indexer.Add(context.Background(), &BulkIndexerItem{Index: "guests", ID: "1"}))
+	assert.Eventually(t, func() bool { return indexer.Stats().Indexed == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, indexer.Close(context.Background()))
+}
+
+func TestBulkIndexerWithoutFlushInterval(t *testing.T) {
+	server := newBulkServerMock(t, []string{`{"errors":false,"items":[{"index":{"status":201}}]}`})
+	defer server.Close()
+
+	indexer := NewBulkIndexer(newTestClient(t, server.URL), WithBulkIndexerFlushInterval(0))
+
+	require.NoError(t, indexer.Add(context.Background(), &BulkIndexerItem{Index: "guests", ID: "1"}))
+	assert.Zero(t, indexer.Stats().Indexed)
+
+	require.NoError(t, indexer.Close(context.Background()))
+	assert.Equal(t, 1, indexer.Stats().Indexed)
+
+	// Closing again only flushes the remaining items, which are none.
+	require.NoError(t, indexer.Close(context.Background()))
+}
+
+func TestBulkIndexerCanceledRetry(t *testing.T) {
+	server := newBulkServerMock(t, []string{
+		`{"errors":true,"items":[{"index":{"status":429}},{"index":{"status":201}}]}`,
+		`{"errors":false,"items":[{"index":{"status":201}}]}`,
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	indexer := NewBulkIndexer(newTestClient(t, server.URL),
+		WithBulkIndexerFlushInterval(time.Hour),
+		WithBulkIndexerRetryBackoff(func(int) time.Duration {
+			cancel() // Cancel the flush while waiting to retry the first item.
+
+			return time.Hour
+		}),
+	)
+
+	require.NoError(t, indexer.Add(ctx, &BulkIndexerItem{Index: "bookings", Document: &GuestBookingDocument{}}))
+	require.NoError(t, indexer.Add(ctx, &BulkIndexerItem{Index: "bookings", ID: "2"}))
+	require.ErrorIs(t, indexer.Flush(ctx), context.Canceled)
+
+	// Only the item which was not indexed is sent again.
+	require.NoError(t, indexer.Close(context.Background()))
+	assert.Equal(t, [][]string{{"", "2"}, {""}}, server.requestedIDs)
+	assert.Equal(t, BulkIndexerStats{Indexed: 2, Retried: 1, Flushed: 1}, indexer.Stats())
+}
+
+func TestBulkIndexerFailedRequests(t *testing.T) {
+	tests := map[string]struct {
+		statusCodes  []int
+		wantRequests int
+		wantError    *BulkIndexerItemError
+	}{
+		"rejected request": {
+			statusCodes:  []int{http.StatusRequestEntityTooLarge},
+			wantRequests: 1,
+			wantError:    &BulkIndexerItemError{Status: http.StatusRequestEntityTooLarge, Type: "bulk_request_rejected"},
+		},
+		"transient error": {
+			statusCodes:  []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantRequests: 2,
+			wantError:    &BulkIndexerItemError{Type: "flush_failed"},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			server := newBulkServerMock(t, make([]string, len(tt.statusCodes)))
+			server.statusCodes = tt.statusCodes
+
+			defer server.Close()
+
+			itemErrors := []*BulkIndexerItemError{}
+
+			indexer := NewBulkIndexer(newTestClient(t, server.URL),
+				WithBulkIndexerFlushInterval(time.Hour),
+				WithBulkIndexerMaxRetries(0),
+				WithBulkIndexerMaxFlushAttempts(2),
+				WithBulkIndexerOnError(func(err *BulkIndexerItemError) { itemErrors = append(itemErrors, err) }),
+			)
+
+			ctx := context.Background()
+			item := &BulkIndexerItem{Index: "guests", ID: "1"}
+
+			require.NoError(t, indexer.Add(ctx, item))
+
+			for range tt.wantRequests {
+				assert.Error(t, indexer.Flush(ctx))
+			}
+
+			// The item is not requeued anymore.
+			require.NoError(t, indexer.Close(ctx))
+			assert.Len(t, server.requestedIDs, tt.wantRequests)
+
+			require.Len(t, itemErrors, 1)
+			assert.Equal(t, item, itemErrors[0].Item)
+			assert.Equal(t, tt.wantError.Status, itemErrors[0].Status)
+			assert.Equal(t, tt.wantError.Type, itemErrors[0].Type)
+			assert.Equal(t, 1, indexer.Stats().Failed)
+		})
+	}
+}
+
+func TestBulkIndexerThrottle(t *testing.T) {
+	server := newBulkServerMock(t, []string{
+		`{"errors":false,"items":[{"index":{"status":201}},{"index":{"status":201}}]}`,
+		`{"errors":false,"items":[{"index":{"status":201}},{"index":{"status":201}}]}`,
+	})
+	defer server.Close()
+
+	tester := &loadTesterMock{results: []bool{false, false, true, true}}
+
+	indexer := NewBulkIndexer(newTestClient(t, server.URL),
+		WithBulkIndexerFlushItems(4),
+		WithBulkIndexerFlushInterval(time.Hour),
+		WithBulkIndexerLoadTester(tester),
+		WithBulkIndexerLoadPause(time.Millisecond),
+	)
+
+	ctx := context.Background()
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, indexer.Add(ctx, &BulkIndexerItem{Index: "guests", ID: id}))
+	}
+
+	// The high load divides the size of the requests by 4, and the next acceptable test only multiplies it by 2, so the
+	// load is tested again before the second request.
+	require.NoError(t, indexer.Close(ctx))
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}}, server.requestedIDs)
+	assert.Equal(t, 4, tester.calls)
+}
+
+type bulkServerMock struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	requestedIDs [][]string
+
+	// statusCodes of the responses, if not 200.
+	statusCodes []int
+}
+
+func newBulkServerMock(t *testing.T, responses []string) *bulkServerMock {
+	t.Helper()
+
+	result := &bulkServerMock{}
+	result.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result.mu.Lock()
+		defer result.mu.Unlock()
+
+		ids := []string{}
+
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 1 {
+				continue // Skip documents.
+			}
+
+			action := map[string]struct {
+				ID string `json:"_id"`
+			}{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &action))
+
+			ids = append(ids, action["index"].ID)
+		}
+
+		response := responses[len(result.requestedIDs)]
+		result.requestedIDs = append(result.requestedIDs, ids)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		if i := len(result.requestedIDs) - 1; i < len(result.statusCodes) {
+			w.WriteHeader(result.statusCodes[i])
+		}
+
+		_, _ = w.Write([]byte(response))
+	}))
+
+	return result
+}
+
+func newTestClient(t *testing.T, address string) *elasticsearch.Client {
+	t.Helper()
+
+	result, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{address}})
+	require.NoError(t, err)
+
+	return result
+}
+
+type loadTesterMock struct {
+	results []bool
+	calls   int
+}
+
+func (m *loadTesterMock) Test() (bool, error) {
+	result := m.results[m.calls]
+	m.calls++
+
+	return result, nil
+}
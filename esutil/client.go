@@ -14,12 +14,11 @@ import (
 	"github.com/elastic/elastic-transport-go/v8/elastictransport"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
-	"github.com/loungeup/go-loungeup/errors"
 	"github.com/loungeup/go-loungeup/log"
 )
 
-func NewClient(addresses []string, username, password string) (*elasticsearch.Client, error) {
-	result, err := elasticsearch.NewClient(newClientConfig(addresses, username, password))
+func NewClient(addresses []string, username, password string, options ...ClientOption) (*elasticsearch.Client, error) {
+	result, err := elasticsearch.NewClient(newClientConfig(addresses, username, password, options...))
 	if err != nil {
 		return nil, fmt.Errorf("could not create Elasticsearch client: %w", err)
 	}
@@ -31,8 +30,12 @@ func NewClient(addresses []string, username, password string) (*elasticsearch.Cl
 	return result, nil
 }
 
-func NewTypedClient(addresses []string, username, password string) (*elasticsearch.TypedClient, error) {
-	result, err := elasticsearch.NewTypedClient(newClientConfig(addresses, username, password))
+func NewTypedClient(
+	addresses []string,
+	username, password string,
+	options ...ClientOption,
+) (*elasticsearch.TypedClient, error) {
+	result, err := elasticsearch.NewTypedClient(newClientConfig(addresses, username, password, options...))
 	if err != nil {
 		return nil, fmt.Errorf("could not create Elasticsearch client: %w", err)
 	}
@@ -44,9 +47,14 @@ func NewTypedClient(addresses []string, username, password string) (*elasticsear
 	return result, nil
 }
 
-func newClientConfig(addresses []string, username, password string) elasticsearch.Config {
+func newClientConfig(addresses []string, username, password string, options ...ClientOption) elasticsearch.Config {
 	logger := log.Default().With(slog.String("component", "elasticsearch"))
 
+	clientOptions := &clientOptions{}
+	for _, option := range options {
+		option(clientOptions)
+	}
+
 	//nolint:mnd
 	return elasticsearch.Config{
 		// Authentication.
@@ -104,12 +112,18 @@ func newClientConfig(addresses []string, username, password string) elasticsearc
 			http.StatusGatewayTimeout,
 		},
 
-		Logger:    &clientLogger{baseLogger: logger},
-		Transport: &clientTransport{baseTransport: http.DefaultTransport},
+		Logger: &clientLogger{baseLogger: logger, diagnostics: clientOptions.diagnostics},
+		Transport: &clientTransport{
+			baseTransport: http.DefaultTransport,
+			diagnostics:   clientOptions.diagnostics,
+		},
 	}
 }
 
-type clientLogger struct{ baseLogger *log.Logger }
+type clientLogger struct {
+	baseLogger  *log.Logger
+	diagnostics *ClientDiagnostics
+}
 
 var _ (elastictransport.Logger) = (*clientLogger)(nil)
 
@@ -150,6 +164,7 @@ func (l *clientLogger) LogRoundTrip(
 		l.baseLogger.Debug("Could not execute invalid request", attrs...)
 	default:
 		l.baseLogger.Debug("Request executed", attrs...)
+		l.diagnostics.logSlowSearch(l.baseLogger, request, response, duration)
 	}
 
 	return nil
@@ -220,11 +235,18 @@ func convertReaderToLogAttrValue(contentType string, reader io.Reader) any {
 	}
 }
 
-type clientTransport struct{ baseTransport http.RoundTripper }
+type clientTransport struct {
+	baseTransport http.RoundTripper
+	diagnostics   *ClientDiagnostics
+}
 
 var _ (http.RoundTripper) = (*clientTransport)(nil)
 
 func (t *clientTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.diagnostics.sample(request) {
+		request = t.diagnostics.profileRequest(request)
+	}
+
 	response, err := t.baseTransport.RoundTrip(request)
 	if err != nil {
 		return nil, err
@@ -246,37 +268,23 @@ func (t *clientTransport) RoundTrip(request *http.Request) (*http.Response, erro
 		return response, nil // Ignore.
 	}
 
-	if err := parseShardFailures(searchResponse.Shards_.Failures); err != nil {
-		return response, err
+	shardFailuresError := newShardFailuresError(searchResponse.Shards_.Failures)
+	if shardFailuresError == nil {
+		return response, nil
+	}
+
+	// Some shards succeeded, so the caller might accept the partial results.
+	if partialResults := partialResultsFromContext(request.Context()); partialResults != nil &&
+		searchResponse.Shards_.Successful > 0 {
+		partialResults.add(shardFailuresError.Failures...)
+
+		return response, nil
 	}
 
-	return response, nil
+	return response, shardFailuresError.appError()
 }
 
 // Reference: https://github.com/elastic/go-elasticsearch/blob/1dda5df4f11fd5f15264279dd6c773f0f97b9536/typedapi/core/search/response.go#L48
 type partialSearchResponse struct {
 	Shards_ types.ShardStatistics `json:"_shards"`
 }
-
-func parseShardFailures(failures []types.ShardFailure) error {
-	if len(failures) == 0 {
-		return nil
-	}
-
-	failure := failures[0].Reason // We only care about the first failure.
-
-	underlyingError := func() error {
-		if reason := failure.Reason; reason != nil {
-			return fmt.Errorf("could not execute request because of a shard failure: %s", *reason)
-		}
-
-		return fmt.Errorf("could not execute request because of a shard failure")
-	}()
-
-	switch failure.Type {
-	case "query_shard_exception":
-		return &errors.Error{Code: errors.CodeInvalid, UnderlyingError: underlyingError}
-	default:
-		return underlyingError
-	}
-}
@@ -0,0 +1,239 @@
+package esutil
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/loungeup/go-loungeup/log"
+)
+
+// ClientOption configures the clients created by NewClient and NewTypedClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	diagnostics *ClientDiagnostics
+}
+
+// WithClientDiagnostics enables the slow search logs of the client.
+func WithClientDiagnostics(diagnostics *ClientDiagnostics) ClientOption {
+	return func(o *clientOptions) { o.diagnostics = diagnostics }
+}
+
+// ClientDiagnostics logs the profile of slow searches. A sample of the searches targeting an index with a slow search
+// threshold are executed with `profile: true`, and their profile is summarized in the logs when they are slower than
+// the threshold.
+//
+// Searches without indices in their path, such as the point in time searches of the pagination package, are not
+// profiled: their indices are only known by Elasticsearch.
+type ClientDiagnostics struct {
+	// SlowSearchThresholds by index pattern. The first pattern matching an index of a search is used.
+	SlowSearchThresholds []*SlowSearchThreshold
+
+	// SampleRate is the ratio of searches that are profiled, between 0 and 1.
+	SampleRate float64
+
+	// ProfileNodesLimit is the number of query nodes logged, slowest first. Defaults to 5.
+	ProfileNodesLimit int
+
+	// randFunc returns a number in [0, 1) used to sample searches.
+	randFunc func() float64
+}
+
+// SlowSearchThreshold of the indices matching a pattern (e.g. "*-guestbookings-*"), using the syntax of path.Match.
+type SlowSearchThreshold struct {
+	IndexPattern string
+	Threshold    time.Duration
+}
+
+// threshold of the search executed by the given request, or false if the request is not a search or its indices do not
+// have a threshold.
+func (d *ClientDiagnostics) threshold(request *http.Request) (time.Duration, bool) {
+	if d == nil {
+		return 0, false
+	}
+
+	indices, ok := parseSearchIndices(request)
+	if !ok {
+		return 0, false
+	}
+
+	for _, threshold := range d.SlowSearchThresholds {
+		if slices.ContainsFunc(indices, func(index string) bool {
+			matched, _ := path.Match(threshold.IndexPattern, index)
+
+			return matched
+		}) {
+			return threshold.Threshold, true
+		}
+	}
+
+	return 0, false
+}
+
+func (d *ClientDiagnostics) sample(request *http.Request) bool {
+	if _, ok := d.threshold(request); !ok {
+		return false
+	}
+
+	randFunc := d.randFunc
+	if randFunc == nil {
+		randFunc = rand.Float64
+	}
+
+	return randFunc() < d.SampleRate
+}
+
+// profileRequest returns a copy of the given search request with profiling enabled.
+func (d *ClientDiagnostics) profileRequest(request *http.Request) *http.Request {
+	body := map[string]json.RawMessage{}
+
+	if request.Body != nil && request.Body != http.NoBody {
+		encodedBody, err := io.ReadAll(request.Body)
+		if err != nil {
+			return request
+		}
+
+		request.Body = io.NopCloser(bytes.NewReader(encodedBody)) // Restore.
+
+		if err := json.Unmarshal(encodedBody, &body); err != nil {
+			return request // Not a search body that can be profiled.
+		}
+	}
+
+	body["profile"] = json.RawMessage(`true`)
+
+	encodedBody, err := json.Marshal(body)
+	if err != nil {
+		return request
+	}
+
+	result := request.Clone(request.Context())
+	result.Body = io.NopCloser(bytes.NewReader(encodedBody))
+	result.ContentLength = int64(len(encodedBody))
+	result.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(encodedBody)), nil }
+
+	return result
+}
+
+// logSlowSearch logs the profile of the search if it was sampled and is slower than the threshold of its indices.
+func (d *ClientDiagnostics) logSlowSearch(
+	logger *log.Logger,
+	request *http.Request,
+	response *http.Response,
+	duration time.Duration,
+) {
+	threshold, ok := d.threshold(request)
+	if !ok || duration < threshold || response == nil || response.Body == nil {
+		return
+	}
+
+	searchResponse := &profiledSearchResponse{}
+	if err := json.NewDecoder(response.Body).Decode(searchResponse); err != nil || searchResponse.Profile == nil {
+		return // The search was not sampled.
+	}
+
+	profileNodesLimit := d.ProfileNodesLimit
+	if profileNodesLimit <= 0 {
+		profileNodesLimit = 5
+	}
+
+	nodes := searchResponse.Profile.nodes()
+	slices.SortStableFunc(nodes, func(a, b *profiledQueryNode) int { return cmp.Compare(b.TimeInNanos, a.TimeInNanos) })
+
+	logger.FormattedWarn("Slow search executed",
+		slog.String("url", request.URL.String()),
+		slog.Int64("durationInMilliseconds", duration.Milliseconds()),
+		slog.Int64("thresholdInMilliseconds", threshold.Milliseconds()),
+		slog.Int64("tookInMilliseconds", searchResponse.Took),
+		slog.Any("slowestQueryNodes", nodes[:min(len(nodes), profileNodesLimit)]),
+	)
+}
+
+// parseSearchIndices returns the indices targeted by a search request, or false if the request is not a search or does
+// not name its indices (e.g. a point in time search, whose indices are the ones of its point in time).
+func parseSearchIndices(request *http.Request) ([]string, bool) {
+	if request.Method != http.MethodGet && request.Method != http.MethodPost {
+		return nil, false
+	}
+
+	indices, ok := strings.CutSuffix(strings.Trim(request.URL.Path, "/"), "/_search")
+	if !ok || indices == "" || strings.Contains(indices, "/") {
+		return nil, false
+	}
+
+	return strings.Split(indices, ","), true
+}
+
+// Reference: https://www.elastic.co/guide/en/elasticsearch/reference/current/search-profile.html
+type profiledSearchResponse struct {
+	Took    int64          `json:"took"`
+	Profile *searchProfile `json:"profile"`
+}
+
+type searchProfile struct {
+	Shards []struct {
+		ID       string `json:"id"`
+		Searches []struct {
+			Query []*profiledQueryNode `json:"query"`
+		} `json:"searches"`
+	} `json:"shards"`
+}
+
+// nodes of every query of every shard, children included.
+func (p *searchProfile) nodes() []*profiledQueryNode {
+	result := []*profiledQueryNode{}
+
+	var walk func(shardID string, nodes []*profiledQueryNode)
+	walk = func(shardID string, nodes []*profiledQueryNode) {
+		for _, node := range nodes {
+			node.ShardID = shardID
+			result = append(result, node)
+			walk(shardID, node.Children)
+		}
+	}
+
+	for _, shard := range p.Shards {
+		for _, search := range shard.Searches {
+			walk(shard.ID, search.Query)
+		}
+	}
+
+	return result
+}
+
+type profiledQueryNode struct {
+	ShardID     string               `json:"shard"`
+	Type        string               `json:"type"`
+	Description string               `json:"description"`
+	TimeInNanos int64                `json:"time_in_nanos"`
+	Children    []*profiledQueryNode `json:"-"`
+}
+
+var _ json.Unmarshaler = (*profiledQueryNode)(nil)
+
+// UnmarshalJSON reads the children of the node, which are not logged.
+func (n *profiledQueryNode) UnmarshalJSON(data []byte) error {
+	type Alias profiledQueryNode
+
+	alias := struct {
+		*Alias
+		Children []*profiledQueryNode `json:"children"`
+	}{Alias: (*Alias)(n)}
+
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	n.Children = alias.Children
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package esutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/loungeup/go-loungeup/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		body := map[string]json.RawMessage{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if _, ok := body["profile"]; !ok {
+			_, _ = w.Write([]byte(`{"took": 12, "hits": {"hits": []}}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"took": 12, "hits": {"hits": []}, "profile": {"shards": [{
+			"id": "[node][index][0]",
+			"searches": [{"query": [{
+				"type": "BooleanQuery",
+				"description": "+guest.chain.entityId:abc +booking.arrival:[1 TO 2]",
+				"time_in_nanos": 300,
+				"children": [
+					{"type": "TermQuery", "description": "guest.chain.entityId:abc", "time_in_nanos": 100},
+					{"type": "IndexOrDocValuesQuery", "description": "booking.arrival:[1 TO 2]", "time_in_nanos": 200}
+				]
+			}]}]
+		}]}}`))
+	}))
+	defer server.Close()
+
+	newClient := func(t *testing.T, sampled bool) (*elasticsearch.Client, *bytes.Buffer) {
+		t.Helper()
+
+		logs := &bytes.Buffer{}
+		diagnostics := &ClientDiagnostics{
+			SlowSearchThresholds: []*SlowSearchThreshold{
+				{IndexPattern: "*-guestcards-*", Threshold: time.Hour},
+				{IndexPattern: "*-guestbookings-*", Threshold: 0},
+			},
+			SampleRate:        0.5,
+			ProfileNodesLimit: 2,
+			randFunc: func() float64 {
+				if sampled {
+					return 0.1
+				}
+
+				return 0.9
+			},
+		}
+
+		result, err := elasticsearch.NewClient(elasticsearch.Config{
+			Addresses: []string{server.URL},
+			Logger: &clientLogger{
+				baseLogger:  log.NewLogger(log.WithLoggerWriter(logs)),
+				diagnostics: diagnostics,
+			},
+			Transport: &clientTransport{baseTransport: http.DefaultTransport, diagnostics: diagnostics},
+		})
+		require.NoError(t, err)
+
+		return result, logs
+	}
+
+	search := func(t *testing.T, client *elasticsearch.Client, index string) {
+		t.Helper()
+
+		response, err := client.Search(
+			client.Search.WithContext(context.Background()),
+			client.Search.WithIndex(index),
+			client.Search.WithBody(strings.NewReader(`{"query": {"match_all": {}}}`)),
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+	}
+
+	t.Run("sampled slow search", func(t *testing.T) {
+		client, logs := newClient(t, true)
+		search(t, client, "production-guestbookings-2024-01")
+
+		entry := findLogEntry(t, logs, "Slow search executed")
+		require.NotNil(t, entry)
+		assert.JSONEq(t, `"warn"`, string(entry["status"]))
+		assert.JSONEq(t, `[
+			{"shard": "[node][index][0]", "type": "BooleanQuery", "description": "+guest.chain.entityId:abc +booking.arrival:[1 TO 2]", "time_in_nanos": 300},
+			{"shard": "[node][index][0]", "type": "IndexOrDocValuesQuery", "description": "booking.arrival:[1 TO 2]", "time_in_nanos": 200}
+		]`, string(entry["slowestQueryNodes"]))
+	})
+
+	t.Run("sampled fast search", func(t *testing.T) {
+		client, logs := newClient(t, true)
+		search(t, client, "production-guestcards-2024-01")
+
+		assert.Nil(t, findLogEntry(t, logs, "Slow search executed"))
+	})
+
+	t.Run("not sampled", func(t *testing.T) {
+		client, logs := newClient(t, false)
+		search(t, client, "production-guestbookings-2024-01")
+
+		assert.Nil(t, findLogEntry(t, logs, "Slow search executed"))
+	})
+}
+
+func TestParseSearchIndices(t *testing.T) {
+	tests := map[string]struct {
+		method, path string
+		want         []string
+		wantOK       bool
+	}{
+		"single index":   {method: http.MethodPost, path: "/a/_search", want: []string{"a"}, wantOK: true},
+		"many indices":   {method: http.MethodGet, path: "/a,b/_search", want: []string{"a", "b"}, wantOK: true},
+		"without index":  {method: http.MethodPost, path: "/_search"},
+		"not a search":   {method: http.MethodPost, path: "/a/_count"},
+		"invalid method": {method: http.MethodDelete, path: "/a/_search"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := parseSearchIndices(httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// findLogEntry returns the first JSON log entry with the given message, or nil.
+func findLogEntry(t *testing.T, logs *bytes.Buffer, message string) map[string]json.RawMessage {
+	t.Helper()
+
+	decoder := json.NewDecoder(bytes.NewReader(logs.Bytes()))
+	for decoder.More() {
+		entry := map[string]json.RawMessage{}
+		require.NoError(t, decoder.Decode(&entry))
+
+		if string(entry["message"]) == `"`+message+`"` {
+			return entry
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package esutil
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/resmodels"
+)
+
+// ComputedAttr describes how a computed attribute is aggregated from the bookings of a guest.
+type ComputedAttr struct {
+	// AggFunc builds the aggregation of the attribute. It is called for each config, so it must return a new value
+	// every time.
+	AggFunc      func(entityType resmodels.EntityType) estypes.Aggregations
+	AggType      ComputedAttrAggType
+	MapValueFunc func(aggregate estypes.Aggregate) (any, error)
+}
+
+// ComputedAttrRegistry of the computed attributes known by a service. It is safe for concurrent use.
+type ComputedAttrRegistry struct {
+	mu    sync.RWMutex
+	attrs map[string]*ComputedAttr
+}
+
+// DefaultComputedAttrRegistry contains the computed attributes shared by every service. Services can register their
+// own attributes into it.
+var DefaultComputedAttrRegistry = newDefaultComputedAttrRegistry()
+
+// ErrDuplicateComputedAttr is returned when registering an attribute whose name is already taken.
+var ErrDuplicateComputedAttr = errors.New("duplicate computed attribute")
+
+func NewComputedAttrRegistry() *ComputedAttrRegistry {
+	return &ComputedAttrRegistry{attrs: map[string]*ComputedAttr{}}
+}
+
+func newDefaultComputedAttrRegistry() *ComputedAttrRegistry {
+	result := NewComputedAttrRegistry()
+
+	for name, attr := range defaultComputedAttrs {
+		result.MustRegister(name, attr)
+	}
+
+	return result
+}
+
+// Register a computed attribute under the given name.
+func (r *ComputedAttrRegistry) Register(name string, attr *ComputedAttr) error {
+	if name == "" {
+		return errors.New("computed attribute name is required")
+	}
+
+	if attr == nil || attr.AggFunc == nil || attr.MapValueFunc == nil {
+		return fmt.Errorf("computed attribute %q requires an aggregation and a mapper", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.attrs[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateComputedAttr, name)
+	}
+
+	r.attrs[name] = &ComputedAttr{
+		AggFunc:      attr.AggFunc,
+		AggType:      attr.AggType,
+		MapValueFunc: attr.MapValueFunc,
+	}
+
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant to be used when initializing a service.
+func (r *ComputedAttrRegistry) MustRegister(name string, attr *ComputedAttr) {
+	if err := r.Register(name, attr); err != nil {
+		panic(err)
+	}
+}
+
+// Config of the computed attribute with the given name, built for the given entity type. Every call returns a new
+// aggregation, which can be modified by the caller.
+func (r *ComputedAttrRegistry) Config(name string, entityType resmodels.EntityType) (ComputedAttrAggConfig, bool) {
+	r.mu.RLock()
+	attr, ok := r.attrs[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return ComputedAttrAggConfig{}, false
+	}
+
+	return ComputedAttrAggConfig{
+		Agg:          attr.AggFunc(entityType),
+		AggType:      attr.AggType,
+		MapValueFunc: attr.MapValueFunc,
+	}, true
+}
+
+// Names of the registered computed attributes, sorted.
+func (r *ComputedAttrRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.attrs))
+}
@@ -0,0 +1,287 @@
+package esutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/pagination"
+	"github.com/loungeup/go-loungeup/pointer"
+	"github.com/loungeup/go-loungeup/resmodels"
+)
+
+const (
+	computedAttrsBatchGuestsAgg = "guests"
+	computedAttrsBatchGuestKey  = "guestId"
+)
+
+// GuestComputedAttributes computed for a guest by a ComputedAttrsBatcher, ready to be written in the
+// typedComputedAttributes field of the scope.
+type GuestComputedAttributes struct {
+	GuestID    string
+	Attributes *ScopedTypedComputedAttributes
+}
+
+// ComputedAttrsBatcher computes attributes for every guest of a scope. Bookings are grouped by guest with composite
+// aggregations, so each page of guests costs a single search request.
+type ComputedAttrsBatcher struct {
+	client       *elasticsearch.TypedClient
+	index        string
+	scope        MappingKeysScope
+	attrIDs      []string
+	registry     *ComputedAttrRegistry
+	query        *estypes.Query
+	pageSize     int
+	progressFunc func(progress int) error
+}
+
+type ComputedAttrsBatcherOption func(*ComputedAttrsBatcher)
+
+func NewComputedAttrsBatcher(
+	client *elasticsearch.TypedClient,
+	index string,
+	scope MappingKeysScope,
+	attrIDs []string,
+	options ...ComputedAttrsBatcherOption,
+) (*ComputedAttrsBatcher, error) {
+	const defaultPageSize = 500
+
+	if err := scope.validate(); err != nil {
+		return nil, err
+	}
+
+	result := &ComputedAttrsBatcher{
+		client:       client,
+		index:        index,
+		scope:        scope,
+		attrIDs:      attrIDs,
+		registry:     DefaultComputedAttrRegistry,
+		pageSize:     defaultPageSize,
+		progressFunc: func(int) error { return nil },
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	for _, attrID := range attrIDs {
+		if _, ok := result.registry.Config(attrID, result.entityType()); !ok {
+			return nil, &errors.Error{Code: errors.CodeInvalid, Message: "Unknown computed attribute: " + attrID}
+		}
+	}
+
+	return result, nil
+}
+
+// WithComputedAttrsBatcherRegistry sets the registry in which the attributes are looked up. The
+// DefaultComputedAttrRegistry is used by default.
+func WithComputedAttrsBatcherRegistry(registry *ComputedAttrRegistry) ComputedAttrsBatcherOption {
+	return func(b *ComputedAttrsBatcher) { b.registry = registry }
+}
+
+// WithComputedAttrsBatcherQuery filters the bookings to aggregate (e.g. the bookings of a chain).
+func WithComputedAttrsBatcherQuery(query *estypes.Query) ComputedAttrsBatcherOption {
+	return func(b *ComputedAttrsBatcher) { b.query = query }
+}
+
+// WithComputedAttrsBatcherPageSize sets the number of guests aggregated by each search request.
+func WithComputedAttrsBatcherPageSize(size int) ComputedAttrsBatcherOption {
+	return func(b *ComputedAttrsBatcher) { b.pageSize = size }
+}
+
+// WithComputedAttrsBatcherProgressFunc sets the function called with the progress of the batch, between 0 and 100. It
+// can be used to report the progress of a task (e.g. with restasks.Server.SetTaskProgress).
+func WithComputedAttrsBatcherProgressFunc(progressFunc func(progress int) error) ComputedAttrsBatcherOption {
+	return func(b *ComputedAttrsBatcher) { b.progressFunc = progressFunc }
+}
+
+// Run the batch, calling handlePageFunc with the attributes of each page of guests.
+func (b *ComputedAttrsBatcher) Run(
+	ctx context.Context,
+	handlePageFunc func(ctx context.Context, page []*GuestComputedAttributes) error,
+) error {
+	total, err := b.countGuests(ctx)
+	if err != nil {
+		return err
+	}
+
+	pager := pagination.NewPager(
+		pagination.NewESCompositeKeysetPageReader[[]*GuestComputedAttributes](
+			func(
+				size int,
+				lastKey estypes.CompositeAggregateKey,
+				_ *estypes.PointInTimeReference,
+				query *estypes.Query,
+				aggs map[string]estypes.Aggregations,
+			) ([]*GuestComputedAttributes, estypes.CompositeAggregateKey, error) {
+				return b.readPage(ctx, size, lastKey, query, aggs)
+			},
+			pagination.WithESCompositeKeysetPageReaderQuery[estypes.CompositeAggregateKey](b.query),
+			pagination.WithESCompositeKeysetPageReaderAgg[estypes.CompositeAggregateKey](b.makeAggs()),
+			pagination.WithESCompositeKeysetPageReaderSize[estypes.CompositeAggregateKey](b.pageSize),
+		),
+		pagination.WithPageSize(b.pageSize),
+	)
+
+	progress, processed := 0, 0
+
+	for pager.Next() {
+		page := pager.Page()
+		if err := handlePageFunc(ctx, page); err != nil {
+			return err
+		}
+
+		processed += len(page)
+
+		// The total is approximate, so the progress is only complete when the last page has been handled.
+		if newProgress := min(processed*100/max(total, 1), 99); newProgress != progress {
+			progress = newProgress
+			if err := b.progressFunc(progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := pager.Err(); err != nil {
+		return err
+	}
+
+	return b.progressFunc(100)
+}
+
+// countGuests returns the approximate number of guests to process.
+func (b *ComputedAttrsBatcher) countGuests(ctx context.Context) (int, error) {
+	response, err := b.client.Search().Index(b.index).TypedKeys(true).Request(&search.Request{
+		Query: b.query,
+		Size:  pointer.From(0),
+		Aggregations: map[string]estypes.Aggregations{
+			computedAttrsBatchGuestsAgg: {Cardinality: &estypes.CardinalityAggregation{Field: pointer.From(b.guestIDKey())}},
+		},
+	}).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not count guests: %w", err)
+	}
+
+	cardinality, err := DecodeAggregate[*estypes.CardinalityAggregate](response.Aggregations[computedAttrsBatchGuestsAgg])
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cardinality.Value), nil
+}
+
+func (b *ComputedAttrsBatcher) readPage(
+	ctx context.Context,
+	size int,
+	lastKey estypes.CompositeAggregateKey,
+	query *estypes.Query,
+	aggs map[string]estypes.Aggregations,
+) ([]*GuestComputedAttributes, estypes.CompositeAggregateKey, error) {
+	guestsAgg := aggs[computedAttrsBatchGuestsAgg]
+	guestsAgg.Composite = &estypes.CompositeAggregation{
+		After:   lastKey,
+		Size:    pointer.From(size),
+		Sources: guestsAgg.Composite.Sources,
+	}
+
+	response, err := b.client.Search().Index(b.index).TypedKeys(true).Request(&search.Request{
+		Query:        query,
+		Size:         pointer.From(0),
+		Aggregations: map[string]estypes.Aggregations{computedAttrsBatchGuestsAgg: guestsAgg},
+	}).Do(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not aggregate computed attributes: %w", err)
+	}
+
+	composite, err := DecodeAggregate[*estypes.CompositeAggregate](response.Aggregations[computedAttrsBatchGuestsAgg])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buckets, err := decodeBuckets[estypes.CompositeBucket](composite.Buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := []*GuestComputedAttributes{}
+
+	for _, bucket := range buckets {
+		guestAttributes, err := b.mapBucket(bucket)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result = append(result, guestAttributes)
+	}
+
+	return result, composite.AfterKey, nil
+}
+
+func (b *ComputedAttrsBatcher) mapBucket(bucket estypes.CompositeBucket) (*GuestComputedAttributes, error) {
+	guestID, ok := bucket.Key[computedAttrsBatchGuestKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: got guest key of type %T", ErrUnexpectedAggregate, bucket.Key[computedAttrsBatchGuestKey])
+	}
+
+	result := &GuestComputedAttributes{GuestID: guestID, Attributes: &ScopedTypedComputedAttributes{}}
+
+	for _, attrID := range b.attrIDs {
+		config, _ := b.registry.Config(attrID, b.entityType())
+
+		value, err := config.MapValueFunc(bucket.Aggregations[attrID])
+		if err != nil {
+			return nil, fmt.Errorf("could not map computed attribute %q of guest %q: %w", attrID, guestID, err)
+		}
+
+		attribute := ScopedTypedComputedAttribute{ID: attrID, Value: value}
+
+		switch config.AggType {
+		case ComputedAttrAggTypeBoolean:
+			result.Attributes.Boolean = append(result.Attributes.Boolean, attribute)
+		case ComputedAttrAggTypeDate:
+			result.Attributes.Date = append(result.Attributes.Date, attribute)
+		case ComputedAttrAggTypeNumber:
+			result.Attributes.Number = append(result.Attributes.Number, attribute)
+		default:
+			result.Attributes.Text = append(result.Attributes.Text, attribute)
+		}
+	}
+
+	return result, nil
+}
+
+func (b *ComputedAttrsBatcher) makeAggs() map[string]estypes.Aggregations {
+	subAggs := map[string]estypes.Aggregations{}
+	for _, attrID := range b.attrIDs {
+		config, _ := b.registry.Config(attrID, b.entityType())
+		subAggs[attrID] = config.Agg
+	}
+
+	return map[string]estypes.Aggregations{
+		computedAttrsBatchGuestsAgg: {
+			Composite: &estypes.CompositeAggregation{
+				Sources: []map[string]estypes.CompositeAggregationSource{{
+					computedAttrsBatchGuestKey: {Terms: &estypes.CompositeTermsAggregation{
+						Field: pointer.From(b.guestIDKey()),
+					}},
+				}},
+			},
+			Aggregations: subAggs,
+		},
+	}
+}
+
+func (b *ComputedAttrsBatcher) guestIDKey() string { return newScopedGuestMappingKeys(b.scope).ID }
+
+func (b *ComputedAttrsBatcher) entityType() resmodels.EntityType {
+	switch b.scope {
+	case MappingKeysScopeChain:
+		return resmodels.EntityTypeChain
+	case MappingKeysScopeGroup:
+		return resmodels.EntityTypeGroup
+	default:
+		return resmodels.EntityTypeAccount
+	}
+}
@@ -0,0 +1,108 @@
+package esutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputedAttrsBatcherRun(t *testing.T) {
+	afterKeys := []json.RawMessage{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		body := struct {
+			Aggregations struct {
+				Guests struct {
+					Composite *struct {
+						After json.RawMessage `json:"after"`
+					} `json:"composite"`
+				} `json:"guests"`
+			} `json:"aggregations"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		composite := body.Aggregations.Guests.Composite
+		if composite == nil {
+			_, _ = w.Write([]byte(`{"hits": {"hits": []}, "aggregations": {"cardinality#guests": {"value": 3}}}`))
+
+			return
+		}
+
+		afterKeys = append(afterKeys, composite.After)
+
+		switch string(composite.After) {
+		case "":
+			_, _ = w.Write([]byte(`{"hits": {"hits": []}, "aggregations": {"composite#guests": {
+				"after_key": {"guestId": "b"},
+				"buckets": [
+					{"key": {"guestId": "a"}, "doc_count": 2, "sum#totalNights": {"value": 5}, "max#previousBookingDeparture": {"value": 1700000000000}},
+					{"key": {"guestId": "b"}, "doc_count": 1, "sum#totalNights": {"value": 1}, "max#previousBookingDeparture": {"value": null}}
+				]
+			}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"hits": {"hits": []}, "aggregations": {"composite#guests": {
+				"buckets": [
+					{"key": {"guestId": "c"}, "doc_count": 1, "sum#totalNights": {"value": 3}, "max#previousBookingDeparture": {"value": 1600000000000}}
+				]
+			}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	require.NoError(t, err)
+
+	progresses := []int{}
+
+	batcher, err := NewComputedAttrsBatcher(
+		client,
+		"guestbookings",
+		MappingKeysScopeChain,
+		[]string{"totalNights", "previousBookingDeparture"},
+		WithComputedAttrsBatcherPageSize(2),
+		WithComputedAttrsBatcherProgressFunc(func(progress int) error {
+			progresses = append(progresses, progress)
+
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	got := []*GuestComputedAttributes{}
+	require.NoError(t, batcher.Run(context.Background(), func(_ context.Context, page []*GuestComputedAttributes) error {
+		got = append(got, page...)
+
+		return nil
+	}))
+
+	assert.Equal(t, []*GuestComputedAttributes{
+		{GuestID: "a", Attributes: &ScopedTypedComputedAttributes{
+			Number: []ScopedTypedComputedAttribute{{ID: "totalNights", Value: float64(5)}},
+			Date:   []ScopedTypedComputedAttribute{{ID: "previousBookingDeparture", Value: float64(1700000000000)}},
+		}},
+		{GuestID: "b", Attributes: &ScopedTypedComputedAttributes{
+			Number: []ScopedTypedComputedAttribute{{ID: "totalNights", Value: float64(1)}},
+			Date:   []ScopedTypedComputedAttribute{{ID: "previousBookingDeparture", Value: float64(0)}},
+		}},
+		{GuestID: "c", Attributes: &ScopedTypedComputedAttributes{
+			Number: []ScopedTypedComputedAttribute{{ID: "totalNights", Value: float64(3)}},
+			Date:   []ScopedTypedComputedAttribute{{ID: "previousBookingDeparture", Value: float64(1600000000000)}},
+		}},
+	}, got)
+	assert.Equal(t, []json.RawMessage{nil, json.RawMessage(`{"guestId":"b"}`)}, afterKeys)
+	assert.Equal(t, []int{66, 99, 100}, progresses)
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		_, err := NewComputedAttrsBatcher(client, "guestbookings", MappingKeysScopeChain, []string{"unknown"})
+		assert.Error(t, err)
+	})
+}
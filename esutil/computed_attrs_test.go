@@ -0,0 +1,82 @@
+package esutil_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/esutil"
+	"github.com/loungeup/go-loungeup/pointer"
+	"github.com/loungeup/go-loungeup/resmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputedAttrRegistry(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		registry := esutil.NewComputedAttrRegistry()
+
+		attr := &esutil.ComputedAttr{
+			AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+				return estypes.Aggregations{Max: &estypes.MaxAggregation{Field: pointer.From("booking.fare")}}
+			},
+			AggType: esutil.ComputedAttrAggTypeNumber,
+			MapValueFunc: func(estypes.Aggregate) (any, error) {
+				return 1, nil
+			},
+		}
+
+		require.NoError(t, registry.Register("maxRevenue", attr))
+		assert.ErrorIs(t, registry.Register("maxRevenue", attr), esutil.ErrDuplicateComputedAttr)
+		assert.Error(t, registry.Register("invalid", &esutil.ComputedAttr{}))
+		assert.Equal(t, []string{"maxRevenue"}, registry.Names())
+
+		config, ok := registry.Config("maxRevenue", resmodels.EntityTypeAccount)
+		require.True(t, ok)
+		assert.Equal(t, esutil.ComputedAttrAggTypeNumber, config.AggType)
+		assert.Equal(t, "booking.fare", *config.Agg.Max.Field)
+
+		_, ok = registry.Config("unknown", resmodels.EntityTypeAccount)
+		assert.False(t, ok)
+	})
+
+	t.Run("per-call copies", func(t *testing.T) {
+		chainConfig := esutil.GetComputedAttrAggConfig("mostRelevantBookingId", resmodels.EntityTypeChain)
+		accountConfig := esutil.GetComputedAttrAggConfig("mostRelevantBookingId", resmodels.EntityTypeAccount)
+
+		assert.Equal(t, json.RawMessage(`"chain"`), chainConfig.Agg.ScriptedMetric.Params["entityType"])
+		assert.Equal(t, json.RawMessage(`"account"`), accountConfig.Agg.ScriptedMetric.Params["entityType"])
+
+		accountConfig.Agg.ScriptedMetric.Params["entityType"] = json.RawMessage(`"group"`)
+		assert.Equal(t, json.RawMessage(`"chain"`), chainConfig.Agg.ScriptedMetric.Params["entityType"])
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		wg := sync.WaitGroup{}
+
+		for _, entityType := range []resmodels.EntityType{
+			resmodels.EntityTypeAccount, resmodels.EntityTypeChain, resmodels.EntityTypeGroup,
+		} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for range 100 {
+					config := esutil.GetComputedAttrAggConfig("mostRelevantBookingId", entityType)
+					assert.Equal(t,
+						json.RawMessage(`"`+entityType+`"`),
+						config.Agg.ScriptedMetric.Params["entityType"],
+					)
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		assert.Nil(t, esutil.GetComputedAttrAggConfig("unknown", resmodels.EntityTypeAccount).MapValueFunc)
+	})
+}
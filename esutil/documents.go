@@ -12,28 +12,28 @@ type GuestBookingDocument struct {
 
 type ScopedGuestBookingDocument struct {
 	Booking                 Booking                        `json:"booking"`
-	CampaignStats           json.RawMessage                `json:"campaignStats,omitempty"`
-	Device                  json.RawMessage                `json:"device,omitempty"`
+	CampaignStats           json.RawMessage                `json:"campaignStats,omitempty" es:"dynamic"`
+	Device                  json.RawMessage                `json:"device,omitempty" es:"dynamic"`
 	Guest                   *ScopedGuest                   `json:"guest,omitempty"`
-	SurveyAnswers           json.RawMessage                `json:"surveyAnswers,omitempty"`
+	SurveyAnswers           json.RawMessage                `json:"surveyAnswers,omitempty" es:"dynamic"`
 	TypedComputedAttributes *ScopedTypedComputedAttributes `json:"typedComputedAttributes,omitempty"`
 	Aggregations            *ScopedAggregations            `json:"aggregations,omitempty"`
 }
 
 type GuestCardDocument struct {
-	CampaignStats           json.RawMessage          `json:"campaignStats,omitempty"`
-	Device                  json.RawMessage          `json:"device,omitempty"`
+	CampaignStats           json.RawMessage          `json:"campaignStats,omitempty" es:"dynamic"`
+	Device                  json.RawMessage          `json:"device,omitempty" es:"dynamic"`
 	Guest                   Guest                    `json:"guest"`
-	SurveyAnswers           json.RawMessage          `json:"surveyAnswers,omitempty"`
+	SurveyAnswers           json.RawMessage          `json:"surveyAnswers,omitempty" es:"dynamic"`
 	TypedComputedAttributes *TypedComputedAttributes `json:"typedComputedAttributes,omitempty"`
 	Aggregations            Aggregations             `json:"aggregations,omitempty"`
 }
 
 type ScopedGuestCardDocument struct {
-	CampaignStats           json.RawMessage                `json:"campaignStats,omitempty"`
-	Device                  json.RawMessage                `json:"device,omitempty"`
+	CampaignStats           json.RawMessage                `json:"campaignStats,omitempty" es:"dynamic"`
+	Device                  json.RawMessage                `json:"device,omitempty" es:"dynamic"`
 	Guest                   *ScopedGuest                   `json:"guest,omitempty"`
-	SurveyAnswers           json.RawMessage                `json:"surveyAnswers,omitempty"`
+	SurveyAnswers           json.RawMessage                `json:"surveyAnswers,omitempty" es:"dynamic"`
 	TypedComputedAttributes *ScopedTypedComputedAttributes `json:"typedComputedAttributes,omitempty"`
 	Aggregations            *ScopedAggregations            `json:"aggregations,omitempty"`
 }
@@ -53,13 +53,13 @@ type ScopedTypedComputedAttributes struct {
 
 type ScopedTypedComputedAttribute struct {
 	ID        string `json:"id,omitempty"`
-	Value     any    `json:"value,omitempty"`
+	Value     any    `json:"value,omitempty" es:"dynamic"`
 	AccountID string `json:"accountId,omitempty"`
 }
 
 type ScopedAggregations struct {
 	CounterFutureBookings float64                     `json:"counterFutureBookings"`
-	LastDeparture         string                      `json:"lastDeparture,omitempty"`
+	LastDeparture         string                      `json:"lastDeparture,omitempty" es:"date"`
 	CounterBookings       float64                     `json:"counterBookings"`
 	EntityID              string                      `json:"entityId,omitempty"`
 	ConvertedAvgFare      *BookingConvertedCurrencies `json:"convertedAvgFare,omitempty"`
@@ -67,13 +67,13 @@ type ScopedAggregations struct {
 	AvgFares              float64                     `json:"avgFares"`
 	SumFares              float64                     `json:"sumFares"`
 	ConvertedSumFare      *BookingConvertedCurrencies `json:"convertedSumFare,omitempty"`
-	NextArrival           string                      `json:"nextArrival,omitempty"`
+	NextArrival           string                      `json:"nextArrival,omitempty" es:"date"`
 	AccountIDs            Array[string]               `json:"accountIds,omitempty"`
 	AccountIDsCounter     float64                     `json:"accountIdsCounter"`
 	LastAccountID         string                      `json:"lastAccountId,omitempty"`
 	ID                    string                      `json:"id,omitempty"`
 	NextAccountID         string                      `json:"nextAccountId,omitempty"`
-	UpdatedAt             string                      `json:"updatedAt,omitempty"`
+	UpdatedAt             string                      `json:"updatedAt,omitempty" es:"date"`
 }
 
 type Aggregations struct {
@@ -83,12 +83,12 @@ type Aggregations struct {
 }
 
 type Booking struct {
-	AggregateAt           string                      `json:"aggregateAt,omitempty"`
-	Arrival               string                      `json:"arrival,omitempty"`
+	AggregateAt           string                      `json:"aggregateAt,omitempty" es:"date"`
+	Arrival               string                      `json:"arrival,omitempty" es:"date"`
 	ArrivalDay            string                      `json:"arrivalDay,omitempty"`
 	ArrivalDow            string                      `json:"arrivalDow,omitempty"`
-	Balance               any                         `json:"balance,omitempty"`
-	BookingDate           string                      `json:"bookingDate,omitempty"`
+	Balance               any                         `json:"balance,omitempty" es:"double"`
+	BookingDate           string                      `json:"bookingDate,omitempty" es:"date"`
 	BookingDateArrival    string                      `json:"bookingDateArrival,omitempty"`
 	Channel               string                      `json:"channel,omitempty"`
 	Cico                  *BookingCico                `json:"cico,omitempty"`
@@ -99,25 +99,25 @@ type Booking struct {
 	ConvertedTouristTax   *BookingConvertedCurrencies `json:"convertedTouristTax,omitempty"`
 	CustomFields          *CustomFields               `json:"customFields,omitempty"`
 	Data                  *BookingData                `json:"data,omitempty"`
-	Departure             string                      `json:"departure,omitempty"`
+	Departure             string                      `json:"departure,omitempty" es:"date"`
 	DepartureDay          string                      `json:"departureDay,omitempty"`
 	DepartureDow          string                      `json:"departureDow,omitempty"`
 	EntityID              string                      `json:"entityId,omitempty"`
 	ExternalIDs           *BookingExternalIDs         `json:"externalIds,omitempty"`
-	Fare                  any                         `json:"fare,omitempty"`
+	Fare                  any                         `json:"fare,omitempty" es:"double"`
 	FareCode              string                      `json:"fareCode,omitempty"`
 	FarePerNight          float64                     `json:"farePerNight,omitempty"`
 	FilledCustomFields    Array[string]               `json:"filledCustomFields,omitempty"`
 	GuestID               string                      `json:"guestId,omitempty"`
 	ID                    int                         `json:"id,omitempty"`
-	InstayDates           Array[string]               `json:"instayDates,omitempty"`
+	InstayDates           Array[string]               `json:"instayDates,omitempty" es:"date"`
 	InstayDows            Array[string]               `json:"instayDows,omitempty"`
 	Last                  string                      `json:"last,omitempty"`
 	Partner               string                      `json:"partner,omitempty"`
 	Pass                  string                      `json:"pass,omitempty"`
-	PaxAdults             any                         `json:"paxAdults,omitempty"`
-	PaxBabies             any                         `json:"paxBabies,omitempty"`
-	PaxChildren           any                         `json:"paxChildren,omitempty"`
+	PaxAdults             any                         `json:"paxAdults,omitempty" es:"integer"`
+	PaxBabies             any                         `json:"paxBabies,omitempty" es:"integer"`
+	PaxChildren           any                         `json:"paxChildren,omitempty" es:"integer"`
 	PMSBookingID          string                      `json:"pmsBookingId,omitempty"`
 	PMSBookingParentID    string                      `json:"pmsBookingParentId,omitempty"`
 	Purposes              Array[string]               `json:"purposes,omitempty"`
@@ -127,8 +127,8 @@ type Booking struct {
 	Status                string                      `json:"status,omitempty"`
 	StayLength            int                         `json:"stayLength,omitempty"`
 	Tags                  Array[string]               `json:"tags,omitempty"`
-	TouristTax            any                         `json:"touristTax,omitempty"`
-	UpdatedAt             string                      `json:"updatedAt,omitempty"`
+	TouristTax            any                         `json:"touristTax,omitempty" es:"double"`
+	UpdatedAt             string                      `json:"updatedAt,omitempty" es:"date"`
 	Weekend               bool                        `json:"weekend,omitempty"`
 }
 
@@ -157,9 +157,9 @@ func (m *BookingConvertedCurrencies) UnmarshalJSON(data []byte) error {
 }
 
 type BookingExternalIDs struct {
-	ExternalID       any `json:"externalid,omitempty"`
-	MonewebAccountID any `json:"moneweb_account_id,omitempty"`
-	Qualitelis       any `json:"qualitelis,omitempty"`
+	ExternalID       any `json:"externalid,omitempty" es:"keyword"`
+	MonewebAccountID any `json:"moneweb_account_id,omitempty" es:"keyword"`
+	Qualitelis       any `json:"qualitelis,omitempty" es:"keyword"`
 }
 
 type BookingCico struct {
@@ -173,13 +173,13 @@ type BookingData struct {
 	ArrivalTime     string `json:"arrivalTime,omitempty"`
 	Converted       string `json:"converted,omitempty"`
 	Index           string `json:"index,omitempty"`
-	NextStay        string `json:"nextStay,omitempty"`
-	PmsCreatedAt    string `json:"pmsCreatedAt,omitempty"`
-	PmsImportAt     string `json:"pmsImportAt,omitempty"`
-	PrevStay        string `json:"prevStay,omitempty"`
+	NextStay        string `json:"nextStay,omitempty" es:"date"`
+	PmsCreatedAt    string `json:"pmsCreatedAt,omitempty" es:"date"`
+	PmsImportAt     string `json:"pmsImportAt,omitempty" es:"date"`
+	PrevStay        string `json:"prevStay,omitempty" es:"date"`
 	PreviousStatus  string `json:"previousStatus,omitempty"`
 	ReindexGuest    string `json:"reindexGuest,omitempty"`
-	StatusUpdatedAt string `json:"statusUpdatedAt,omitempty"`
+	StatusUpdatedAt string `json:"statusUpdatedAt,omitempty" es:"date"`
 }
 
 type BookingConvertedCurrencies struct {
@@ -200,8 +200,8 @@ type BookingCustomFields struct{}
 type Guest struct {
 	ID        string `json:"id,omitempty"`
 	EntityID  string `json:"entityId,omitempty"`
-	CreatedAt string `json:"createdAt,omitempty"`
-	IndexedAt string `json:"indexedAt,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty" es:"date"`
+	IndexedAt string `json:"indexedAt,omitempty" es:"date"`
 
 	Account *ScopedGuest `json:"account,omitempty"`
 	Chain   *ScopedGuest `json:"chain,omitempty"`
@@ -211,21 +211,21 @@ type Guest struct {
 // ScopedGuest represents a guest in an Guest format. It is used to add the
 // representations based on the entities of the guest.
 type ScopedGuest struct {
-	AnonymizedAt        string                  `json:"anonymizedAt,omitempty"`
+	AnonymizedAt        string                  `json:"anonymizedAt,omitempty" es:"date"`
 	Anonymous           bool                    `json:"anonymous"`
-	Birthdate           string                  `json:"birthdate,omitempty"`
+	Birthdate           string                  `json:"birthdate,omitempty" es:"date"`
 	Birthplace          *GuestAddress           `json:"birthplace,omitempty"`
 	City                Array[string]           `json:"city,omitempty"`
 	Company             string                  `json:"company,omitempty"`
 	ComposedWith        Array[string]           `json:"composedWith,omitempty"`
 	ComputeAggregations bool                    `json:"computeAggregations,omitempty"`
 	Country             Array[string]           `json:"country,omitempty"`
-	CreatedAt           string                  `json:"createdAt,omitempty"`
+	CreatedAt           string                  `json:"createdAt,omitempty" es:"date"`
 	CustomFields        *CustomFields           `json:"customFields,omitempty"`
 	Documents           *GuestDocuments         `json:"documents,omitempty"`
 	EmailDomains        Array[string]           `json:"emailDomains,omitempty"`
 	Emails              Array[string]           `json:"emails,omitempty"`
-	EmailsMergeableAt   string                  `json:"emailsMergeableAt,omitempty"`
+	EmailsMergeableAt   string                  `json:"emailsMergeableAt,omitempty" es:"date"`
 	EntityID            string                  `json:"entityId,omitempty"`
 	FilledCustomFields  Array[string]           `json:"filledCustomFields,omitempty"`
 	Firstname           string                  `json:"firstname,omitempty"`
@@ -243,7 +243,7 @@ type ScopedGuest struct {
 	Notes               Array[string]           `json:"notes,omitempty"`
 	OptedOut            *GuestOptedOut          `json:"optedOut,omitempty"`
 	Phones              Array[string]           `json:"phones,omitempty"`
-	PhonesMergeableAt   string                  `json:"phonesMergeableAt,omitempty"`
+	PhonesMergeableAt   string                  `json:"phonesMergeableAt,omitempty" es:"date"`
 	PMSID               Array[string]           `json:"pmsId,omitempty"`
 	PreferredContacts   *GuestPreferredContacts `json:"preferredContacts,omitempty"`
 	Socials             *GuestSocials           `json:"socials,omitempty"`
@@ -255,7 +255,7 @@ type ScopedGuest struct {
 	Timezone            Array[string]           `json:"timezone,omitempty"`
 	Title               string                  `json:"title,omitempty"`
 	TrustableContacts   *GuestTrustableContacts `json:"trustableContacts,omitempty"`
-	UpdatedAt           string                  `json:"updatedAt,omitempty"`
+	UpdatedAt           string                  `json:"updatedAt,omitempty" es:"date"`
 	ZipCode             Array[string]           `json:"zipcode,omitempty"`
 }
 
@@ -279,7 +279,7 @@ type CustomFields struct {
 
 type GuestCustomField struct {
 	Key   string `json:"key,omitempty"`
-	Value any    `json:"value,omitempty"`
+	Value any    `json:"value,omitempty" es:"dynamic"`
 }
 
 type GuestDocuments struct {
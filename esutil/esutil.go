@@ -2,6 +2,7 @@ package esutil
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
@@ -12,232 +13,242 @@ import (
 	"github.com/loungeup/go-loungeup/resmodels"
 )
 
-//nolint:forcetypeassert
-var computedAttrAggConfigs = map[string]ComputedAttrAggConfig{
+var defaultComputedAttrs = map[string]*ComputedAttr{
 	"accountIds": {
-		Agg: estypes.Aggregations{
-			Terms: &estypes.TermsAggregation{
-				Field: pointer.From("booking.entityId"),
-				Size:  pointer.From(20), //nolint:mnd
-			},
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Terms: &estypes.TermsAggregation{
+					Field: pointer.From("booking.entityId"),
+					Size:  pointer.From(20), //nolint:mnd
+				},
+			}
 		},
 		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			buckets := aggregate.(*estypes.StringTermsAggregate).Buckets.([]estypes.StringTermsBucket)
-			if len(buckets) == 0 {
-				return nil
+		MapValueFunc: func(aggregate estypes.Aggregate) (any, error) {
+			buckets, err := DecodeTermsBuckets(aggregate)
+			if err != nil {
+				return nil, err
 			}
 
-			entityIds := uuid.UUIDs{}
+			entityIDs := uuid.UUIDs{}
+
 			for _, bucket := range buckets {
 				key, ok := bucket.Key.(string)
 				if !ok {
 					continue
 				}
 
-				entityId, _ := uuid.Parse(key)
+				entityID, err := uuid.Parse(key)
+				if err != nil {
+					return nil, fmt.Errorf("could not parse entity ID: %w", err)
+				}
 
-				entityIds = append(entityIds, entityId)
+				entityIDs = append(entityIDs, entityID)
 			}
 
-			if len(entityIds) == 0 {
-				return nil
+			if len(entityIDs) == 0 {
+				return nil, nil
 			}
 
-			return entityIds
+			return entityIDs, nil
 		},
 	},
 	"averageRevenue": {
-		Agg: estypes.Aggregations{
-			Avg: &estypes.AverageAggregation{
-				Field: pointer.From("booking.fare"),
-			},
-		},
-		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.AvgAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Avg: &estypes.AverageAggregation{
+					Field: pointer.From("booking.fare"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeNumber,
+		MapValueFunc: mapMetricValue,
 	},
 	"mostRelevantBookingId": {
-		Agg: estypes.Aggregations{
-			ScriptedMetric: &estypes.ScriptedMetricAggregation{
-				InitScript: &estypes.Script{
-					Id: pointer.From("compute-guest-current-booking-init"),
-				},
-				MapScript: &estypes.Script{
-					Id: pointer.From("compute-guest-current-booking-map"),
-				},
-				CombineScript: &estypes.Script{
-					Id: pointer.From("compute-guest-current-booking-combine"),
-				},
-				ReduceScript: &estypes.Script{
-					Id: pointer.From("compute-guest-current-booking-reduce"),
-				},
-				Params: map[string]json.RawMessage{
-					"entityType": nil, // This is a placeholder for the actual value.
+		AggFunc: func(entityType resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				ScriptedMetric: &estypes.ScriptedMetricAggregation{
+					InitScript: &estypes.Script{
+						Id: pointer.From("compute-guest-current-booking-init"),
+					},
+					MapScript: &estypes.Script{
+						Id: pointer.From("compute-guest-current-booking-map"),
+					},
+					CombineScript: &estypes.Script{
+						Id: pointer.From("compute-guest-current-booking-combine"),
+					},
+					ReduceScript: &estypes.Script{
+						Id: pointer.From("compute-guest-current-booking-reduce"),
+					},
+					Params: map[string]json.RawMessage{
+						"entityType": json.RawMessage(`"` + entityType + `"`),
+					},
 				},
-			},
+			}
 		},
 		AggType: ComputedAttrAggTypeText,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return jsonutil.Document(aggregate.(*estypes.ScriptedMetricAggregate).Value).Child("id")
+		MapValueFunc: func(aggregate estypes.Aggregate) (any, error) {
+			value, err := DecodeScriptedMetric[json.RawMessage](aggregate)
+			if err != nil {
+				return nil, err
+			}
+
+			return jsonutil.Document(value).Child("id"), nil
 		},
 	},
 	"nextAccountId": {
-		Agg: estypes.Aggregations{
-			TopHits: &estypes.TopHitsAggregation{
-				Size: pointer.From(1),
-				Sort: []estypes.SortCombinations{
-					estypes.SortOptions{
-						SortOptions: map[string]estypes.FieldSort{
-							"booking.arrival": {
-								Order: &essortorder.Asc,
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				TopHits: &estypes.TopHitsAggregation{
+					Size: pointer.From(1),
+					Sort: []estypes.SortCombinations{
+						estypes.SortOptions{
+							SortOptions: map[string]estypes.FieldSort{
+								"booking.arrival": {
+									Order: &essortorder.Asc,
+								},
 							},
 						},
 					},
+					Source_: estypes.SourceFilter{
+						Includes: []string{"booking.entityId"},
+					},
 				},
-				Source_: estypes.SourceFilter{
-					Includes: []string{"booking.entityId"},
-				},
-			},
-		},
-		AggType: ComputedAttrAggTypeText,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			hits := aggregate.(*estypes.TopHitsAggregate).Hits.Hits
-			if len(hits) == 0 {
-				return nil
 			}
-
-			return jsonutil.Document(hits[0].Source_).UUID("booking.entityId")
 		},
+		AggType:      ComputedAttrAggTypeText,
+		MapValueFunc: mapFirstBookingEntityID,
 	},
 	"nextBookingArrival": {
-		Agg: estypes.Aggregations{
-			Min: &estypes.MinAggregation{
-				Field: pointer.From("booking.arrival"),
-			},
-		},
-		AggType: ComputedAttrAggTypeDate,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.MinAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Min: &estypes.MinAggregation{
+					Field: pointer.From("booking.arrival"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeDate,
+		MapValueFunc: mapMetricValue,
 	},
 	"previousAccountId": {
-		Agg: estypes.Aggregations{
-			TopHits: &estypes.TopHitsAggregation{
-				Size: pointer.From(1),
-				Sort: []estypes.SortCombinations{
-					estypes.SortOptions{
-						SortOptions: map[string]estypes.FieldSort{
-							"booking.departure": {
-								Order: &essortorder.Desc,
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				TopHits: &estypes.TopHitsAggregation{
+					Size: pointer.From(1),
+					Sort: []estypes.SortCombinations{
+						estypes.SortOptions{
+							SortOptions: map[string]estypes.FieldSort{
+								"booking.departure": {
+									Order: &essortorder.Desc,
+								},
 							},
 						},
 					},
+					Source_: estypes.SourceFilter{
+						Includes: []string{"booking.entityId"},
+					},
 				},
-				Source_: estypes.SourceFilter{
-					Includes: []string{"booking.entityId"},
-				},
-			},
-		},
-		AggType: ComputedAttrAggTypeText,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			hits := aggregate.(*estypes.TopHitsAggregate).Hits.Hits
-			if len(hits) == 0 {
-				return nil
 			}
-
-			return jsonutil.Document(hits[0].Source_).UUID("booking.entityId")
 		},
+		AggType:      ComputedAttrAggTypeText,
+		MapValueFunc: mapFirstBookingEntityID,
 	},
 	"previousBookingDeparture": {
-		Agg: estypes.Aggregations{
-			Max: &estypes.MaxAggregation{
-				Field: pointer.From("booking.departure"),
-			},
-		},
-		AggType: ComputedAttrAggTypeDate,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.MaxAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Max: &estypes.MaxAggregation{
+					Field: pointer.From("booking.departure"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeDate,
+		MapValueFunc: mapMetricValue,
 	},
 	"totalAccounts": {
-		Agg: estypes.Aggregations{
-			Terms: &estypes.TermsAggregation{
-				Field: pointer.From("booking.entityId"),
-			},
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Terms: &estypes.TermsAggregation{
+					Field: pointer.From("booking.entityId"),
+				},
+			}
 		},
 		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			buckets := aggregate.(*estypes.StringTermsAggregate).Buckets.([]estypes.StringTermsBucket)
+		MapValueFunc: func(aggregate estypes.Aggregate) (any, error) {
+			buckets, err := DecodeTermsBuckets(aggregate)
+			if err != nil {
+				return nil, err
+			}
 
-			return len(buckets)
+			return len(buckets), nil
 		},
 	},
 	"totalBookings": {
-		Agg: estypes.Aggregations{
-			ValueCount: &estypes.ValueCountAggregation{
-				Field: pointer.From("booking.id"),
-			},
-		},
-		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.ValueCountAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				ValueCount: &estypes.ValueCountAggregation{
+					Field: pointer.From("booking.id"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeNumber,
+		MapValueFunc: mapMetricValue,
 	},
 	"totalDistinctBookings": {
-		Agg: estypes.Aggregations{
-			Cardinality: &estypes.CardinalityAggregation{
-				Script: &estypes.Script{
-					Source: pointer.From("doc['booking.arrival'].value + '_' + doc['booking.departure'].value"),
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Cardinality: &estypes.CardinalityAggregation{
+					Script: &estypes.Script{
+						Source: pointer.From("doc['booking.arrival'].value + '_' + doc['booking.departure'].value"),
+					},
 				},
-			},
+			}
 		},
 		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return aggregate.(*estypes.CardinalityAggregate).Value
+		MapValueFunc: func(aggregate estypes.Aggregate) (any, error) {
+			cardinality, err := DecodeAggregate[*estypes.CardinalityAggregate](aggregate)
+			if err != nil {
+				return nil, err
+			}
+
+			return cardinality.Value, nil
 		},
 	},
 	"totalNights": {
-		Agg: estypes.Aggregations{
-			Sum: &estypes.SumAggregation{
-				Field: pointer.From("booking.stayLength"),
-			},
-		},
-		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.SumAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Sum: &estypes.SumAggregation{
+					Field: pointer.From("booking.stayLength"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeNumber,
+		MapValueFunc: mapMetricValue,
 	},
 	"totalRevenue": {
-		Agg: estypes.Aggregations{
-			Sum: &estypes.SumAggregation{
-				Field: pointer.From("booking.fare"),
-			},
-		},
-		AggType: ComputedAttrAggTypeNumber,
-		MapValueFunc: func(aggregate estypes.Aggregate) any {
-			return mapESFloat64(aggregate.(*estypes.SumAggregate).Value)
+		AggFunc: func(resmodels.EntityType) estypes.Aggregations {
+			return estypes.Aggregations{
+				Sum: &estypes.SumAggregation{
+					Field: pointer.From("booking.fare"),
+				},
+			}
 		},
+		AggType:      ComputedAttrAggTypeNumber,
+		MapValueFunc: mapMetricValue,
 	},
 }
 
+// GetComputedAttrAggConfig returns the config of a computed attribute registered in the DefaultComputedAttrRegistry, or
+// an empty config if the attribute is unknown.
 func GetComputedAttrAggConfig[T ~string](v T, entityType resmodels.EntityType) ComputedAttrAggConfig {
-	if result, ok := computedAttrAggConfigs[string(v)]; ok {
-		if string(v) == "mostRelevantBookingId" {
-			result.Agg.ScriptedMetric.Params["entityType"] = json.RawMessage(`"` + entityType + `"`)
-		}
-
-		return result
-	}
+	result, _ := DefaultComputedAttrRegistry.Config(string(v), entityType)
 
-	return ComputedAttrAggConfig{}
+	return result
 }
 
 type ComputedAttrAggConfig struct {
 	Agg          estypes.Aggregations
 	AggType      ComputedAttrAggType
-	MapValueFunc func(aggregate estypes.Aggregate) any
+	MapValueFunc func(aggregate estypes.Aggregate) (any, error)
 }
 
 type ComputedAttrAggType string
@@ -265,10 +276,30 @@ func MapEntityType[T ~string](v T) string {
 	}
 }
 
-func mapESFloat64(value *estypes.Float64) float64 {
+// mapMetricValue returns the value of a single-value metric aggregate, or 0 when no document was aggregated.
+func mapMetricValue(aggregate estypes.Aggregate) (any, error) {
+	value, err := DecodeMetricValue(aggregate)
+	if err != nil {
+		return nil, err
+	}
+
 	if value == nil {
-		return 0
+		return float64(0), nil
+	}
+
+	return *value, nil
+}
+
+// mapFirstBookingEntityID returns the entity ID of the booking of the first hit of a top hits aggregate.
+func mapFirstBookingEntityID(aggregate estypes.Aggregate) (any, error) {
+	sources, err := DecodeTopHitsSources[json.RawMessage](aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources) == 0 {
+		return nil, nil
 	}
 
-	return float64(*value)
+	return jsonutil.Document(sources[0]).UUID("booking.entityId"), nil
 }
@@ -0,0 +1,152 @@
+package esutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/esutil"
+	"github.com/loungeup/go-loungeup/jsonutil"
+	"github.com/loungeup/go-loungeup/pointer"
+	"github.com/loungeup/go-loungeup/resmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputedAttrAggConfigMapValueFunc(t *testing.T) {
+	accountID := uuid.MustParse("7b5d9c4e-1f2a-4b3c-8d9e-0a1b2c3d4e5f")
+
+	tests := map[string][]struct {
+		name      string
+		aggregate estypes.Aggregate
+		want      any
+		wantErr   bool
+	}{
+		"accountIds": {
+			{
+				name: "buckets",
+				aggregate: &estypes.StringTermsAggregate{Buckets: []estypes.StringTermsBucket{
+					{Key: accountID.String(), DocCount: 2},
+				}},
+				want: uuid.UUIDs{accountID},
+			},
+			{
+				name:      "keyed buckets",
+				aggregate: &estypes.StringTermsAggregate{Buckets: map[string]estypes.StringTermsBucket{"a": {Key: accountID.String()}}},
+				want:      uuid.UUIDs{accountID},
+			},
+			{name: "no buckets", aggregate: &estypes.StringTermsAggregate{Buckets: []estypes.StringTermsBucket{}}},
+			{name: "unmapped", aggregate: &estypes.UnmappedTermsAggregate{}},
+			{
+				name:      "invalid key",
+				aggregate: &estypes.StringTermsAggregate{Buckets: []estypes.StringTermsBucket{{Key: "foo"}}},
+				wantErr:   true,
+			},
+			{name: "unexpected aggregate", aggregate: &estypes.AvgAggregate{}, wantErr: true},
+		},
+		"averageRevenue": {
+			{name: "value", aggregate: &estypes.AvgAggregate{Value: pointer.From(estypes.Float64(12.5))}, want: 12.5},
+			{name: "no value", aggregate: &estypes.AvgAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.StatsAggregate{}, wantErr: true},
+		},
+		"mostRelevantBookingId": {
+			{
+				name:      "value",
+				aggregate: &estypes.ScriptedMetricAggregate{Value: json.RawMessage(`{"id":"42"}`)},
+				want:      jsonutil.Document("42"),
+			},
+			{name: "no value", aggregate: &estypes.ScriptedMetricAggregate{}, want: jsonutil.Document("")},
+			{name: "unexpected aggregate", aggregate: &estypes.TopHitsAggregate{}, wantErr: true},
+		},
+		"nextAccountId": {
+			{
+				name: "hits",
+				aggregate: &estypes.TopHitsAggregate{Hits: estypes.HitsMetadata{Hits: []estypes.Hit{
+					{Source_: json.RawMessage(`{"booking":{"entityId":"` + accountID.String() + `"}}`)},
+				}}},
+				want: accountID,
+			},
+			{name: "no hits", aggregate: &estypes.TopHitsAggregate{}},
+			{
+				name: "invalid source",
+				aggregate: &estypes.TopHitsAggregate{Hits: estypes.HitsMetadata{Hits: []estypes.Hit{
+					{Source_: json.RawMessage(`{`)},
+				}}},
+				wantErr: true,
+			},
+			{name: "unexpected aggregate", aggregate: &estypes.ScriptedMetricAggregate{}, wantErr: true},
+		},
+		"nextBookingArrival": {
+			{name: "value", aggregate: &estypes.MinAggregate{Value: pointer.From(estypes.Float64(1700000000000))}, want: 1.7e12},
+			{name: "no value", aggregate: &estypes.MinAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.StringTermsAggregate{}, wantErr: true},
+		},
+		"previousAccountId": {
+			{
+				name: "hits",
+				aggregate: &estypes.TopHitsAggregate{Hits: estypes.HitsMetadata{Hits: []estypes.Hit{
+					{Source_: json.RawMessage(`{"booking":{"entityId":"` + accountID.String() + `"}}`)},
+				}}},
+				want: accountID,
+			},
+			{name: "no hits", aggregate: &estypes.TopHitsAggregate{}},
+			{name: "unexpected aggregate", aggregate: &estypes.MaxAggregate{}, wantErr: true},
+		},
+		"previousBookingDeparture": {
+			{name: "value", aggregate: &estypes.MaxAggregate{Value: pointer.From(estypes.Float64(1700000000000))}, want: 1.7e12},
+			{name: "no value", aggregate: &estypes.MaxAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.TopHitsAggregate{}, wantErr: true},
+		},
+		"totalAccounts": {
+			{
+				name: "buckets",
+				aggregate: &estypes.StringTermsAggregate{Buckets: []estypes.StringTermsBucket{
+					{Key: "a", DocCount: 1},
+					{Key: "b", DocCount: 3},
+				}},
+				want: 2,
+			},
+			{name: "no buckets", aggregate: &estypes.StringTermsAggregate{}, want: 0},
+			{name: "unexpected buckets", aggregate: &estypes.StringTermsAggregate{Buckets: "foo"}, wantErr: true},
+			{name: "unexpected aggregate", aggregate: &estypes.SumAggregate{}, wantErr: true},
+		},
+		"totalBookings": {
+			{name: "value", aggregate: &estypes.ValueCountAggregate{Value: pointer.From(estypes.Float64(3))}, want: float64(3)},
+			{name: "no value", aggregate: &estypes.ValueCountAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.LongTermsAggregate{}, wantErr: true},
+		},
+		"totalDistinctBookings": {
+			{name: "value", aggregate: &estypes.CardinalityAggregate{Value: 4}, want: int64(4)},
+			{name: "unexpected aggregate", aggregate: &estypes.ValueCountAggregate{}, wantErr: true},
+		},
+		"totalNights": {
+			{name: "value", aggregate: &estypes.SumAggregate{Value: pointer.From(estypes.Float64(7))}, want: float64(7)},
+			{name: "no value", aggregate: &estypes.SumAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.DateHistogramAggregate{}, wantErr: true},
+		},
+		"totalRevenue": {
+			{name: "value", aggregate: &estypes.SumAggregate{Value: pointer.From(estypes.Float64(250.75))}, want: 250.75},
+			{name: "no value", aggregate: &estypes.SumAggregate{}, want: float64(0)},
+			{name: "unexpected aggregate", aggregate: &estypes.ScriptedMetricAggregate{}, wantErr: true},
+		},
+	}
+
+	for attribute, attributeTests := range tests {
+		config := esutil.GetComputedAttrAggConfig(attribute, resmodels.EntityTypeAccount)
+		require.NotNil(t, config.MapValueFunc, attribute)
+
+		for _, tt := range attributeTests {
+			t.Run(attribute+"/"+tt.name, func(t *testing.T) {
+				got, err := config.MapValueFunc(tt.aggregate)
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
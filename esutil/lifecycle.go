@@ -0,0 +1,384 @@
+package esutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/loungeup/go-loungeup"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/log"
+)
+
+const writeIndexSuffix = "write"
+
+// IndexDefinition contains the settings and mappings used to create an index.
+type IndexDefinition struct {
+	Settings json.RawMessage `json:"settings,omitempty"`
+	Mappings json.RawMessage `json:"mappings,omitempty"`
+}
+
+// NewIndexDefinition creates the definition of an index storing the given document type, with the mappings generated
+// by GenerateMapping.
+func NewIndexDefinition(document any) (*IndexDefinition, error) {
+	mapping, err := GenerateMapping(document)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate mapping: %w", err)
+	}
+
+	encodedMapping, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode mapping: %w", err)
+	}
+
+	return &IndexDefinition{Mappings: encodedMapping}, nil
+}
+
+// IndexOperationAction performed by an IndicesManager.
+type IndexOperationAction string
+
+const (
+	IndexOperationActionCreate      IndexOperationAction = "create"
+	IndexOperationActionDelete      IndexOperationAction = "delete"
+	IndexOperationActionMoveAliases IndexOperationAction = "moveAliases"
+)
+
+// IndexOperation performed (or planned in dry-run mode) by an IndicesManager.
+type IndexOperation struct {
+	Action IndexOperationAction
+	Index  string
+	Alias  string
+}
+
+// IndicesManager manages the lifecycle of the time-based indices computed by an IndicesMaker: creation with the right
+// definitions, write aliases, rollover and retention. In dry-run mode, operations are only planned and returned.
+//
+// Development and studio platforms use global indices, so only their creation is managed. Unless other definitions are
+// given, indices are created with the mappings of GuestBookingDocument and GuestCardDocument.
+type IndicesManager struct {
+	client   *elasticsearch.Client
+	platform loungeup.Platform
+	bookings *IndexDefinition
+	guests   *IndexDefinition
+	dryRun   bool
+	logger   *log.Logger
+}
+
+type IndicesManagerOption func(*IndicesManager)
+
+func NewIndicesManager(
+	client *elasticsearch.Client,
+	platform loungeup.Platform,
+	options ...IndicesManagerOption,
+) *IndicesManager {
+	result := &IndicesManager{
+		client:   client,
+		platform: platform,
+		logger:   log.Default().With(slog.String("component", "indicesManager")),
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	return result
+}
+
+func WithIndicesManagerBookingsDefinition(definition *IndexDefinition) IndicesManagerOption {
+	return func(m *IndicesManager) { m.bookings = definition }
+}
+
+func WithIndicesManagerGuestsDefinition(definition *IndexDefinition) IndicesManagerOption {
+	return func(m *IndicesManager) { m.guests = definition }
+}
+
+// WithIndicesManagerDryRun only plans operations, without executing them.
+func WithIndicesManagerDryRun(dryRun bool) IndicesManagerOption {
+	return func(m *IndicesManager) { m.dryRun = dryRun }
+}
+
+func WithIndicesManagerLogger(logger *log.Logger) IndicesManagerOption {
+	return func(m *IndicesManager) { m.logger = logger }
+}
+
+// WriteAliases returns the aliases pointing at the indices being written.
+func (m *IndicesManager) WriteAliases() *Indices {
+	return makeIndices(makeIndexPrefix(m.platform), writeIndexSuffix)
+}
+
+// CreateAt creates the indices of the given time if they do not exist yet.
+func (m *IndicesManager) CreateAt(ctx context.Context, t time.Time) ([]*IndexOperation, error) {
+	result := []*IndexOperation{}
+
+	indices := MakeIndices(m.platform).At(t)
+	for _, definedIndex := range []struct {
+		index      string
+		definition *IndexDefinition
+		document   any
+	}{
+		{indices.Bookings, m.bookings, GuestBookingDocument{}},
+		{indices.Guests, m.guests, GuestCardDocument{}},
+	} {
+		index, definition := definedIndex.index, definedIndex.definition
+
+		exists, err := m.indexExists(ctx, index)
+		if err != nil {
+			return result, err
+		}
+
+		if exists {
+			continue
+		}
+
+		if definition == nil {
+			if definition, err = NewIndexDefinition(definedIndex.document); err != nil {
+				return result, fmt.Errorf("could not define index %s: %w", index, err)
+			}
+		}
+
+		operation := &IndexOperation{Action: IndexOperationActionCreate, Index: index}
+		if err := m.execute(func() error { return m.createIndex(ctx, index, definition) }, operation); err != nil {
+			return result, err
+		}
+
+		result = append(result, operation)
+	}
+
+	return result, nil
+}
+
+// RolloverAt creates the indices of the given time and atomically moves the write aliases to them. The read aliases
+// returned by IndicesMaker.Wildcard are added to the new indices.
+func (m *IndicesManager) RolloverAt(ctx context.Context, t time.Time) ([]*IndexOperation, error) {
+	result, err := m.CreateAt(ctx, t)
+	if err != nil {
+		return result, err
+	}
+
+	if m.isUsingGlobalIndices() {
+		return result, nil
+	}
+
+	indices := MakeIndices(m.platform).At(t)
+	readAliases := MakeIndices(m.platform).Wildcard()
+	writeAliases := m.WriteAliases()
+
+	actions := []map[string]any{}
+	operations := []*IndexOperation{}
+
+	for _, aliasedIndex := range []struct{ index, readAlias, writeAlias string }{
+		{indices.Bookings, readAliases.Bookings, writeAliases.Bookings},
+		{indices.Guests, readAliases.Guests, writeAliases.Guests},
+	} {
+		actions = append(actions,
+			map[string]any{"remove": map[string]any{
+				"index":      "*",
+				"alias":      aliasedIndex.writeAlias,
+				"must_exist": false,
+			}},
+			map[string]any{"add": map[string]any{"index": aliasedIndex.index, "alias": aliasedIndex.readAlias}},
+			map[string]any{"add": map[string]any{
+				"index":          aliasedIndex.index,
+				"alias":          aliasedIndex.writeAlias,
+				"is_write_index": true,
+			}},
+		)
+
+		operations = append(operations, &IndexOperation{
+			Action: IndexOperationActionMoveAliases,
+			Index:  aliasedIndex.index,
+			Alias:  aliasedIndex.writeAlias,
+		})
+	}
+
+	if err := m.execute(func() error { return m.updateAliases(ctx, actions) }, operations...); err != nil {
+		return result, err
+	}
+
+	return append(result, operations...), nil
+}
+
+// ApplyRetentionAt deletes the indices whose period ended more than the given number of months before the given time.
+// Global indices are never deleted. The number of months must be positive, so the current indices are kept.
+func (m *IndicesManager) ApplyRetentionAt(ctx context.Context, t time.Time, months int) ([]*IndexOperation, error) {
+	result := []*IndexOperation{}
+
+	if months <= 0 {
+		return result, &errors.Error{
+			Code:    errors.CodeInvalid,
+			Message: "Retention must be at least one month",
+		}
+	}
+
+	if m.isUsingGlobalIndices() {
+		return result, nil
+	}
+
+	limit := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -months, 0)
+
+	wildcards := makeIndices(makeIndexPrefix(m.platform), "*")
+	for _, wildcard := range wildcards.Strings() {
+		indices, err := m.listIndices(ctx, wildcard)
+		if err != nil {
+			return result, err
+		}
+
+		for _, index := range indices {
+			periodEnd, ok := parseIndexPeriodEnd(strings.TrimPrefix(index, strings.TrimSuffix(wildcard, "*")))
+			if !ok || periodEnd.After(limit) {
+				continue
+			}
+
+			operation := &IndexOperation{Action: IndexOperationActionDelete, Index: index}
+			if err := m.execute(func() error { return m.deleteIndex(ctx, index) }, operation); err != nil {
+				return result, err
+			}
+
+			result = append(result, operation)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *IndicesManager) isUsingGlobalIndices() bool {
+	return m.platform == loungeup.PlatformDevelopment || m.platform == loungeup.PlatformStudio
+}
+
+// execute the given operations with a single call to the given function, unless the manager is in dry-run mode.
+func (m *IndicesManager) execute(executeFunc func() error, operations ...*IndexOperation) error {
+	l1 := m.logger.With(slog.Bool("dryRun", m.dryRun), slog.Any("operations", operations))
+
+	if m.dryRun {
+		l1.Debug("Skipping index operations")
+
+		return nil
+	}
+
+	if err := executeFunc(); err != nil {
+		return fmt.Errorf("could not %s index %s: %w", operations[0].Action, operations[0].Index, err)
+	}
+
+	l1.Debug("Index operations executed")
+
+	return nil
+}
+
+func (m *IndicesManager) indexExists(ctx context.Context, index string) (bool, error) {
+	response, err := m.client.Indices.Exists([]string{index}, m.client.Indices.Exists.WithContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("could not check if index %s exists: %w", index, err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("could not check if index %s exists: invalid status code: %d",
+			index, response.StatusCode,
+		)
+	}
+}
+
+func (m *IndicesManager) createIndex(ctx context.Context, index string, definition *IndexDefinition) error {
+	body, err := json.Marshal(definition)
+	if err != nil {
+		return fmt.Errorf("could not encode index definition: %w", err)
+	}
+
+	response, err := m.client.Indices.Create(index,
+		m.client.Indices.Create.WithBody(bytes.NewReader(body)),
+		m.client.Indices.Create.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ParseResponseBody(response)
+
+	return err
+}
+
+func (m *IndicesManager) deleteIndex(ctx context.Context, index string) error {
+	response, err := m.client.Indices.Delete([]string{index}, m.client.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = ParseResponseBody(response)
+
+	return err
+}
+
+func (m *IndicesManager) updateAliases(ctx context.Context, actions []map[string]any) error {
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("could not encode alias actions: %w", err)
+	}
+
+	response, err := m.client.Indices.UpdateAliases(bytes.NewReader(body),
+		m.client.Indices.UpdateAliases.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ParseResponseBody(response)
+
+	return err
+}
+
+func (m *IndicesManager) listIndices(ctx context.Context, pattern string) ([]string, error) {
+	response, err := m.client.Cat.Indices(
+		m.client.Cat.Indices.WithContext(ctx),
+		m.client.Cat.Indices.WithFormat("json"),
+		m.client.Cat.Indices.WithH("index"),
+		m.client.Cat.Indices.WithIndex(pattern),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list indices: %w", err)
+	}
+
+	body, err := ParseResponseBody(response)
+	if err != nil {
+		return nil, fmt.Errorf("could not list indices: %w", err)
+	}
+
+	models := []struct {
+		Index string `json:"index"`
+	}{}
+	if err := json.Unmarshal([]byte(body), &models); err != nil {
+		return nil, fmt.Errorf("could not decode indices: %w", err)
+	}
+
+	result := []string{}
+	for _, model := range models {
+		result = append(result, model.Index)
+	}
+
+	slices.Sort(result)
+
+	return result, nil
+}
+
+// parseIndexPeriodEnd returns the end of the period covered by an index suffix (e.g. "2021" or "2023-01").
+func parseIndexPeriodEnd(suffix string) (time.Time, bool) {
+	if t, err := time.Parse("2006-01", suffix); err == nil {
+		return t.AddDate(0, 1, 0), true
+	}
+
+	if t, err := time.Parse("2006", suffix); err == nil {
+		return t.AddDate(1, 0, 0), true
+	}
+
+	return time.Time{}, false
+}
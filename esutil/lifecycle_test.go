@@ -0,0 +1,174 @@
+package esutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loungeup/go-loungeup"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndicesManagerRolloverAt(t *testing.T) {
+	server := newIndicesServerMock(t, []string{"production-guestbookings-2024-01"}, "[]")
+	defer server.Close()
+
+	got, err := NewIndicesManager(newTestClient(t, server.URL), loungeup.PlatformProduction).
+		RolloverAt(context.Background(), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*IndexOperation{
+		{Action: IndexOperationActionCreate, Index: "production-guestcards-2024-01"},
+		{
+			Action: IndexOperationActionMoveAliases,
+			Index:  "production-guestbookings-2024-01",
+			Alias:  "production-guestbookings-write",
+		},
+		{
+			Action: IndexOperationActionMoveAliases,
+			Index:  "production-guestcards-2024-01",
+			Alias:  "production-guestcards-write",
+		},
+	}, got)
+	assert.Equal(t, []string{
+		"HEAD /production-guestbookings-2024-01",
+		"HEAD /production-guestcards-2024-01",
+		"PUT /production-guestcards-2024-01",
+		"POST /_aliases",
+	}, server.requests)
+
+	wantDefinition, err := NewIndexDefinition(GuestCardDocument{})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantDefinition.Mappings), string(server.createdIndices["production-guestcards-2024-01"]))
+}
+
+func TestIndicesManagerApplyRetentionAt(t *testing.T) {
+	server := newIndicesServerMock(t, nil, `[
+		{"index": "production-guestbookings-2021"},
+		{"index": "production-guestbookings-2023-12"},
+		{"index": "production-guestbookings-2024-01"},
+		{"index": "production-guestbookings-global"}
+	]`)
+	defer server.Close()
+
+	got, err := NewIndicesManager(
+		newTestClient(t, server.URL),
+		loungeup.PlatformProduction,
+		WithIndicesManagerDryRun(true),
+	).ApplyRetentionAt(context.Background(), time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*IndexOperation{
+		{Action: IndexOperationActionDelete, Index: "production-guestbookings-2021"},
+		{Action: IndexOperationActionDelete, Index: "production-guestbookings-2023-12"},
+	}, got)
+
+	for _, request := range server.requests {
+		assert.NotContains(t, request, "DELETE", "no index should be deleted in dry-run mode")
+	}
+}
+
+func TestIndicesManagerApplyInvalidRetention(t *testing.T) {
+	server := newIndicesServerMock(t, nil, "[]")
+	defer server.Close()
+
+	for _, months := range []int{0, -1} {
+		got, err := NewIndicesManager(newTestClient(t, server.URL), loungeup.PlatformProduction).
+			ApplyRetentionAt(context.Background(), time.Now(), months)
+		assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+		assert.Empty(t, got)
+	}
+
+	assert.Empty(t, server.requests)
+}
+
+func TestIndicesManagerDevelopment(t *testing.T) {
+	server := newIndicesServerMock(t, []string{"development-guestbookings-global", "development-guestcards-global"}, "[]")
+	defer server.Close()
+
+	manager := NewIndicesManager(newTestClient(t, server.URL), loungeup.PlatformDevelopment)
+
+	got, err := manager.RolloverAt(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = manager.ApplyRetentionAt(context.Background(), time.Now(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseIndexPeriodEnd(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		want   time.Time
+		wantOK bool
+	}{
+		"month":  {in: "2023-12", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), wantOK: true},
+		"year":   {in: "2021", want: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), wantOK: true},
+		"global": {in: "global"},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, ok := parseIndexPeriodEnd(tt.in)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type indicesServerMock struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+
+	// createdIndices maps the created indices to their mappings.
+	createdIndices map[string]json.RawMessage
+}
+
+func newIndicesServerMock(t *testing.T, existingIndices []string, catIndicesResponse string) *indicesServerMock {
+	t.Helper()
+
+	result := &indicesServerMock{createdIndices: map[string]json.RawMessage{}}
+	result.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result.mu.Lock()
+		defer result.mu.Unlock()
+
+		result.requests = append(result.requests, r.Method+" "+r.URL.Path)
+
+		if r.Method == http.MethodPut {
+			definition := &IndexDefinition{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(definition))
+
+			result.createdIndices[strings.TrimPrefix(r.URL.Path, "/")] = definition.Mappings
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		switch {
+		case r.Method == http.MethodHead:
+			for _, index := range existingIndices {
+				if r.URL.Path == "/"+index {
+					return
+				}
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(catIndicesResponse))
+		default:
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		}
+	}))
+
+	return result
+}
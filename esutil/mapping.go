@@ -1,6 +1,8 @@
 package esutil
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"sync/atomic"
 
@@ -20,6 +22,23 @@ type MappingKeys struct {
 	Booking            *BookingMappingKeys
 	Guest              *GuestMappingKeys
 	ComputedAttributes *ComputedAttributesMappingKeys
+	Aggregations       *AggregationsMappingKeys
+}
+
+type AggregationsMappingKeys struct {
+	Account *ScopedAggregationsMappingKeys
+	Chain   *ScopedAggregationsMappingKeys
+	Group   *ScopedAggregationsMappingKeys
+}
+
+type ScopedAggregationsMappingKeys struct {
+	AvgFares              string
+	CounterBookings       string
+	CounterFutureBookings string
+	CounterPastBookings   string
+	LastDeparture         string
+	NextArrival           string
+	SumFares              string
 }
 
 type ComputedAttributesMappingKeys struct {
@@ -39,11 +58,13 @@ type ScopedMappingKeys struct {
 	Booking            *BookingMappingKeys
 	Guest              *ScopedGuestMappingKeys
 	ComputedAttributes *ScopedComputedAttributesMappingKeys
+	Aggregations       *ScopedAggregationsMappingKeys
 }
 
 type BookingMappingKeys struct {
 	Arrival                  string
 	ArrivalDay               string
+	ArrivalDow               string
 	ArrivalTime              string
 	Balance                  string
 	BookingDate              string
@@ -59,6 +80,7 @@ type BookingMappingKeys struct {
 	CustomFieldsText         string
 	Departure                string
 	DepartureDay             string
+	DepartureDow             string
 	EntityID                 string
 	Fare                     string
 	FareCode                 string
@@ -76,8 +98,10 @@ type BookingMappingKeys struct {
 	RoomType                 string
 	Status                   string
 	StayLength               string
+	Tags                     string
 	TouristTax               string
 	UpdatedAt                string
+	Weekend                  string
 	Wildcard                 string
 }
 
@@ -115,6 +139,7 @@ type ScopedGuestMappingKeys struct {
 	EmailsMergeableAt   string
 	PMSID               string
 	State               string
+	Tags                string
 	Title               string
 	UpdatedAt           string
 	TrustableContacts   string
@@ -144,30 +169,30 @@ func NewMappingKeysScope(value string) MappingKeysScope {
 	}
 }
 
-func (scope MappingKeysScope) guestPrefix() string {
+// fieldName returns the name of the field of the documents holding the values of the scope.
+func (scope MappingKeysScope) fieldName() string {
 	switch scope {
 	case MappingKeysScopeAccount:
-		return "guest.account"
+		return "Account"
 	case MappingKeysScopeChain:
-		return "guest.chain"
+		return "Chain"
 	case MappingKeysScopeGroup:
-		return "guest.group"
+		return "Group"
 	default:
 		return ""
 	}
 }
 
+func (scope MappingKeysScope) guestPrefix() string {
+	return documentMappingKey[GuestCardDocument]("Guest", scope.fieldName())
+}
+
 func (scope MappingKeysScope) computedAttributePrefix() string {
-	switch scope {
-	case MappingKeysScopeAccount:
-		return "typedComputedAttributes.account"
-	case MappingKeysScopeChain:
-		return "typedComputedAttributes.chain"
-	case MappingKeysScopeGroup:
-		return "typedComputedAttributes.group"
-	default:
-		return ""
-	}
+	return documentMappingKey[GuestCardDocument]("TypedComputedAttributes", scope.fieldName())
+}
+
+func (scope MappingKeysScope) aggregationsPrefix() string {
+	return documentMappingKey[GuestCardDocument]("Aggregations", scope.fieldName())
 }
 
 func (scope MappingKeysScope) validate() error {
@@ -192,6 +217,11 @@ func newMappingKeys() *MappingKeys {
 			Group:   newScopedComputedAttributeMappingKeys(MappingKeysScopeGroup),
 			Chain:   newScopedComputedAttributeMappingKeys(MappingKeysScopeChain),
 		},
+		Aggregations: &AggregationsMappingKeys{
+			Account: newScopedAggregationsMappingKeys(MappingKeysScopeAccount),
+			Chain:   newScopedAggregationsMappingKeys(MappingKeysScopeChain),
+			Group:   newScopedAggregationsMappingKeys(MappingKeysScopeGroup),
+		},
 	}
 }
 
@@ -209,103 +239,160 @@ func NewScopedMappingKeys(scope MappingKeysScope) (*ScopedMappingKeys, error) {
 			Number:  newScopedComputedAttributeMappingKeys(scope).Number,
 			Text:    newScopedComputedAttributeMappingKeys(scope).Text,
 		},
+		Aggregations: newScopedAggregationsMappingKeys(scope),
 	}, nil
 }
 
 func newBookingMappingKeys() *BookingMappingKeys {
-	prefix := "booking"
+	prefix := documentMappingKey[GuestBookingDocument]("Booking")
+	key := newMappingKeyBuilder[Booking](prefix)
 
 	return &BookingMappingKeys{
-		Arrival:                  joinMappingKeyParts(prefix, "arrival"),
-		ArrivalDay:               joinMappingKeyParts(prefix, "arrivalDay"),
-		ArrivalTime:              joinMappingKeyParts(prefix, "data.arrivalTime"),
-		Balance:                  joinMappingKeyParts(prefix, "balance"),
-		BookingDate:              joinMappingKeyParts(prefix, "bookingDate"),
-		Channel:                  joinMappingKeyParts(prefix, "channel"),
-		CicoHasCompletedPayment:  joinMappingKeyParts(prefix, "cico.hasCompletedPayment"),
-		CicoHasFilledPoliceForm:  joinMappingKeyParts(prefix, "cico.hasFilledPoliceForm"),
-		CicoHasFilledPrestayForm: joinMappingKeyParts(prefix, "cico.hasFilledPrestayForm"),
-		CustomFields:             joinMappingKeyParts(prefix, "customFields"),
-		CustomFieldsBoolean:      joinMappingKeyParts(prefix, "customFields.boolean"),
-		CustomFieldsDate:         joinMappingKeyParts(prefix, "customFields.date"),
-		CustomFieldsList:         joinMappingKeyParts(prefix, "customFields.list"),
-		CustomFieldsNumber:       joinMappingKeyParts(prefix, "customFields.number"),
-		CustomFieldsText:         joinMappingKeyParts(prefix, "customFields.text"),
-		Departure:                joinMappingKeyParts(prefix, "departure"),
-		DepartureDay:             joinMappingKeyParts(prefix, "departureDay"),
-		EntityID:                 joinMappingKeyParts(prefix, "entityId"),
-		Fare:                     joinMappingKeyParts(prefix, "fare"),
-		FareCode:                 joinMappingKeyParts(prefix, "fareCode"),
-		GuestID:                  joinMappingKeyParts(prefix, "guestId"),
-		ID:                       joinMappingKeyParts(prefix, "id"),
-		Index:                    joinMappingKeyParts(prefix, "data.index"),
-		InstayDates:              joinMappingKeyParts(prefix, "instayDates"),
-		Pass:                     joinMappingKeyParts(prefix, "pass"),
-		PaxAdults:                joinMappingKeyParts(prefix, "paxAdults"),
-		PaxBabies:                joinMappingKeyParts(prefix, "paxBabies"),
-		PaxChildren:              joinMappingKeyParts(prefix, "paxChildren"),
-		PMSBookingID:             joinMappingKeyParts(prefix, "pmsBookingId"),
-		PMSBookingParentID:       joinMappingKeyParts(prefix, "pmsBookingParentId"),
-		Room:                     joinMappingKeyParts(prefix, "room"),
-		RoomType:                 joinMappingKeyParts(prefix, "roomType"),
-		Status:                   joinMappingKeyParts(prefix, "status"),
-		StayLength:               joinMappingKeyParts(prefix, "stayLength"),
-		TouristTax:               joinMappingKeyParts(prefix, "touristTax"),
-		UpdatedAt:                joinMappingKeyParts(prefix, "updatedAt"),
+		Arrival:                  key("Arrival"),
+		ArrivalDay:               key("ArrivalDay"),
+		ArrivalDow:               key("ArrivalDow"),
+		ArrivalTime:              key("Data", "ArrivalTime"),
+		Balance:                  key("Balance"),
+		BookingDate:              key("BookingDate"),
+		Channel:                  key("Channel"),
+		CicoHasCompletedPayment:  key("Cico", "HasCompletedPayment"),
+		CicoHasFilledPoliceForm:  key("Cico", "HasFilledPoliceForm"),
+		CicoHasFilledPrestayForm: key("Cico", "HasFilledPrestayForm"),
+		CustomFields:             key("CustomFields"),
+		CustomFieldsBoolean:      key("CustomFields", "Boolean"),
+		CustomFieldsDate:         key("CustomFields", "Date"),
+		CustomFieldsList:         key("CustomFields", "List"),
+		CustomFieldsNumber:       key("CustomFields", "Number"),
+		CustomFieldsText:         key("CustomFields", "Text"),
+		Departure:                key("Departure"),
+		DepartureDay:             key("DepartureDay"),
+		DepartureDow:             key("DepartureDow"),
+		EntityID:                 key("EntityID"),
+		Fare:                     key("Fare"),
+		FareCode:                 key("FareCode"),
+		GuestID:                  key("GuestID"),
+		ID:                       key("ID"),
+		Index:                    key("Data", "Index"),
+		InstayDates:              key("InstayDates"),
+		Pass:                     key("Pass"),
+		PaxAdults:                key("PaxAdults"),
+		PaxBabies:                key("PaxBabies"),
+		PaxChildren:              key("PaxChildren"),
+		PMSBookingID:             key("PMSBookingID"),
+		PMSBookingParentID:       key("PMSBookingParentID"),
+		Room:                     key("Room"),
+		RoomType:                 key("RoomType"),
+		Status:                   key("Status"),
+		StayLength:               key("StayLength"),
+		Tags:                     key("Tags"),
+		TouristTax:               key("TouristTax"),
+		UpdatedAt:                key("UpdatedAt"),
+		Weekend:                  key("Weekend"),
 		Wildcard:                 joinMappingKeyParts(prefix, "*"),
 	}
 }
 
 func newScopedGuestMappingKeys(scope MappingKeysScope) *ScopedGuestMappingKeys {
 	prefix := scope.guestPrefix()
+	key := newMappingKeyBuilder[ScopedGuest](prefix)
 
 	return &ScopedGuestMappingKeys{
-		Anonymous:           joinMappingKeyParts(prefix, "anonymous"),
-		Birthdate:           joinMappingKeyParts(prefix, "birthdate"),
-		BirthplaceCountry:   joinMappingKeyParts(prefix, "birthplace.country"),
-		City:                joinMappingKeyParts(prefix, "city"),
-		Company:             joinMappingKeyParts(prefix, "company"),
-		ComposedWith:        joinMappingKeyParts(prefix, "composedWith"),
-		Country:             joinMappingKeyParts(prefix, "country"),
-		CustomFields:        joinMappingKeyParts(prefix, "customFields"),
-		CustomFieldsBoolean: joinMappingKeyParts(prefix, "customFields.boolean"),
-		CustomFieldsDate:    joinMappingKeyParts(prefix, "customFields.date"),
-		CustomFieldsList:    joinMappingKeyParts(prefix, "customFields.list"),
-		CustomFieldsNumber:  joinMappingKeyParts(prefix, "customFields.number"),
-		CustomFieldsText:    joinMappingKeyParts(prefix, "customFields.text"),
-		Emails:              joinMappingKeyParts(prefix, "emails"),
-		EntityID:            joinMappingKeyParts(prefix, "entityId"),
-		FirstName:           joinMappingKeyParts(prefix, "firstname"),
-		Gender:              joinMappingKeyParts(prefix, "gender"),
-		ID:                  joinMappingKeyParts(prefix, "id"),
-		Languages:           joinMappingKeyParts(prefix, "languages"),
-		LastName:            joinMappingKeyParts(prefix, "lastname"),
-		Nationalities:       joinMappingKeyParts(prefix, "nationalities"),
-		OptedOutMarketing:   joinMappingKeyParts(prefix, "optedOut.marketing"),
-		Phones:              joinMappingKeyParts(prefix, "phones"),
-		PhonesMergeableAt:   joinMappingKeyParts(prefix, "phonesMergeableAt"),
-		EmailsMergeableAt:   joinMappingKeyParts(prefix, "emailsMergeableAt"),
-		PMSID:               joinMappingKeyParts(prefix, "pmsId"),
-		State:               joinMappingKeyParts(prefix, "state"),
-		Title:               joinMappingKeyParts(prefix, "title"),
-		UpdatedAt:           joinMappingKeyParts(prefix, "updatedAt"),
-		TrustableContacts:   joinMappingKeyParts(prefix, "trustableContacts"),
-		Zipcode:             joinMappingKeyParts(prefix, "zipcode"),
+		Anonymous:           key("Anonymous"),
+		Birthdate:           key("Birthdate"),
+		BirthplaceCountry:   key("Birthplace", "Country"),
+		City:                key("City"),
+		Company:             key("Company"),
+		ComposedWith:        key("ComposedWith"),
+		Country:             key("Country"),
+		CustomFields:        key("CustomFields"),
+		CustomFieldsBoolean: key("CustomFields", "Boolean"),
+		CustomFieldsDate:    key("CustomFields", "Date"),
+		CustomFieldsList:    key("CustomFields", "List"),
+		CustomFieldsNumber:  key("CustomFields", "Number"),
+		CustomFieldsText:    key("CustomFields", "Text"),
+		Emails:              key("Emails"),
+		EntityID:            key("EntityID"),
+		FirstName:           key("Firstname"),
+		Gender:              key("Gender"),
+		ID:                  key("ID"),
+		Languages:           key("Languages"),
+		LastName:            key("Lastname"),
+		Nationalities:       key("Nationalities"),
+		OptedOutMarketing:   key("OptedOut", "Marketing"),
+		Phones:              key("Phones"),
+		PhonesMergeableAt:   key("PhonesMergeableAt"),
+		EmailsMergeableAt:   key("EmailsMergeableAt"),
+		PMSID:               key("PMSID"),
+		State:               key("State"),
+		Tags:                key("Tags"),
+		Title:               key("Title"),
+		UpdatedAt:           key("UpdatedAt"),
+		TrustableContacts:   key("TrustableContacts"),
+		Zipcode:             key("ZipCode"),
 		Wildcard:            joinMappingKeyParts(prefix, "*"),
 	}
 }
 
 func newScopedComputedAttributeMappingKeys(scope MappingKeysScope) *ScopedComputedAttributesMappingKeys {
-	prefix := scope.computedAttributePrefix()
+	key := newMappingKeyBuilder[ScopedTypedComputedAttributes](scope.computedAttributePrefix())
 
 	return &ScopedComputedAttributesMappingKeys{
-		Boolean: joinMappingKeyParts(prefix, "boolean"),
-		Date:    joinMappingKeyParts(prefix, "date"),
-		Number:  joinMappingKeyParts(prefix, "number"),
-		Text:    joinMappingKeyParts(prefix, "text"),
+		Boolean: key("Boolean"),
+		Date:    key("Date"),
+		Number:  key("Number"),
+		Text:    key("Text"),
+	}
+}
+
+func newScopedAggregationsMappingKeys(scope MappingKeysScope) *ScopedAggregationsMappingKeys {
+	key := newMappingKeyBuilder[ScopedAggregations](scope.aggregationsPrefix())
+
+	return &ScopedAggregationsMappingKeys{
+		AvgFares:              key("AvgFares"),
+		CounterBookings:       key("CounterBookings"),
+		CounterFutureBookings: key("CounterFutureBookings"),
+		CounterPastBookings:   key("CounterPastBookings"),
+		LastDeparture:         key("LastDeparture"),
+		NextArrival:           key("NextArrival"),
+		SumFares:              key("SumFares"),
+	}
+}
+
+// newMappingKeyBuilder returns a function building the keys of the fields of the document type T below the given
+// prefix. Keys are built from the JSON names of the Go fields, so they follow the documents the mapping is generated
+// from.
+func newMappingKeyBuilder[T any](prefix string) func(fieldNames ...string) string {
+	return func(fieldNames ...string) string {
+		return joinMappingKeyParts(prefix, documentMappingKey[T](fieldNames...))
 	}
 }
 
+// documentMappingKey returns the dotted JSON path of the given Go fields of the document type T. It panics if a field
+// does not exist, since keys are built when the package is initialized.
+func documentMappingKey[T any](fieldNames ...string) string {
+	t := reflect.TypeFor[T]()
+	parts := []string{}
+
+	for _, fieldName := range fieldNames {
+		t = elementType(t)
+
+		field, ok := t.FieldByName(fieldName)
+		if !ok {
+			panic(fmt.Sprintf("esutil: %s has no field %q", t, fieldName))
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		parts = append(parts, name)
+		t = field.Type
+	}
+
+	return joinMappingKeyParts(parts...)
+}
+
 func joinMappingKeyParts(parts ...string) string {
 	return strings.Join(parts, ".")
 }
@@ -0,0 +1,245 @@
+package esutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mapping of an index, generated from a document type by GenerateMapping.
+type Mapping struct {
+	Properties map[string]*MappingProperty `json:"properties"`
+}
+
+// MappingProperty of a field. Only one of Type or Properties is set, except for nested fields.
+type MappingProperty struct {
+	Type       string                      `json:"type,omitempty"`
+	Enabled    *bool                       `json:"enabled,omitempty"`
+	Properties map[string]*MappingProperty `json:"properties,omitempty"`
+
+	// dynamic fields are mapped by Elasticsearch when they are indexed.
+	dynamic bool
+}
+
+var _ json.Marshaler = (*Mapping)(nil)
+
+func (m *Mapping) MarshalJSON() ([]byte, error) {
+	type Alias Mapping
+
+	return json.Marshal(&Alias{Properties: withoutDynamicProperties(m.Properties)})
+}
+
+var _ json.Marshaler = (*MappingProperty)(nil)
+
+func (p *MappingProperty) MarshalJSON() ([]byte, error) {
+	type Alias MappingProperty
+
+	alias := Alias(*p)
+	alias.Properties = withoutDynamicProperties(p.Properties)
+
+	return json.Marshal(&alias)
+}
+
+// withoutDynamicProperties returns the given properties without the dynamic ones, which are only known by their path.
+func withoutDynamicProperties(properties map[string]*MappingProperty) map[string]*MappingProperty {
+	if properties == nil {
+		return nil
+	}
+
+	result := map[string]*MappingProperty{}
+
+	for name, property := range properties {
+		if !property.dynamic {
+			result[name] = property
+		}
+	}
+
+	return result
+}
+
+// Paths returns the sorted dotted paths of every field of the mapping, objects included.
+func (m *Mapping) Paths() []string {
+	result := []string{}
+
+	var walk func(prefix string, properties map[string]*MappingProperty)
+	walk = func(prefix string, properties map[string]*MappingProperty) {
+		for name, property := range properties {
+			path := joinMappingPath(prefix, name)
+			result = append(result, path)
+			walk(path, property.Properties)
+		}
+	}
+	walk("", m.Properties)
+
+	sort.Strings(result)
+
+	return result
+}
+
+// HasPath returns true if the given dotted path is a field of the mapping. Paths ending with "*" match the fields of
+// an object, and the paths below a dynamic field always match.
+func (m *Mapping) HasPath(path string) bool {
+	properties := m.Properties
+
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 {
+			return len(properties) > 0
+		}
+
+		property, ok := properties[part]
+		if !ok {
+			return false
+		}
+
+		if property.dynamic {
+			return true
+		}
+
+		properties = property.Properties
+	}
+
+	return true
+}
+
+// GenerateMapping of the given document type. Fields are mapped from their JSON name and their Go type: strings are
+// keywords, numbers are longs or doubles, booleans are booleans and structs are objects. The `es` struct tag
+// overrides the type of a field (e.g. `es:"date"`), and accepts the following special values:
+//   - "-" to skip the field,
+//   - "dynamic" to let Elasticsearch map the field,
+//   - "object,disabled" to store an object without indexing it.
+//
+// It fails, listing every field, when fields cannot be mapped from their type (e.g. any or json.RawMessage) and have no
+// tag.
+func GenerateMapping(document any) (*Mapping, error) {
+	generator := &mappingGenerator{}
+
+	properties := generator.generateProperties("", reflect.TypeOf(document))
+	if len(generator.unmappedFields) > 0 {
+		return nil, fmt.Errorf("could not map fields: %s", strings.Join(generator.unmappedFields, ", "))
+	}
+
+	return &Mapping{Properties: properties}, nil
+}
+
+type mappingGenerator struct {
+	unmappedFields []string
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *mappingGenerator) generateProperties(prefix string, t reflect.Type) map[string]*MappingProperty {
+	t = indirectType(t)
+	result := map[string]*MappingProperty{}
+
+	if t.Kind() != reflect.Struct {
+		return result
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, like encoding/json does.
+		if field.Anonymous && name == "" {
+			for embeddedName, property := range g.generateProperties(prefix, field.Type) {
+				result[embeddedName] = property
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if property := g.generateProperty(joinMappingPath(prefix, name), field); property != nil {
+			result[name] = property
+		}
+	}
+
+	return result
+}
+
+func (g *mappingGenerator) generateProperty(path string, field reflect.StructField) *MappingProperty {
+	tag, ok := field.Tag.Lookup("es")
+	if ok {
+		mappingType, option, _ := strings.Cut(tag, ",")
+
+		switch {
+		case mappingType == "-":
+			return nil
+		case mappingType == "dynamic":
+			return &MappingProperty{dynamic: true}
+		case option == "disabled":
+			return &MappingProperty{Type: mappingType, Enabled: new(bool)}
+		case mappingType == "object" || mappingType == "nested":
+			return &MappingProperty{Type: mappingType, Properties: g.generateProperties(path, elementType(field.Type))}
+		default:
+			return &MappingProperty{Type: mappingType}
+		}
+	}
+
+	t := elementType(field.Type)
+
+	switch {
+	case t == timeType:
+		return &MappingProperty{Type: "date"}
+	case t == rawMessageType:
+	case t.Kind() == reflect.String:
+		return &MappingProperty{Type: "keyword"}
+	case t.Kind() == reflect.Bool:
+		return &MappingProperty{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &MappingProperty{Type: "long"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &MappingProperty{Type: "double"}
+	case t.Kind() == reflect.Struct:
+		return &MappingProperty{Properties: g.generateProperties(path, t)}
+	}
+
+	g.unmappedFields = append(g.unmappedFields, path)
+
+	return nil
+}
+
+func joinMappingPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return joinMappingKeyParts(prefix, name)
+}
+
+// elementType returns the type of the values of a field, without pointers and slices (e.g. Array[string] is mapped as
+// a string).
+func elementType(t reflect.Type) reflect.Type {
+	t = indirectType(t)
+
+	if t.Kind() == reflect.Slice && t != rawMessageType {
+		return indirectType(t.Elem())
+	}
+
+	return t
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,134 @@
+package esutil_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/loungeup/go-loungeup/esutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMapping(t *testing.T) {
+	t.Run("documents", func(t *testing.T) {
+		for _, document := range []any{
+			esutil.GuestBookingDocument{},
+			esutil.GuestCardDocument{},
+			esutil.ScopedGuestBookingDocument{},
+			esutil.ScopedGuestCardDocument{},
+		} {
+			_, err := esutil.GenerateMapping(document)
+			assert.NoError(t, err, "every field of %T must be mapped", document)
+		}
+	})
+
+	t.Run("mapping keys", func(t *testing.T) {
+		mapping, err := esutil.GenerateMapping(esutil.GuestBookingDocument{})
+		require.NoError(t, err)
+
+		keys := collectMappingKeys(reflect.ValueOf(esutil.GlobalMappingKeys()))
+		require.NotEmpty(t, keys)
+
+		for _, key := range keys {
+			assert.True(t, mapping.HasPath(key), "mapping key %q must point at a document field", key)
+		}
+	})
+
+	t.Run("scoped mapping keys", func(t *testing.T) {
+		globalMapping, err := esutil.GenerateMapping(esutil.GuestBookingDocument{})
+		require.NoError(t, err)
+
+		bookingMapping, err := esutil.GenerateMapping(esutil.ScopedGuestBookingDocument{})
+		require.NoError(t, err)
+
+		cardMapping, err := esutil.GenerateMapping(esutil.ScopedGuestCardDocument{})
+		require.NoError(t, err)
+
+		for scope, scopeName := range map[esutil.MappingKeysScope]string{
+			esutil.MappingKeysScopeAccount: "account",
+			esutil.MappingKeysScopeChain:   "chain",
+			esutil.MappingKeysScopeGroup:   "group",
+		} {
+			keys, err := esutil.NewScopedMappingKeys(scope)
+			require.NoError(t, err)
+
+			for _, key := range collectMappingKeys(reflect.ValueOf(keys)) {
+				assert.True(t, globalMapping.HasPath(key), "mapping key %q must point at a document field", key)
+
+				// Scoped documents hold the values of a single scope, without the scope part of the key.
+				scopedKey := strings.Replace(key, "."+scopeName+".", ".", 1)
+				assert.True(t, bookingMapping.HasPath(scopedKey), "scoped key %q must point at a booking field", scopedKey)
+
+				if !strings.HasPrefix(key, "booking.") {
+					assert.True(t, cardMapping.HasPath(scopedKey), "scoped key %q must point at a card field", scopedKey)
+				}
+			}
+		}
+	})
+
+	t.Run("types", func(t *testing.T) {
+		type document struct {
+			Name    string            `json:"name"`
+			Tags    esutil.Array[int] `json:"tags"`
+			Score   *float64          `json:"score"`
+			Arrival string            `json:"arrival" es:"date"`
+			Extra   json.RawMessage   `json:"extra" es:"object,disabled"`
+			Value   any               `json:"value" es:"dynamic"`
+			Ignored any               `json:"ignored" es:"-"`
+			Nested  struct {
+				Enabled bool `json:"enabled"`
+			} `json:"nested"`
+		}
+
+		mapping, err := esutil.GenerateMapping(document{})
+		require.NoError(t, err)
+
+		encodedMapping, err := json.Marshal(mapping)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"properties": {
+			"arrival": {"type": "date"},
+			"extra": {"type": "object", "enabled": false},
+			"name": {"type": "keyword"},
+			"nested": {"properties": {"enabled": {"type": "boolean"}}},
+			"score": {"type": "double"},
+			"tags": {"type": "long"}
+		}}`, string(encodedMapping))
+
+		assert.Equal(t, []string{
+			"arrival", "extra", "name", "nested", "nested.enabled", "score", "tags", "value",
+		}, mapping.Paths())
+		assert.True(t, mapping.HasPath("value.anything"))
+		assert.True(t, mapping.HasPath("nested.*"))
+		assert.False(t, mapping.HasPath("nested.disabled"))
+	})
+
+	t.Run("unmapped fields", func(t *testing.T) {
+		type document struct {
+			Value any `json:"value"`
+		}
+
+		_, err := esutil.GenerateMapping(document{})
+		assert.ErrorContains(t, err, "value")
+	})
+}
+
+// collectMappingKeys returns the string fields of the given mapping keys, recursively.
+func collectMappingKeys(value reflect.Value) []string {
+	value = reflect.Indirect(value)
+
+	switch value.Kind() {
+	case reflect.String:
+		return []string{value.String()}
+	case reflect.Struct:
+		result := []string{}
+		for i := range value.NumField() {
+			result = append(result, collectMappingKeys(value.Field(i))...)
+		}
+
+		return result
+	default:
+		return nil
+	}
+}
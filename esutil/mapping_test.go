@@ -14,6 +14,7 @@ func TestMappingKeys(t *testing.T) {
 		assert.Equal(t, "guest.account.id", keys.Guest.Account.ID)
 		assert.Equal(t, "guest.chain.id", keys.Guest.Chain.ID)
 		assert.Equal(t, "guest.group.id", keys.Guest.Group.ID)
+		assert.Equal(t, "aggregations.chain.sumFares", keys.Aggregations.Chain.SumFares)
 	})
 
 	t.Run("scoped", func(t *testing.T) {
@@ -21,6 +22,7 @@ func TestMappingKeys(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "booking.id", keys.Booking.ID)
 		assert.Equal(t, "guest.account.id", keys.Guest.ID)
+		assert.Equal(t, "aggregations.account.nextArrival", keys.Aggregations.NextArrival)
 	})
 
 	t.Run("invalid scope", func(t *testing.T) {
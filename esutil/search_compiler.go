@@ -0,0 +1,608 @@
+package esutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/pointer"
+)
+
+const searchDateFormat = "2006-01-02"
+
+// Errors returned when search conditions cannot be compiled. They can be checked with errors.Is on a
+// SearchCompileError.
+var (
+	ErrUnsupportedSearchField    = errors.New("unsupported search field")
+	ErrUnsupportedSearchOperator = errors.New("unsupported search operator")
+	ErrInvalidSearchValue        = errors.New("invalid search value")
+	ErrInvalidSearchLogic        = errors.New("invalid search logic")
+)
+
+// SearchCompileError is returned when search conditions cannot be compiled into an Elasticsearch query.
+type SearchCompileError struct {
+	// Path of the invalid criteria in the conditions (e.g. "criteria[0].criteria[1]").
+	Path     string
+	Field    models.SearchCriteriaField
+	Operator models.SearchCriteriaOperator
+
+	// Reason is one of the ErrUnsupportedSearchField, ErrUnsupportedSearchOperator, ErrInvalidSearchValue or
+	// ErrInvalidSearchLogic errors.
+	Reason error
+}
+
+func (e *SearchCompileError) Error() string {
+	result := e.Path + ": " + e.Reason.Error()
+
+	if e.Field != "" || e.Operator != "" {
+		result += fmt.Sprintf(" (field: %q, operator: %q)", e.Field, e.Operator)
+	}
+
+	return result
+}
+
+func (e *SearchCompileError) Unwrap() error { return e.Reason }
+
+// SearchCompiler compiles search conditions into Elasticsearch queries without calling the Guest Profile server.
+type SearchCompiler struct {
+	keys *ScopedMappingKeys
+	now  func() time.Time
+}
+
+type SearchCompilerOption func(*SearchCompiler)
+
+// NewSearchCompiler creates a compiler using the mapping keys of the given scope.
+func NewSearchCompiler(scope MappingKeysScope, options ...SearchCompilerOption) (*SearchCompiler, error) {
+	keys, err := NewScopedMappingKeys(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchCompiler{
+		keys: keys,
+		now:  time.Now,
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	return result, nil
+}
+
+// WithSearchCompilerNow sets the function returning the current time, used to resolve keyword and relative dates.
+func WithSearchCompilerNow(now func() time.Time) SearchCompilerOption {
+	return func(c *SearchCompiler) { c.now = now }
+}
+
+// Compile the given conditions into an Elasticsearch query.
+func (c *SearchCompiler) Compile(conditions *models.SearchConditions) (*estypes.Query, error) {
+	result := []estypes.Query{}
+
+	for i, criterion := range conditions.Criteria {
+		path := "criteria[" + strconv.Itoa(i) + "]"
+
+		queries := []estypes.Query{}
+
+		for j, criteria := range criterion.Criteria {
+			query, err := c.compileCriteria(criteria)
+			if err != nil {
+				err.Path = path + ".criteria[" + strconv.Itoa(j) + "]"
+
+				return nil, err
+			}
+
+			queries = append(queries, *query)
+		}
+
+		query, err := combineSearchQueries(criterion.Logic, queries)
+		if err != nil {
+			err.Path = path
+
+			return nil, err
+		}
+
+		result = append(result, *query)
+	}
+
+	query, err := combineSearchQueries(conditions.Logic, result)
+	if err != nil {
+		err.Path = "$"
+
+		return nil, err
+	}
+
+	return query, nil
+}
+
+func combineSearchQueries(logic string, queries []estypes.Query) (*estypes.Query, *SearchCompileError) {
+	switch models.SearchGuestsLogic(strings.ToUpper(logic)) {
+	case "", models.SearchGuestsLogicAnd:
+		return &estypes.Query{Bool: &estypes.BoolQuery{Filter: queries}}, nil
+	case models.SearchGuestsLogicOr:
+		return &estypes.Query{Bool: &estypes.BoolQuery{Should: queries, MinimumShouldMatch: 1}}, nil
+	default:
+		return nil, &SearchCompileError{Reason: ErrInvalidSearchLogic}
+	}
+}
+
+type searchFieldKind int
+
+const (
+	searchFieldKindKeyword searchFieldKind = iota
+	searchFieldKindNumber
+	searchFieldKindDate
+	searchFieldKindDayOfWeek
+	searchFieldKindBoolean
+	searchFieldKindInStay
+)
+
+type searchField struct {
+	kind searchFieldKind
+	key  func(keys *ScopedMappingKeys) string
+}
+
+var searchFields = map[models.SearchCriteriaField]searchField{
+	// Guest fields.
+	models.SearchCriteriaFieldBirthdate: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Guest.Birthdate },
+	},
+	models.SearchCriteriaFieldCity: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.City },
+	},
+	models.SearchCriteriaFieldCompany: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Company },
+	},
+	models.SearchCriteriaFieldCountry: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Country },
+	},
+	models.SearchCriteriaFieldEmail: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Emails },
+	},
+	models.SearchCriteriaFieldEntityID: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.EntityID },
+	},
+	models.SearchCriteriaFieldGuestTags: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Tags },
+	},
+	models.SearchCriteriaFieldGuestUUID: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.ID },
+	},
+	models.SearchCriteriaFieldLangs: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Languages },
+	},
+	models.SearchCriteriaFieldLastName: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.LastName },
+	},
+	models.SearchCriteriaFieldNationality: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Nationalities },
+	},
+	models.SearchCriteriaFieldOptinMarketing: {
+		searchFieldKindBoolean, func(keys *ScopedMappingKeys) string { return keys.Guest.OptedOutMarketing },
+	},
+	models.SearchCriteriaFieldPhone: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Phones },
+	},
+	models.SearchCriteriaFieldTags: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Tags },
+	},
+	models.SearchCriteriaFieldUpdatedAt: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Guest.UpdatedAt },
+	},
+	models.SearchCriteriaFieldZipCode: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Guest.Zipcode },
+	},
+
+	// Booking fields.
+	models.SearchCriteriaFieldArrival: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Booking.Arrival },
+	},
+	models.SearchCriteriaFieldArrivalDOW: {
+		searchFieldKindDayOfWeek, func(keys *ScopedMappingKeys) string { return keys.Booking.ArrivalDow },
+	},
+	models.SearchCriteriaFieldBalance: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.Balance },
+	},
+	models.SearchCriteriaFieldBookingDate: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Booking.BookingDate },
+	},
+	models.SearchCriteriaFieldBookingID: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.ID },
+	},
+	models.SearchCriteriaFieldBookingStatus: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.Status },
+	},
+	models.SearchCriteriaFieldBookingTags: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.Tags },
+	},
+	models.SearchCriteriaFieldChannel: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.Channel },
+	},
+	models.SearchCriteriaFieldDeparture: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Booking.Departure },
+	},
+	models.SearchCriteriaFieldDepartureDOW: {
+		searchFieldKindDayOfWeek, func(keys *ScopedMappingKeys) string { return keys.Booking.DepartureDow },
+	},
+	models.SearchCriteriaFieldFare: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.Fare },
+	},
+	models.SearchCriteriaFieldFareCode: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.FareCode },
+	},
+	models.SearchCriteriaFieldIDMasterResa: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.PMSBookingParentID },
+	},
+	models.SearchCriteriaFieldIDResa: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.PMSBookingID },
+	},
+	models.SearchCriteriaFieldInStay: {
+		searchFieldKindInStay, nil,
+	},
+	models.SearchCriteriaFieldInStayDate: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Booking.InstayDates },
+	},
+	models.SearchCriteriaFieldPaxAdults: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.PaxAdults },
+	},
+	models.SearchCriteriaFieldPaxBabies: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.PaxBabies },
+	},
+	models.SearchCriteriaFieldPaxChildren: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.PaxChildren },
+	},
+	models.SearchCriteriaFieldRoomNumber: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.Room },
+	},
+	models.SearchCriteriaFieldRoomType: {
+		searchFieldKindKeyword, func(keys *ScopedMappingKeys) string { return keys.Booking.RoomType },
+	},
+	models.SearchCriteriaFieldStayLength: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.StayLength },
+	},
+	models.SearchCriteriaFieldTouristTax: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Booking.TouristTax },
+	},
+	models.SearchCriteriaFieldWeekend: {
+		searchFieldKindBoolean, func(keys *ScopedMappingKeys) string { return keys.Booking.Weekend },
+	},
+
+	// Aggregation fields.
+	models.SearchCriteriaFieldFareAvg: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Aggregations.AvgFares },
+	},
+	models.SearchCriteriaFieldFareSum: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Aggregations.SumFares },
+	},
+	models.SearchCriteriaFieldNbNextStays: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Aggregations.CounterFutureBookings },
+	},
+	models.SearchCriteriaFieldNbPreviousStays: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Aggregations.CounterPastBookings },
+	},
+	models.SearchCriteriaFieldNbStays: {
+		searchFieldKindNumber, func(keys *ScopedMappingKeys) string { return keys.Aggregations.CounterBookings },
+	},
+	models.SearchCriteriaFieldNextStay: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Aggregations.NextArrival },
+	},
+	models.SearchCriteriaFieldPreviousStay: {
+		searchFieldKindDate, func(keys *ScopedMappingKeys) string { return keys.Aggregations.LastDeparture },
+	},
+}
+
+func (c *SearchCompiler) compileCriteria(criteria *models.SearchCriteria) (*estypes.Query, *SearchCompileError) {
+	newError := func(reason error) *SearchCompileError {
+		return &SearchCompileError{Field: criteria.Field, Operator: criteria.Operator, Reason: reason}
+	}
+
+	// The anonymous operators do not depend on the field.
+	switch criteria.Operator {
+	case models.SearchOperatorAnonymous:
+		return newTermQuery(c.keys.Guest.Anonymous, true), nil
+	case models.SearchOperatorNotAnonymous:
+		return newTermQuery(c.keys.Guest.Anonymous, false), nil
+	}
+
+	field, ok := searchFields[criteria.Field]
+	if !ok {
+		return nil, newError(ErrUnsupportedSearchField)
+	}
+
+	key := ""
+	if field.key != nil {
+		key = field.key(c.keys)
+	}
+
+	switch criteria.Operator {
+	case models.SearchOperatorFilled:
+		if field.kind != searchFieldKindBoolean && field.kind != searchFieldKindInStay {
+			return &estypes.Query{Exists: &estypes.ExistsQuery{Field: key}}, nil
+		}
+	case models.SearchOperatorNotFilled:
+		if field.kind != searchFieldKindBoolean && field.kind != searchFieldKindInStay {
+			return newMustNotQuery(estypes.Query{Exists: &estypes.ExistsQuery{Field: key}}), nil
+		}
+	}
+
+	var (
+		result *estypes.Query
+		err    error
+	)
+
+	switch field.kind {
+	case searchFieldKindKeyword:
+		result, err = compileKeywordCriteria(key, criteria)
+	case searchFieldKindNumber:
+		result, err = compileNumberCriteria(key, criteria)
+	case searchFieldKindDate:
+		result, err = c.compileDateCriteria(key, criteria)
+	case searchFieldKindDayOfWeek:
+		result, err = compileDayOfWeekCriteria(key, criteria)
+	case searchFieldKindBoolean:
+		result, err = compileBooleanCriteria(key, criteria)
+	case searchFieldKindInStay:
+		result, err = c.compileInStayCriteria(criteria)
+	}
+
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return result, nil
+}
+
+func compileKeywordCriteria(key string, criteria *models.SearchCriteria) (*estypes.Query, error) {
+	values, ok := models.ParseSearchStrings(criteria.Value)
+	if !ok || len(values) == 0 {
+		return nil, ErrInvalidSearchValue
+	}
+
+	switch criteria.Operator {
+	case models.SearchOperatorEquals:
+		return newTermsQuery(key, values), nil
+	case models.SearchOperatorNotEquals:
+		return newMustNotQuery(*newTermsQuery(key, values)), nil
+	case models.SearchOperatorContains:
+		return newAnyOfQuery(values, func(value string) *estypes.Query { return newContainsQuery(key, value) }), nil
+	case models.SearchOperatorNotContains:
+		return newMustNotQuery(*newAnyOfQuery(values, func(value string) *estypes.Query {
+			return newContainsQuery(key, value)
+		})), nil
+	case models.SearchOperatorStartsWith:
+		return newAnyOfQuery(values, func(value string) *estypes.Query { return newPrefixQuery(key, value) }), nil
+	case models.SearchOperatorNotStartsWith:
+		return newMustNotQuery(*newAnyOfQuery(values, func(value string) *estypes.Query {
+			return newPrefixQuery(key, value)
+		})), nil
+	default:
+		return nil, ErrUnsupportedSearchOperator
+	}
+}
+
+func compileNumberCriteria(key string, criteria *models.SearchCriteria) (*estypes.Query, error) {
+	if criteria.Operator == models.SearchOperatorRange {
+		bounds, ok := models.ParseSearchRange(criteria.Value)
+		if !ok {
+			return nil, ErrInvalidSearchValue
+		}
+
+		from, fromOK := models.ParseSearchNumber(bounds[0])
+		to, toOK := models.ParseSearchNumber(bounds[1])
+
+		if !fromOK || !toOK {
+			return nil, ErrInvalidSearchValue
+		}
+
+		return newNumberRangeQuery(key, estypes.NumberRangeQuery{
+			Gte: pointer.From(estypes.Float64(from)),
+			Lte: pointer.From(estypes.Float64(to)),
+		}), nil
+	}
+
+	value, ok := models.ParseSearchNumber(criteria.Value)
+	if !ok {
+		return nil, ErrInvalidSearchValue
+	}
+
+	switch criteria.Operator {
+	case models.SearchOperatorEquals:
+		return newTermQuery(key, value), nil
+	case models.SearchOperatorNotEquals:
+		return newMustNotQuery(*newTermQuery(key, value)), nil
+	case models.SearchOperatorInferior:
+		return newNumberRangeQuery(key, estypes.NumberRangeQuery{Lt: pointer.From(estypes.Float64(value))}), nil
+	case models.SearchOperatorSuperior:
+		return newNumberRangeQuery(key, estypes.NumberRangeQuery{Gt: pointer.From(estypes.Float64(value))}), nil
+	default:
+		return nil, ErrUnsupportedSearchOperator
+	}
+}
+
+// compileDateCriteria into a range query on whole days. Dates are either formatted as "2006-01-02" or are keywords
+// (e.g. "today") resolved with SearchKeywordDate.Duration.
+func (c *SearchCompiler) compileDateCriteria(key string, criteria *models.SearchCriteria) (*estypes.Query, error) {
+	today := truncateToDay(c.now())
+
+	switch criteria.Operator {
+	case models.SearchOperatorRange:
+		bounds, ok := models.ParseSearchRange(criteria.Value)
+		if !ok {
+			return nil, ErrInvalidSearchValue
+		}
+
+		from, fromOK := models.ParseSearchDate(bounds[0], today)
+		to, toOK := models.ParseSearchDate(bounds[1], today)
+
+		if !fromOK || !toOK {
+			return nil, ErrInvalidSearchValue
+		}
+
+		return newDateRangeQuery(key, &from, pointer.From(to.AddDate(0, 0, 1))), nil
+	case models.SearchOperatorDateAwayMore, models.SearchOperatorDateAwayLess:
+		days, ok := models.ParseSearchNumber(criteria.Value)
+		if !ok {
+			return nil, ErrInvalidSearchValue
+		}
+
+		limit := today.AddDate(0, 0, int(days))
+
+		if criteria.Operator == models.SearchOperatorDateAwayMore {
+			return newDateRangeQuery(key, &limit, nil), nil
+		}
+
+		return newDateRangeQuery(key, &today, &limit), nil
+	case models.SearchOperatorDateAfterThanKeywordDay, models.SearchOperatorDateBeforeThanKeywordDay:
+		if models.ParseSearchKeywordDate(criteria.Value) == models.SearchKeywordDateUnknown {
+			return nil, ErrInvalidSearchValue
+		}
+	}
+
+	day, ok := models.ParseSearchDate(criteria.Value, today)
+	if !ok {
+		return nil, ErrInvalidSearchValue
+	}
+
+	nextDay := day.AddDate(0, 0, 1)
+
+	switch criteria.Operator {
+	case models.SearchOperatorDateEqual:
+		return newDateRangeQuery(key, &day, &nextDay), nil
+	case models.SearchOperatorDateAfterThan, models.SearchOperatorDateAfterThanKeywordDay:
+		return newDateRangeQuery(key, &nextDay, nil), nil
+	case models.SearchOperatorDateAfterThanEqual:
+		return newDateRangeQuery(key, &day, nil), nil
+	case models.SearchOperatorDateBeforeThan, models.SearchOperatorDateBeforeThanKeywordDay:
+		return newDateRangeQuery(key, nil, &day), nil
+	case models.SearchOperatorDateBeforeThanEqual:
+		return newDateRangeQuery(key, nil, &nextDay), nil
+	default:
+		return nil, ErrUnsupportedSearchOperator
+	}
+}
+
+func compileDayOfWeekCriteria(key string, criteria *models.SearchCriteria) (*estypes.Query, error) {
+	if criteria.Operator != models.SearchOperatorDow {
+		return nil, ErrUnsupportedSearchOperator
+	}
+
+	values, ok := models.ParseSearchStrings(criteria.Value)
+	if !ok || len(values) == 0 {
+		return nil, ErrInvalidSearchValue
+	}
+
+	return newTermsQuery(key, values), nil
+}
+
+func compileBooleanCriteria(key string, criteria *models.SearchCriteria) (*estypes.Query, error) {
+	switch criteria.Operator {
+	case models.SearchOperatorYes:
+		return newTermQuery(key, true), nil
+	case models.SearchOperatorNo:
+		return newTermQuery(key, false), nil
+	default:
+		return nil, ErrUnsupportedSearchOperator
+	}
+}
+
+// compileInStayCriteria matches the guests whose booking includes the current day.
+func (c *SearchCompiler) compileInStayCriteria(criteria *models.SearchCriteria) (*estypes.Query, error) {
+	today := truncateToDay(c.now())
+	tomorrow := today.AddDate(0, 0, 1)
+
+	inStayQuery := estypes.Query{Bool: &estypes.BoolQuery{Filter: []estypes.Query{
+		*newDateRangeQuery(c.keys.Booking.Arrival, nil, &tomorrow),
+		*newDateRangeQuery(c.keys.Booking.Departure, &today, nil),
+	}}}
+
+	switch criteria.Operator {
+	case models.SearchOperatorYes:
+		return &inStayQuery, nil
+	case models.SearchOperatorNo:
+		return newMustNotQuery(inStayQuery), nil
+	default:
+		return nil, ErrUnsupportedSearchOperator
+	}
+}
+
+func newTermQuery(key string, value any) *estypes.Query {
+	return &estypes.Query{Term: map[string]estypes.TermQuery{key: {Value: value}}}
+}
+
+func newTermsQuery(key string, values []string) *estypes.Query {
+	if len(values) == 1 {
+		return newTermQuery(key, values[0])
+	}
+
+	fieldValues := []estypes.FieldValue{}
+	for _, value := range values {
+		fieldValues = append(fieldValues, value)
+	}
+
+	return &estypes.Query{Terms: &estypes.TermsQuery{TermsQuery: map[string]estypes.TermsQueryField{key: fieldValues}}}
+}
+
+func newWildcardQuery(key, value string) *estypes.Query {
+	return &estypes.Query{Wildcard: map[string]estypes.WildcardQuery{key: {
+		Value:           &value,
+		CaseInsensitive: pointer.From(true),
+	}}}
+}
+
+// newContainsQuery matching the values containing the given one. Wildcard characters of the value are escaped.
+func newContainsQuery(key, value string) *estypes.Query {
+	return newWildcardQuery(key, "*"+wildcardEscaper.Replace(value)+"*")
+}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
+
+func newPrefixQuery(key, value string) *estypes.Query {
+	return &estypes.Query{Prefix: map[string]estypes.PrefixQuery{key: {
+		Value:           value,
+		CaseInsensitive: pointer.From(true),
+	}}}
+}
+
+func newNumberRangeQuery(key string, query estypes.NumberRangeQuery) *estypes.Query {
+	return &estypes.Query{Range: map[string]estypes.RangeQuery{key: query}}
+}
+
+// newDateRangeQuery matching the dates greater than or equal to from and less than to.
+func newDateRangeQuery(key string, from, to *time.Time) *estypes.Query {
+	query := estypes.DateRangeQuery{Format: pointer.From("yyyy-MM-dd")}
+
+	if from != nil {
+		query.Gte = pointer.From(from.Format(searchDateFormat))
+	}
+
+	if to != nil {
+		query.Lt = pointer.From(to.Format(searchDateFormat))
+	}
+
+	return &estypes.Query{Range: map[string]estypes.RangeQuery{key: query}}
+}
+
+// newAnyOfQuery matching the documents matched by the query of at least one of the given values.
+func newAnyOfQuery(values []string, newQuery func(value string) *estypes.Query) *estypes.Query {
+	if len(values) == 1 {
+		return newQuery(values[0])
+	}
+
+	queries := []estypes.Query{}
+	for _, value := range values {
+		queries = append(queries, *newQuery(value))
+	}
+
+	return &estypes.Query{Bool: &estypes.BoolQuery{Should: queries, MinimumShouldMatch: 1}}
+}
+
+func newMustNotQuery(query estypes.Query) *estypes.Query {
+	return &estypes.Query{Bool: &estypes.BoolQuery{MustNot: []estypes.Query{query}}}
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
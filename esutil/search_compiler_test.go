@@ -0,0 +1,310 @@
+package esutil_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/esutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGoldenFiles = flag.Bool("update", false, "update the golden files")
+
+func TestSearchCompiler(t *testing.T) {
+	compiler, err := esutil.NewSearchCompiler(esutil.MappingKeysScopeAccount,
+		esutil.WithSearchCompilerNow(func() time.Time { return time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC) }),
+	)
+	require.NoError(t, err)
+
+	newConditions := func(logic string, criteria ...*models.SearchCriteria) *models.SearchConditions {
+		return &models.SearchConditions{
+			Logic:    logic,
+			Criteria: []*models.SearchCriterion{{Criteria: criteria}},
+		}
+	}
+
+	tests := map[string]*models.SearchConditions{
+		"anonymous": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldEmail, Operator: models.SearchOperatorAnonymous},
+		),
+		"not_anonymous": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldEmail, Operator: models.SearchOperatorNotAnonymous},
+		),
+		"keyword_equals": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldCity, Operator: models.SearchOperatorEquals, Value: "Paris"},
+		),
+		"keyword_equals_many": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldLangs,
+				Operator: models.SearchOperatorEquals,
+				Value:    []any{"fr", "en"},
+			},
+		),
+		"keyword_not_equals": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldCountry,
+				Operator: models.SearchOperatorNotEquals,
+				Value:    "FR",
+			},
+		),
+		"keyword_contains": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldLastName,
+				Operator: models.SearchOperatorContains,
+				Value:    "dup",
+			},
+		),
+		"keyword_contains_many": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldLastName,
+				Operator: models.SearchOperatorContains,
+				Value:    []any{"dup", `a*b?\`},
+			},
+		),
+		"keyword_not_contains": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldEmail,
+				Operator: models.SearchOperatorNotContains,
+				Value:    "gmail",
+			},
+		),
+		"keyword_starts_with": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldZipCode,
+				Operator: models.SearchOperatorStartsWith,
+				Value:    "75",
+			},
+		),
+		"keyword_not_starts_with_many": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldZipCode,
+				Operator: models.SearchOperatorNotStartsWith,
+				Value:    []any{"75", "92"},
+			},
+		),
+		"keyword_not_starts_with": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldPhone,
+				Operator: models.SearchOperatorNotStartsWith,
+				Value:    "+33",
+			},
+		),
+		"filled": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldCompany, Operator: models.SearchOperatorFilled},
+		),
+		"not_filled": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldBirthdate, Operator: models.SearchOperatorNotFilled},
+		),
+		"number_equals": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldPaxAdults,
+				Operator: models.SearchOperatorEquals,
+				Value:    float64(2),
+			},
+		),
+		"number_not_equals": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldStayLength,
+				Operator: models.SearchOperatorNotEquals,
+				Value:    "1",
+			},
+		),
+		"number_inferior": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldFare, Operator: models.SearchOperatorInferior, Value: 100},
+		),
+		"number_superior": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldNbStays,
+				Operator: models.SearchOperatorSuperior,
+				Value:    3,
+			},
+		),
+		"number_range": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldFareSum,
+				Operator: models.SearchOperatorRange,
+				Value:    []any{float64(100), float64(500)},
+			},
+		),
+		"date_equal": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrival,
+				Operator: models.SearchOperatorDateEqual,
+				Value:    "2024-04-01",
+			},
+		),
+		"date_equal_keyword": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldDeparture,
+				Operator: models.SearchOperatorDateEqual,
+				Value:    "tomorrow",
+			},
+		),
+		"date_after_than": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldBookingDate,
+				Operator: models.SearchOperatorDateAfterThan,
+				Value:    "2024-01-01",
+			},
+		),
+		"date_after_than_equal": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldUpdatedAt,
+				Operator: models.SearchOperatorDateAfterThanEqual,
+				Value:    "2024-01-01T12:00:00Z",
+			},
+		),
+		"date_after_than_keyword_day": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldNextStay,
+				Operator: models.SearchOperatorDateAfterThanKeywordDay,
+				Value:    "today",
+			},
+		),
+		"date_before_than": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldPreviousStay,
+				Operator: models.SearchOperatorDateBeforeThan,
+				Value:    "2023-12-31",
+			},
+		),
+		"date_before_than_equal": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldInStayDate,
+				Operator: models.SearchOperatorDateBeforeThanEqual,
+				Value:    "2023-12-31",
+			},
+		),
+		"date_before_than_keyword_day": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrival,
+				Operator: models.SearchOperatorDateBeforeThanKeywordDay,
+				Value:    "yesterday",
+			},
+		),
+		"date_away_more": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrival,
+				Operator: models.SearchOperatorDateAwayMore,
+				Value:    float64(7),
+			},
+		),
+		"date_away_less": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrival,
+				Operator: models.SearchOperatorDateAwayLess,
+				Value:    "7",
+			},
+		),
+		"date_range": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldDeparture,
+				Operator: models.SearchOperatorRange,
+				Value:    []any{"2024-01-01", "today"},
+			},
+		),
+		"dow": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrivalDOW,
+				Operator: models.SearchOperatorDow,
+				Value:    []any{"6", "7"},
+			},
+		),
+		"yes": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldWeekend, Operator: models.SearchOperatorYes},
+		),
+		"no": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldOptinMarketing, Operator: models.SearchOperatorNo},
+		),
+		"in_stay": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldInStay, Operator: models.SearchOperatorYes},
+		),
+		"not_in_stay": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldInStay, Operator: models.SearchOperatorNo},
+		),
+		"or_logic": {
+			Logic: "OR",
+			Criteria: []*models.SearchCriterion{
+				{Criteria: []*models.SearchCriteria{
+					{Field: models.SearchCriteriaFieldCity, Operator: models.SearchOperatorEquals, Value: "Paris"},
+				}},
+				{Logic: "and", Criteria: []*models.SearchCriteria{
+					{Field: models.SearchCriteriaFieldCity, Operator: models.SearchOperatorEquals, Value: "Lyon"},
+					{Field: models.SearchCriteriaFieldGuestTags, Operator: models.SearchOperatorEquals, Value: "vip"},
+				}},
+			},
+		},
+		"error_unsupported_field": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldSegment, Operator: models.SearchOperatorEquals, Value: "1"},
+		),
+		"error_unsupported_operator": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldEmail,
+				Operator: models.SearchOperatorCampaignOpened,
+				Value:    "1",
+			},
+		),
+		"error_unsupported_operator_for_field": newConditions("",
+			&models.SearchCriteria{Field: models.SearchCriteriaFieldArrival, Operator: models.SearchOperatorContains},
+		),
+		"error_invalid_value": newConditions("",
+			&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldArrival,
+				Operator: models.SearchOperatorDateAfterThanKeywordDay,
+				Value:    "2024-01-01",
+			},
+		),
+		"error_invalid_logic": newConditions("XOR"),
+	}
+
+	for name, conditions := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got any
+
+			query, err := compiler.Compile(conditions)
+			if err != nil {
+				got = map[string]string{"error": err.Error()}
+			} else {
+				got = query
+			}
+
+			encodedGot, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+
+			goldenFilePath := filepath.Join("testdata", "search_compiler", name+".json")
+
+			if *updateGoldenFiles {
+				require.NoError(t, os.MkdirAll(filepath.Dir(goldenFilePath), 0o755))
+				require.NoError(t, os.WriteFile(goldenFilePath, append(encodedGot, '\n'), 0o644)) //nolint:gosec
+			}
+
+			want, err := os.ReadFile(goldenFilePath)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(encodedGot))
+		})
+	}
+}
+
+func TestSearchCompileError(t *testing.T) {
+	compiler, err := esutil.NewSearchCompiler(esutil.MappingKeysScopeChain)
+	require.NoError(t, err)
+
+	_, err = compiler.Compile(&models.SearchConditions{Criteria: []*models.SearchCriterion{
+		{Criteria: []*models.SearchCriteria{
+			{Field: models.SearchCriteriaFieldCity, Operator: models.SearchOperatorEquals, Value: "Paris"},
+			{Field: models.SearchCriteriaFieldMetadata, Operator: models.SearchOperatorEquals, Value: "1"},
+		}},
+	}})
+
+	compileError := &esutil.SearchCompileError{}
+	require.ErrorAs(t, err, &compileError)
+	assert.ErrorIs(t, err, esutil.ErrUnsupportedSearchField)
+	assert.Equal(t, "criteria[0].criteria[1]", compileError.Path)
+	assert.Equal(t, models.SearchCriteriaFieldMetadata, compileError.Field)
+}
@@ -0,0 +1,148 @@
+package esutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+// ShardFailure of a request executed on many shards.
+type ShardFailure struct {
+	Index  string
+	Shard  int
+	Node   string
+	Type   string
+	Reason string
+}
+
+func (f *ShardFailure) String() string {
+	return fmt.Sprintf("[%s][%d] %s: %s", f.Index, f.Shard, f.Type, f.Reason)
+}
+
+// code of the failure, based on its type.
+func (f *ShardFailure) code() string {
+	switch f.Type {
+	case "illegal_argument_exception",
+		"parsing_exception",
+		"query_shard_exception",
+		"search_parse_exception",
+		"x_content_parse_exception":
+		return errors.CodeInvalid
+	case "index_not_found_exception":
+		return errors.CodeNotFound
+	default:
+		return errors.CodeInternal
+	}
+}
+
+// ShardFailuresError contains every shard failure of a request.
+type ShardFailuresError struct {
+	Failures []*ShardFailure
+}
+
+var _ error = (*ShardFailuresError)(nil)
+
+func (e *ShardFailuresError) Error() string {
+	failures := []string{}
+	for _, failure := range e.Failures {
+		failures = append(failures, failure.String())
+	}
+
+	return "could not execute request because of shard failures: " + strings.Join(failures, ", ")
+}
+
+// newShardFailuresError returns nil when there is no failure.
+func newShardFailuresError(failures []types.ShardFailure) *ShardFailuresError {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	result := &ShardFailuresError{}
+
+	for _, failure := range failures {
+		mappedFailure := &ShardFailure{Shard: failure.Shard, Type: failure.Reason.Type}
+
+		if index := failure.Index; index != nil {
+			mappedFailure.Index = *index
+		}
+
+		if node := failure.Node; node != nil {
+			mappedFailure.Node = *node
+		}
+
+		if reason := failure.Reason.Reason; reason != nil {
+			mappedFailure.Reason = *reason
+		}
+
+		result.Failures = append(result.Failures, mappedFailure)
+	}
+
+	return result
+}
+
+// appError wraps the failures with the code shared by all of them, or the internal code when they differ.
+func (e *ShardFailuresError) appError() error {
+	code := e.Failures[0].code()
+
+	for _, failure := range e.Failures[1:] {
+		if failure.code() != code {
+			code = errors.CodeInternal
+
+			break
+		}
+	}
+
+	return &errors.Error{Code: code, UnderlyingError: e}
+}
+
+type partialResultsContextKey struct{}
+
+// PartialResults records the shard failures of the requests executed with a context returned by
+// WithPartialResults.
+type PartialResults struct {
+	mu       sync.Mutex
+	failures []*ShardFailure
+}
+
+// WithPartialResults returns a context accepting partial results: requests on which some shards succeeded do not fail
+// because of the other shards, whose failures are recorded in the returned PartialResults.
+func WithPartialResults(ctx context.Context) (context.Context, *PartialResults) {
+	result := &PartialResults{}
+
+	return context.WithValue(ctx, partialResultsContextKey{}, result), result
+}
+
+// Failures recorded so far.
+func (r *PartialResults) Failures() []*ShardFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*ShardFailure{}, r.failures...)
+}
+
+// Err returns a ShardFailuresError if any failure was recorded, or nil.
+func (r *PartialResults) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &ShardFailuresError{Failures: failures}
+}
+
+func (r *PartialResults) add(failures ...*ShardFailure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = append(r.failures, failures...)
+}
+
+func partialResultsFromContext(ctx context.Context) *PartialResults {
+	result, _ := ctx.Value(partialResultsContextKey{}).(*PartialResults)
+
+	return result
+}
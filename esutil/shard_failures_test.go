@@ -0,0 +1,118 @@
+package esutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTransportShardFailures(t *testing.T) {
+	const (
+		queryShardFailure = `{"index": "a", "shard": 0, "node": "n1", "reason": {"type": "query_shard_exception", "reason": "failed to create query"}}`
+		parsingFailure    = `{"index": "b", "shard": 1, "node": "n2", "reason": {"type": "parsing_exception", "reason": "unknown field"}}`
+		timeoutFailure    = `{"index": "b", "shard": 2, "node": "n2", "reason": {"type": "timeout_exception", "reason": "timed out"}}`
+	)
+
+	tests := map[string]struct {
+		body         string
+		wantCode     string
+		wantFailures []*ShardFailure
+	}{
+		"no failures": {
+			body: `{"_shards": {"total": 2, "successful": 2, "failed": 0}}`,
+		},
+		"invalid failures": {
+			body:     `{"_shards": {"total": 3, "successful": 1, "failed": 2, "failures": [` + queryShardFailure + `,` + parsingFailure + `]}}`,
+			wantCode: errors.CodeInvalid,
+			wantFailures: []*ShardFailure{
+				{Index: "a", Shard: 0, Node: "n1", Type: "query_shard_exception", Reason: "failed to create query"},
+				{Index: "b", Shard: 1, Node: "n2", Type: "parsing_exception", Reason: "unknown field"},
+			},
+		},
+		"mixed failures": {
+			body:     `{"_shards": {"total": 3, "successful": 1, "failed": 2, "failures": [` + queryShardFailure + `,` + timeoutFailure + `]}}`,
+			wantCode: errors.CodeInternal,
+			wantFailures: []*ShardFailure{
+				{Index: "a", Shard: 0, Node: "n1", Type: "query_shard_exception", Reason: "failed to create query"},
+				{Index: "b", Shard: 2, Node: "n2", Type: "timeout_exception", Reason: "timed out"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			transport := &clientTransport{baseTransport: http.DefaultTransport}
+
+			t.Run("without partial results", func(t *testing.T) {
+				response, err := transport.RoundTrip(newSearchRequest(t, context.Background(), server.URL+"/a,b/_search"))
+				require.NotNil(t, response)
+				require.NoError(t, response.Body.Close())
+
+				if tt.wantCode == "" {
+					assert.NoError(t, err)
+
+					return
+				}
+
+				assert.Equal(t, tt.wantCode, errors.ErrorCode(err))
+
+				shardFailuresError := &ShardFailuresError{}
+				require.ErrorAs(t, err.(*errors.Error).UnderlyingError, &shardFailuresError)
+				assert.Equal(t, tt.wantFailures, shardFailuresError.Failures)
+			})
+
+			t.Run("with partial results", func(t *testing.T) {
+				ctx, partialResults := WithPartialResults(context.Background())
+
+				response, err := transport.RoundTrip(newSearchRequest(t, ctx, server.URL+"/a,b/_search"))
+				require.NoError(t, err)
+				require.NoError(t, response.Body.Close())
+
+				if tt.wantCode == "" {
+					assert.NoError(t, partialResults.Err())
+
+					return
+				}
+
+				assert.Equal(t, tt.wantFailures, partialResults.Failures())
+				assert.ErrorContains(t, partialResults.Err(), "shard failures")
+			})
+		})
+	}
+
+	t.Run("every shard failed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"_shards": {"total": 1, "successful": 0, "failed": 1, "failures": [` + queryShardFailure + `]}}`))
+		}))
+		defer server.Close()
+
+		ctx, partialResults := WithPartialResults(context.Background())
+
+		response, err := (&clientTransport{baseTransport: http.DefaultTransport}).RoundTrip(
+			newSearchRequest(t, ctx, server.URL+"/a/_search"),
+		)
+		require.NoError(t, response.Body.Close())
+		assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+		assert.Empty(t, partialResults.Failures())
+	})
+}
+
+//nolint:revive
+func newSearchRequest(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+
+	result, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	require.NoError(t, err)
+
+	return result
+}
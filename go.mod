@@ -3,6 +3,7 @@ module github.com/loungeup/go-loungeup
 go 1.24.0
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/DataDog/gostackparse v0.7.0
 	github.com/SparkPost/gosparkpost v0.2.0
 	github.com/cenkalti/backoff/v4 v4.3.0
@@ -14,6 +15,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/jirenius/go-res v0.5.0
 	github.com/lib/pq v1.10.9
+	github.com/nats-io/nats-server/v2 v2.9.22
 	github.com/nats-io/nats.go v1.37.0
 	github.com/stretchr/testify v1.10.0
 	github.com/tidwall/gjson v1.17.1
@@ -41,7 +43,6 @@ require (
 	github.com/minio/highwayhash v1.0.2 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
 	github.com/nats-io/jwt/v2 v2.5.0 // indirect
-	github.com/nats-io/nats-server/v2 v2.9.22 // indirect
 	github.com/nats-io/nkeys v0.4.7 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -0,0 +1,283 @@
+package jetstreamutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/log"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const defaultSchemaVersion = 1
+
+// Envelope wrapping the payload of every typed message.
+type Envelope[T any] struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	TraceID       string    `json:"traceId,omitempty"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Payload       T         `json:"payload"`
+}
+
+// Errors returned when decoding an envelope. They can be checked with errors.Is on a DecodeError.
+var (
+	ErrMalformedEnvelope        = errors.New("malformed envelope")
+	ErrMalformedPayload         = errors.New("malformed payload")
+	ErrUnexpectedMessageType    = errors.New("unexpected message type")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+)
+
+// DecodeError is returned when a message cannot be decoded into an envelope.
+type DecodeError struct {
+	// Reason is one of the ErrMalformedEnvelope, ErrMalformedPayload, ErrUnexpectedMessageType or
+	// ErrUnsupportedSchemaVersion errors.
+	Reason error
+
+	// UnderlyingError that caused this error, if any.
+	UnderlyingError error
+}
+
+func (e *DecodeError) Error() string {
+	if e.UnderlyingError != nil {
+		return e.Reason.Error() + ": " + e.UnderlyingError.Error()
+	}
+
+	return e.Reason.Error()
+}
+
+func (e *DecodeError) Unwrap() []error { return []error{e.Reason, e.UnderlyingError} }
+
+// DecodeEnvelope from the given data. The type of the envelope must match the given one unless it is empty, and its
+// schema version must not be greater than the given one.
+func DecodeEnvelope[T any](data []byte, messageType string, maxSchemaVersion int) (*Envelope[T], error) {
+	model := &Envelope[json.RawMessage]{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, &DecodeError{Reason: ErrMalformedEnvelope, UnderlyingError: err}
+	}
+
+	if model.ID == "" || model.Type == "" {
+		return nil, &DecodeError{
+			Reason:          ErrMalformedEnvelope,
+			UnderlyingError: fmt.Errorf("missing ID or type"),
+		}
+	}
+
+	if messageType != "" && model.Type != messageType {
+		return nil, &DecodeError{
+			Reason:          ErrUnexpectedMessageType,
+			UnderlyingError: fmt.Errorf("got %q, want %q", model.Type, messageType),
+		}
+	}
+
+	if model.SchemaVersion > maxSchemaVersion {
+		return nil, &DecodeError{
+			Reason:          ErrUnsupportedSchemaVersion,
+			UnderlyingError: fmt.Errorf("got %d, max %d", model.SchemaVersion, maxSchemaVersion),
+		}
+	}
+
+	result := &Envelope[T]{
+		ID:            model.ID,
+		Type:          model.Type,
+		OccurredAt:    model.OccurredAt,
+		TraceID:       model.TraceID,
+		SchemaVersion: model.SchemaVersion,
+	}
+
+	if err := json.Unmarshal(model.Payload, &result.Payload); err != nil {
+		return nil, &DecodeError{Reason: ErrMalformedPayload, UnderlyingError: err}
+	}
+
+	return result, nil
+}
+
+// msgPublisher is the subset of the jetstream.JetStream interface used by publishers.
+type msgPublisher interface {
+	PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+// Publisher of typed messages on a JetStream subject.
+type Publisher[T any] struct {
+	js            msgPublisher
+	subject       string
+	messageType   string
+	schemaVersion int
+}
+
+// NewPublisher creates a publisher of messages of the given type on the given subject.
+func NewPublisher[T any](
+	js msgPublisher,
+	subject, messageType string,
+	options ...publisherOption,
+) *Publisher[T] {
+	config := &publisherConfig{schemaVersion: defaultSchemaVersion}
+	for _, option := range options {
+		option(config)
+	}
+
+	return &Publisher[T]{
+		js:            js,
+		subject:       subject,
+		messageType:   messageType,
+		schemaVersion: config.schemaVersion,
+	}
+}
+
+type publisherConfig struct {
+	schemaVersion int
+}
+
+type publisherOption func(*publisherConfig)
+
+// WithPublisherSchemaVersion sets the schema version of the published envelopes.
+func WithPublisherSchemaVersion(version int) publisherOption {
+	return func(config *publisherConfig) { config.schemaVersion = version }
+}
+
+// Publish the given payload wrapped in an envelope. The ID of the envelope is sent in the Nats-Msg-Id header so
+// JetStream can detect duplicates.
+func (p *Publisher[T]) Publish(ctx context.Context, payload T, options ...publishOption) (*Envelope[T], error) {
+	config := &publishConfig{
+		id:         uuid.NewString(),
+		traceID:    uuid.NewString(),
+		occurredAt: time.Now(),
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	result := &Envelope[T]{
+		ID:            config.id,
+		Type:          p.messageType,
+		OccurredAt:    config.occurredAt.UTC(),
+		TraceID:       config.traceID,
+		SchemaVersion: p.schemaVersion,
+		Payload:       payload,
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode envelope: %w", err)
+	}
+
+	if _, err := p.js.PublishMsg(ctx, &nats.Msg{
+		Subject: p.subject,
+		Data:    data,
+		Header:  nats.Header{jetstream.MsgIDHeader: []string{result.ID}},
+	}); err != nil {
+		return nil, fmt.Errorf("could not publish message: %w", err)
+	}
+
+	return result, nil
+}
+
+type publishConfig struct {
+	id         string
+	traceID    string
+	occurredAt time.Time
+}
+
+type publishOption func(*publishConfig)
+
+// WithPublishID sets the ID of the envelope. Use it with a deterministic value to deduplicate messages.
+func WithPublishID(id string) publishOption {
+	return func(config *publishConfig) { config.id = id }
+}
+
+// WithPublishTraceID sets the trace ID of the envelope, e.g. to propagate the one of an incoming request.
+func WithPublishTraceID(traceID string) publishOption {
+	return func(config *publishConfig) { config.traceID = traceID }
+}
+
+// WithPublishOccurredAt sets the time at which the published event occurred.
+func WithPublishOccurredAt(occurredAt time.Time) publishOption {
+	return func(config *publishConfig) { config.occurredAt = occurredAt }
+}
+
+// Handler of typed messages. Messages that cannot be decoded are terminated with the reason.
+type Handler[T any] struct {
+	messageType      string
+	maxSchemaVersion int
+	handleFunc       func(envelope *Envelope[T]) error
+	logger           *log.Logger
+}
+
+// NewHandler creates a handler of messages of the given type. The message is acknowledged when the given function
+// succeeds, and negatively acknowledged otherwise so it is redelivered.
+func NewHandler[T any](
+	messageType string,
+	handleFunc func(envelope *Envelope[T]) error,
+	options ...handlerOption,
+) *Handler[T] {
+	config := &handlerConfig{
+		maxSchemaVersion: defaultSchemaVersion,
+		logger:           log.Default(),
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	return &Handler[T]{
+		messageType:      messageType,
+		maxSchemaVersion: config.maxSchemaVersion,
+		handleFunc:       handleFunc,
+		logger:           config.logger,
+	}
+}
+
+type handlerConfig struct {
+	maxSchemaVersion int
+	logger           *log.Logger
+}
+
+type handlerOption func(*handlerConfig)
+
+// WithHandlerMaxSchemaVersion sets the greatest schema version supported by the handler.
+func WithHandlerMaxSchemaVersion(version int) handlerOption {
+	return func(config *handlerConfig) { config.maxSchemaVersion = version }
+}
+
+// WithHandlerLogger sets the logger of the handler, which logs the poison and failed messages. It defaults to
+// log.Default().
+func WithHandlerLogger(logger *log.Logger) handlerOption {
+	return func(config *handlerConfig) { config.logger = logger }
+}
+
+// Handle the given message. It can be used with Consume or Throttler.Handle.
+func (h *Handler[T]) Handle(msg jetstream.Msg) {
+	envelope, err := DecodeEnvelope[T](msg.Data(), h.messageType, h.maxSchemaVersion)
+	if err != nil {
+		h.logger.Error("Terminating poison message",
+			slog.String("error", err.Error()),
+			slog.String("subject", msg.Subject()),
+		)
+
+		_ = msg.TermWithReason(err.Error())
+
+		return
+	}
+
+	l1 := h.logger.With(
+		slog.String("id", envelope.ID),
+		slog.String("subject", msg.Subject()),
+		slog.String("traceId", envelope.TraceID),
+		slog.String("type", envelope.Type),
+	)
+
+	if err := h.handleFunc(envelope); err != nil {
+		l1.Error("Could not handle message", slog.String("error", err.Error()))
+
+		_ = msg.Nak()
+
+		return
+	}
+
+	_ = msg.Ack()
+}
@@ -0,0 +1,162 @@
+package jetstreamutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Name string `json:"name"`
+}
+
+func TestPublisherPublish(t *testing.T) {
+	js := &msgPublisherMock{}
+	occurredAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	envelope, err := NewPublisher[*testPayload](js, "stream.default.test", "test.created").Publish(
+		context.Background(),
+		&testPayload{Name: "foo"},
+		WithPublishID("1"),
+		WithPublishTraceID("2"),
+		WithPublishOccurredAt(occurredAt),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Envelope[*testPayload]{
+		ID:            "1",
+		Type:          "test.created",
+		OccurredAt:    occurredAt,
+		TraceID:       "2",
+		SchemaVersion: defaultSchemaVersion,
+		Payload:       &testPayload{Name: "foo"},
+	}, envelope)
+
+	require.Len(t, js.msgs, 1)
+	assert.Equal(t, "stream.default.test", js.msgs[0].Subject)
+	assert.Equal(t, "1", js.msgs[0].Header.Get(jetstream.MsgIDHeader))
+	assert.JSONEq(t, `{
+		"id": "1",
+		"type": "test.created",
+		"occurredAt": "2024-01-02T03:04:05Z",
+		"traceId": "2",
+		"schemaVersion": 1,
+		"payload": {"name": "foo"}
+	}`, string(js.msgs[0].Data))
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	tests := map[string]struct {
+		in         string
+		want       *Envelope[*testPayload]
+		wantReason error
+	}{
+		"valid": {
+			in: `{"id":"1","type":"test.created","schemaVersion":1,"payload":{"name":"foo"}}`,
+			want: &Envelope[*testPayload]{
+				ID:            "1",
+				Type:          "test.created",
+				SchemaVersion: 1,
+				Payload:       &testPayload{Name: "foo"},
+			},
+		},
+		"malformed envelope": {
+			in:         `not json`,
+			wantReason: ErrMalformedEnvelope,
+		},
+		"missing ID": {
+			in:         `{"type":"test.created","payload":{}}`,
+			wantReason: ErrMalformedEnvelope,
+		},
+		"unexpected type": {
+			in:         `{"id":"1","type":"test.deleted","payload":{}}`,
+			wantReason: ErrUnexpectedMessageType,
+		},
+		"unsupported schema version": {
+			in:         `{"id":"1","type":"test.created","schemaVersion":2,"payload":{}}`,
+			wantReason: ErrUnsupportedSchemaVersion,
+		},
+		"malformed payload": {
+			in:         `{"id":"1","type":"test.created","payload":{"name":1}}`,
+			wantReason: ErrMalformedPayload,
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, err := DecodeEnvelope[*testPayload]([]byte(tt.in), "test.created", 1)
+			if tt.wantReason != nil {
+				decodeError := &DecodeError{}
+				require.True(t, errors.As(err, &decodeError))
+				assert.ErrorIs(t, err, tt.wantReason)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandlerHandle(t *testing.T) {
+	handleFunc := func(envelope *Envelope[*testPayload]) error {
+		if envelope.Payload.Name == "fail" {
+			return errors.New("failure")
+		}
+
+		return nil
+	}
+
+	tests := map[string]struct {
+		in             string
+		wantAckCount   int
+		wantNakCount   int
+		wantTermReason string
+	}{
+		"ack": {
+			in:           `{"id":"1","type":"test.created","payload":{"name":"foo"}}`,
+			wantAckCount: 1,
+		},
+		"nak": {
+			in:           `{"id":"1","type":"test.created","payload":{"name":"fail"}}`,
+			wantNakCount: 1,
+		},
+		"term": {
+			in:             `{"id":"1","type":"test.deleted","payload":{}}`,
+			wantTermReason: `unexpected message type: got "test.deleted", want "test.created"`,
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			msg := &msgMock{data: []byte(tt.in)}
+
+			NewHandler("test.created", handleFunc).Handle(msg)
+
+			assert.Equal(t, tt.wantAckCount, msg.ackCount)
+			assert.Equal(t, tt.wantNakCount, msg.nakCount)
+			assert.Equal(t, tt.wantTermReason, msg.termReason)
+		})
+	}
+}
+
+type msgPublisherMock struct{ msgs []*nats.Msg }
+
+var _ (msgPublisher) = (*msgPublisherMock)(nil)
+
+func (m *msgPublisherMock) PublishMsg(
+	_ context.Context,
+	msg *nats.Msg,
+	_ ...jetstream.PublishOpt,
+) (*jetstream.PubAck, error) {
+	m.msgs = append(m.msgs, msg)
+
+	return &jetstream.PubAck{}, nil
+}
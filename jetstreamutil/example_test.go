@@ -36,3 +36,32 @@ func Example() {
 		panic(err)
 	}
 }
+
+func ExampleEnsure() {
+	js, err := jetstream.New(nil) // Create a JetStream context as usual.
+	if err != nil {
+		panic(err)
+	}
+
+	// Declare the topology next to the code using it.
+	topology := &jetstreamutil.Topology{
+		Streams: []*jetstreamutil.StreamSpec{
+			jetstreamutil.NewDefaultStreamSpec(
+				&jetstreamutil.ConsumerSpec{Name: "test", FilterSubject: "stream.default.test"},
+			),
+		},
+		KeyValues: []*jetstreamutil.KeyValueSpec{
+			{Bucket: "tasks", TTL: time.Hour},
+		},
+	}
+
+	// Reconcile it at startup.
+	report, err := jetstreamutil.Ensure(js, context.Background(), topology)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, change := range report.Changes {
+		_ = change // Log the change.
+	}
+}
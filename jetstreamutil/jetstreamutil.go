@@ -24,17 +24,25 @@ func GetOrCreateDefaultStream(js jetstream.JetStream, ctx context.Context) (jets
 }
 
 func createDefaultStream(js jetstream.JetStream, ctx context.Context) (jetstream.Stream, error) {
+	return js.CreateStream(ctx, NewDefaultStreamSpec().config())
+}
+
+// NewDefaultStreamSpec creates the spec of the default stream with the given consumers.
+func NewDefaultStreamSpec(consumers ...*ConsumerSpec) *StreamSpec {
 	const (
 		streamSubject    = "stream.default.>"
 		streamDuplicates = 100 * time.Millisecond
 	)
 
-	return js.CreateStream(ctx, jetstream.StreamConfig{
+	retention := jetstream.WorkQueuePolicy
+
+	return &StreamSpec{
 		Name:       defaultStreamName,
 		Subjects:   []string{streamSubject},
-		Retention:  jetstream.WorkQueuePolicy,
+		Retention:  &retention,
 		Duplicates: streamDuplicates,
-	})
+		Consumers:  consumers,
+	}
 }
 
 // NewConsumerConfigWithDefaults creates a new consumer config with default values.
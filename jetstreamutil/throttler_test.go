@@ -62,6 +62,8 @@ type msgMock struct {
 
 	ackCount        int
 	inProgressCount int
+	nakCount        int
+	termReason      string
 }
 
 var _ (jetstream.Msg) = (*msgMock)(nil)
@@ -72,9 +74,9 @@ func (m *msgMock) DoubleAck(context.Context) error           { return nil }
 func (m *msgMock) Headers() nats.Header                      { return nil }
 func (m *msgMock) InProgress() error                         { m.inProgressCount++; return nil }
 func (m *msgMock) Metadata() (*jetstream.MsgMetadata, error) { return nil, nil }
-func (m *msgMock) Nak() error                                { return nil }
+func (m *msgMock) Nak() error                                { m.nakCount++; return nil }
 func (m *msgMock) NakWithDelay(delay time.Duration) error    { return nil }
 func (m *msgMock) Reply() string                             { return "" }
 func (m *msgMock) Subject() string                           { return m.subject }
 func (m *msgMock) Term() error                               { return nil }
-func (m *msgMock) TermWithReason(reason string) error        { return nil }
+func (m *msgMock) TermWithReason(reason string) error        { m.termReason = reason; return nil }
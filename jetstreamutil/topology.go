@@ -0,0 +1,389 @@
+package jetstreamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Topology declares the JetStream streams, consumers and key-value buckets used by a service. Services are expected to
+// declare their topology next to the code using it and to reconcile it at startup with Ensure.
+type Topology struct {
+	Streams   []*StreamSpec
+	KeyValues []*KeyValueSpec
+}
+
+// MergeTopologies into a single topology. It allows each package of a service to declare its own part of the topology.
+func MergeTopologies(topologies ...*Topology) *Topology {
+	result := &Topology{}
+	for _, topology := range topologies {
+		if topology == nil {
+			continue
+		}
+
+		result.Streams = append(result.Streams, topology.Streams...)
+		result.KeyValues = append(result.KeyValues, topology.KeyValues...)
+	}
+
+	return result
+}
+
+// StreamSpec declares a stream and its consumers. Zero values are left to the JetStream defaults.
+type StreamSpec struct {
+	Name     string
+	Subjects []string
+
+	// Retention of the stream, if set. JetStream does not allow to change the retention of an existing stream, so a
+	// spec leaving it unset keeps the current one.
+	Retention *jetstream.RetentionPolicy
+
+	Replicas   int
+	MaxAge     time.Duration
+	Duplicates time.Duration
+	Consumers  []*ConsumerSpec
+}
+
+func (spec *StreamSpec) config() jetstream.StreamConfig {
+	return spec.applyTo(jetstream.StreamConfig{})
+}
+
+// applyTo the given config the fields managed by the spec.
+func (spec *StreamSpec) applyTo(config jetstream.StreamConfig) jetstream.StreamConfig {
+	config.Name = spec.Name
+	config.Subjects = spec.Subjects
+
+	if spec.Retention != nil {
+		config.Retention = *spec.Retention
+	}
+
+	if spec.Replicas > 0 {
+		config.Replicas = spec.Replicas
+	}
+
+	if spec.MaxAge > 0 {
+		config.MaxAge = spec.MaxAge
+	}
+
+	if spec.Duplicates > 0 {
+		config.Duplicates = spec.Duplicates
+	}
+
+	return config
+}
+
+// ConsumerSpec declares a durable consumer. Zero values fallback to the ones of NewConsumerConfigWithDefaults.
+type ConsumerSpec struct {
+	Name          string
+	FilterSubject string
+	AckWait       time.Duration
+	MaxDeliver    int
+	MaxAckPending int
+}
+
+func (spec *ConsumerSpec) config() jetstream.ConsumerConfig {
+	return spec.applyTo(NewConsumerConfigWithDefaults(spec.Name, spec.FilterSubject))
+}
+
+// applyTo the given config the fields managed by the spec.
+func (spec *ConsumerSpec) applyTo(config jetstream.ConsumerConfig) jetstream.ConsumerConfig {
+	defaults := NewConsumerConfigWithDefaults(spec.Name, spec.FilterSubject)
+
+	config.Name = spec.Name
+	config.Durable = spec.Name
+	config.FilterSubject = spec.FilterSubject
+	config.AckWait = defaults.AckWait
+	config.MaxDeliver = defaults.MaxDeliver
+
+	if spec.AckWait > 0 {
+		config.AckWait = spec.AckWait
+	}
+
+	if spec.MaxDeliver != 0 {
+		config.MaxDeliver = spec.MaxDeliver
+	}
+
+	if spec.MaxAckPending > 0 {
+		config.MaxAckPending = spec.MaxAckPending
+	}
+
+	return config
+}
+
+// KeyValueSpec declares a key-value bucket. Zero values are left to the JetStream defaults.
+type KeyValueSpec struct {
+	Bucket   string
+	History  uint8
+	TTL      time.Duration
+	Replicas int
+}
+
+func (spec *KeyValueSpec) config() jetstream.KeyValueConfig {
+	return spec.applyTo(jetstream.KeyValueConfig{})
+}
+
+// applyTo the given config the fields managed by the spec.
+func (spec *KeyValueSpec) applyTo(config jetstream.KeyValueConfig) jetstream.KeyValueConfig {
+	config.Bucket = spec.Bucket
+
+	if spec.History > 0 {
+		config.History = spec.History
+	}
+
+	if spec.TTL > 0 {
+		config.TTL = spec.TTL
+	}
+
+	if spec.Replicas > 0 {
+		config.Replicas = spec.Replicas
+	}
+
+	return config
+}
+
+// TopologyAction performed on a JetStream resource by Ensure.
+type TopologyAction string
+
+const (
+	TopologyActionCreated TopologyAction = "created"
+	TopologyActionUpdated TopologyAction = "updated"
+)
+
+// TopologyChange describes a change applied to a JetStream resource by Ensure.
+type TopologyChange struct {
+	// Resource that has been changed (e.g. "stream default", "consumer default/test" or "keyValue tasks").
+	Resource    string
+	Action      TopologyAction
+	Differences []*TopologyDifference
+}
+
+// TopologyDifference between the current and the desired value of a config field.
+type TopologyDifference struct {
+	Field   string
+	Current any
+	Desired any
+}
+
+func (d *TopologyDifference) String() string {
+	return fmt.Sprintf("%s: %v -> %v", d.Field, d.Current, d.Desired)
+}
+
+// EnsureReport lists the changes applied by Ensure. It is empty when the topology was already up to date.
+type EnsureReport struct {
+	Changes []*TopologyChange
+}
+
+func (r *EnsureReport) add(resource string, action TopologyAction, differences []*TopologyDifference) {
+	r.Changes = append(r.Changes, &TopologyChange{Resource: resource, Action: action, Differences: differences})
+}
+
+// Ensure reconciles the given topology: missing resources are created and resources whose config drifted from the
+// spec are updated. Calling it several times with the same topology is a no-op.
+func Ensure(js jetstream.JetStream, ctx context.Context, topology *Topology) (*EnsureReport, error) {
+	result := &EnsureReport{}
+
+	for _, spec := range topology.Streams {
+		stream, err := ensureStream(js, ctx, spec, result)
+		if err != nil {
+			return result, fmt.Errorf("could not ensure stream %s: %w", spec.Name, err)
+		}
+
+		for _, consumerSpec := range spec.Consumers {
+			if err := ensureConsumer(stream, ctx, spec.Name, consumerSpec, result); err != nil {
+				return result, fmt.Errorf("could not ensure consumer %s/%s: %w", spec.Name, consumerSpec.Name, err)
+			}
+		}
+	}
+
+	for _, spec := range topology.KeyValues {
+		if err := ensureKeyValue(js, ctx, spec, result); err != nil {
+			return result, fmt.Errorf("could not ensure key-value bucket %s: %w", spec.Bucket, err)
+		}
+	}
+
+	return result, nil
+}
+
+func ensureStream(
+	js jetstream.JetStream,
+	ctx context.Context,
+	spec *StreamSpec,
+	report *EnsureReport,
+) (jetstream.Stream, error) {
+	resource := "stream " + spec.Name
+
+	stream, err := js.Stream(ctx, spec.Name)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		result, err := js.CreateStream(ctx, spec.config())
+		if err != nil {
+			return nil, err
+		}
+
+		report.add(resource, TopologyActionCreated, nil)
+
+		return result, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	current := stream.CachedInfo().Config
+
+	differences := diffStreamConfigs(current, spec.applyTo(current))
+	if len(differences) == 0 {
+		return stream, nil
+	}
+
+	result, err := js.UpdateStream(ctx, spec.applyTo(current))
+	if err != nil {
+		return nil, err
+	}
+
+	report.add(resource, TopologyActionUpdated, differences)
+
+	return result, nil
+}
+
+func ensureConsumer(
+	stream jetstream.Stream,
+	ctx context.Context,
+	streamName string,
+	spec *ConsumerSpec,
+	report *EnsureReport,
+) error {
+	resource := "consumer " + streamName + "/" + spec.Name
+
+	consumer, err := stream.Consumer(ctx, spec.Name)
+	if errors.Is(err, jetstream.ErrConsumerNotFound) {
+		if _, err := stream.CreateConsumer(ctx, spec.config()); err != nil {
+			return err
+		}
+
+		report.add(resource, TopologyActionCreated, nil)
+
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	current := consumer.CachedInfo().Config
+
+	differences := diffConsumerConfigs(current, spec.applyTo(current))
+	if len(differences) == 0 {
+		return nil
+	}
+
+	if _, err := stream.UpdateConsumer(ctx, spec.applyTo(current)); err != nil {
+		return err
+	}
+
+	report.add(resource, TopologyActionUpdated, differences)
+
+	return nil
+}
+
+func ensureKeyValue(js jetstream.JetStream, ctx context.Context, spec *KeyValueSpec, report *EnsureReport) error {
+	resource := "keyValue " + spec.Bucket
+
+	store, err := js.KeyValue(ctx, spec.Bucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		if _, err := js.CreateKeyValue(ctx, spec.config()); err != nil {
+			return err
+		}
+
+		report.add(resource, TopologyActionCreated, nil)
+
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	status, err := store.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("could not read status: %w", err)
+	}
+
+	current := mapKeyValueStatusToConfig(status)
+
+	differences := diffKeyValueConfigs(current, spec.applyTo(current))
+	if len(differences) == 0 {
+		return nil
+	}
+
+	if _, err := js.UpdateKeyValue(ctx, spec.applyTo(current)); err != nil {
+		return err
+	}
+
+	report.add(resource, TopologyActionUpdated, differences)
+
+	return nil
+}
+
+func mapKeyValueStatusToConfig(status jetstream.KeyValueStatus) jetstream.KeyValueConfig {
+	result := jetstream.KeyValueConfig{
+		Bucket:  status.Bucket(),
+		History: uint8(status.History()), //nolint:gosec
+		TTL:     status.TTL(),
+	}
+
+	if status, ok := status.(*jetstream.KeyValueBucketStatus); ok {
+		config := status.StreamInfo().Config
+
+		result.Description = config.Description
+		result.MaxValueSize = config.MaxMsgSize
+		result.MaxBytes = config.MaxBytes
+		result.Storage = config.Storage
+		result.Replicas = config.Replicas
+		result.Placement = config.Placement
+		result.RePublish = config.RePublish
+		result.Compression = config.Compression != jetstream.NoCompression
+	}
+
+	return result
+}
+
+func diffStreamConfigs(current, desired jetstream.StreamConfig) []*TopologyDifference {
+	result := []*TopologyDifference{}
+	result = appendDifference(result, "subjects", current.Subjects, desired.Subjects)
+	result = appendDifference(result, "retention", current.Retention, desired.Retention)
+	result = appendDifference(result, "replicas", current.Replicas, desired.Replicas)
+	result = appendDifference(result, "maxAge", current.MaxAge, desired.MaxAge)
+	result = appendDifference(result, "duplicates", current.Duplicates, desired.Duplicates)
+
+	return result
+}
+
+func diffConsumerConfigs(current, desired jetstream.ConsumerConfig) []*TopologyDifference {
+	result := []*TopologyDifference{}
+	result = appendDifference(result, "filterSubject", current.FilterSubject, desired.FilterSubject)
+	result = appendDifference(result, "ackWait", current.AckWait, desired.AckWait)
+	result = appendDifference(result, "maxDeliver", current.MaxDeliver, desired.MaxDeliver)
+	result = appendDifference(result, "maxAckPending", current.MaxAckPending, desired.MaxAckPending)
+
+	return result
+}
+
+func diffKeyValueConfigs(current, desired jetstream.KeyValueConfig) []*TopologyDifference {
+	result := []*TopologyDifference{}
+	result = appendDifference(result, "history", current.History, desired.History)
+	result = appendDifference(result, "ttl", current.TTL, desired.TTL)
+	result = appendDifference(result, "replicas", current.Replicas, desired.Replicas)
+
+	return result
+}
+
+func appendDifference(differences []*TopologyDifference, field string, current, desired any) []*TopologyDifference {
+	if currentSubjects, ok := current.([]string); ok {
+		desiredSubjects, _ := desired.([]string)
+		if slices.Equal(currentSubjects, desiredSubjects) {
+			return differences
+		}
+	} else if reflect.DeepEqual(current, desired) {
+		return differences
+	}
+
+	return append(differences, &TopologyDifference{Field: field, Current: current, Desired: desired})
+}
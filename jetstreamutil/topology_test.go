@@ -0,0 +1,194 @@
+package jetstreamutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	nats "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTopologies(t *testing.T) {
+	got := MergeTopologies(
+		&Topology{Streams: []*StreamSpec{{Name: "a"}}},
+		nil,
+		&Topology{KeyValues: []*KeyValueSpec{{Bucket: "b"}}},
+	)
+
+	assert.Equal(t, &Topology{
+		Streams:   []*StreamSpec{{Name: "a"}},
+		KeyValues: []*KeyValueSpec{{Bucket: "b"}},
+	}, got)
+}
+
+func TestStreamSpecApplyTo(t *testing.T) {
+	current := jetstream.StreamConfig{
+		Name:        defaultStreamName,
+		Description: "Unmanaged",
+		Subjects:    []string{"stream.default.>"},
+		Retention:   jetstream.WorkQueuePolicy,
+		Replicas:    1,
+		Duplicates:  100 * time.Millisecond,
+	}
+
+	tests := map[string]struct {
+		in   *StreamSpec
+		want []*TopologyDifference
+	}{
+		"up to date": {
+			in:   NewDefaultStreamSpec(),
+			want: []*TopologyDifference{},
+		},
+		// The retention is left unset, so the current one is kept.
+		"drifted": {
+			in: &StreamSpec{
+				Name:       defaultStreamName,
+				Subjects:   []string{"stream.default.>", "stream.other.>"},
+				Replicas:   3,
+				Duplicates: 100 * time.Millisecond,
+			},
+			want: []*TopologyDifference{
+				{
+					Field:   "subjects",
+					Current: []string{"stream.default.>"},
+					Desired: []string{"stream.default.>", "stream.other.>"},
+				},
+				{Field: "replicas", Current: 1, Desired: 3},
+			},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			desired := tt.in.applyTo(current)
+
+			assert.Equal(t, "Unmanaged", desired.Description)
+			assert.Equal(t, tt.want, diffStreamConfigs(current, desired))
+		})
+	}
+}
+
+func TestConsumerSpecApplyTo(t *testing.T) {
+	current := NewConsumerConfigWithDefaults("test", "stream.default.test")
+
+	tests := map[string]struct {
+		in   *ConsumerSpec
+		want []*TopologyDifference
+	}{
+		"defaults": {
+			in:   &ConsumerSpec{Name: "test", FilterSubject: "stream.default.test"},
+			want: []*TopologyDifference{},
+		},
+		"drifted": {
+			in: &ConsumerSpec{Name: "test", FilterSubject: "stream.default.test", AckWait: time.Minute},
+			want: []*TopologyDifference{
+				{Field: "ackWait", Current: 30 * time.Second, Desired: time.Minute},
+			},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffConsumerConfigs(current, tt.in.applyTo(current)))
+		})
+	}
+}
+
+func TestKeyValueSpecApplyTo(t *testing.T) {
+	current := jetstream.KeyValueConfig{Bucket: "tasks", History: 1, TTL: time.Hour}
+
+	assert.Equal(t, []*TopologyDifference{}, diffKeyValueConfigs(
+		current,
+		(&KeyValueSpec{Bucket: "tasks", TTL: time.Hour}).applyTo(current),
+	))
+	assert.Equal(t, []*TopologyDifference{{Field: "ttl", Current: time.Hour, Desired: 2 * time.Hour}}, diffKeyValueConfigs(
+		current,
+		(&KeyValueSpec{Bucket: "tasks", TTL: 2 * time.Hour}).applyTo(current),
+	))
+}
+
+func TestEnsure(t *testing.T) {
+	js := newTestJetStream(t)
+	ctx := context.Background()
+
+	topology := &Topology{
+		Streams: []*StreamSpec{
+			NewDefaultStreamSpec(&ConsumerSpec{Name: "test", FilterSubject: "stream.default.test"}),
+		},
+		KeyValues: []*KeyValueSpec{{Bucket: "tasks", TTL: time.Hour}},
+	}
+
+	report, err := Ensure(js, ctx, topology)
+	require.NoError(t, err)
+	assert.Equal(t, []*TopologyChange{
+		{Resource: "stream default", Action: TopologyActionCreated},
+		{Resource: "consumer default/test", Action: TopologyActionCreated},
+		{Resource: "keyValue tasks", Action: TopologyActionCreated},
+	}, report.Changes)
+
+	report, err = Ensure(js, ctx, topology)
+	require.NoError(t, err)
+	assert.Empty(t, report.Changes)
+
+	// A spec without retention keeps the work-queue retention of the existing stream.
+	topology.Streams[0].Retention = nil
+	topology.Streams[0].Consumers[0].AckWait = time.Minute
+	topology.KeyValues[0].TTL = 2 * time.Hour
+
+	report, err = Ensure(js, ctx, topology)
+	require.NoError(t, err)
+	assert.Equal(t, []*TopologyChange{
+		{
+			Resource:    "consumer default/test",
+			Action:      TopologyActionUpdated,
+			Differences: []*TopologyDifference{{Field: "ackWait", Current: 30 * time.Second, Desired: time.Minute}},
+		},
+		{
+			Resource:    "keyValue tasks",
+			Action:      TopologyActionUpdated,
+			Differences: []*TopologyDifference{{Field: "ttl", Current: time.Hour, Desired: 2 * time.Hour}},
+		},
+	}, report.Changes)
+
+	stream, err := js.Stream(ctx, defaultStreamName)
+	require.NoError(t, err)
+	assert.Equal(t, jetstream.WorkQueuePolicy, stream.CachedInfo().Config.Retention)
+
+	// JetStream rejects retention changes.
+	limits := jetstream.LimitsPolicy
+	topology.Streams[0].Retention = &limits
+
+	_, err = Ensure(js, ctx, topology)
+	assert.Error(t, err)
+}
+
+// newTestJetStream runs an in-process NATS server with JetStream enabled, and connects to it.
+func newTestJetStream(t *testing.T) jetstream.JetStream {
+	t.Helper()
+
+	natsServer, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	go natsServer.Start()
+	t.Cleanup(natsServer.Shutdown)
+
+	require.True(t, natsServer.ReadyForConnections(5*time.Second))
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	return js
+}
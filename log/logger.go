@@ -62,6 +62,11 @@ func (l *Logger) Debug(message string, attributes ...slog.Attr) {
 	l.underlyingLogger.LogAttrs(context.TODO(), slog.LevelDebug, message, attributes...)
 }
 
+// Warn logs a warning message with the given attributes.
+func (l *Logger) Warn(message string, attributes ...slog.Attr) {
+	l.underlyingLogger.LogAttrs(context.TODO(), slog.LevelWarn, message, attributes...)
+}
+
 // Error logs an error message with the given attributes.
 func (l *Logger) Error(message string, attributes ...slog.Attr) {
 	l.underlyingLogger.LogAttrs(context.TODO(), slog.LevelError, message, attributes...)
@@ -76,6 +81,15 @@ func (l *Logger) FormattedDebug(message string, attributes ...slog.Attr) {
 	)...)
 }
 
+// FormattedWarn logs a warning message with the given attributes and automatically adds a formatted message attribute.
+// The formatted message attribute is used to send logs to Datadog.
+func (l *Logger) FormattedWarn(message string, attributes ...slog.Attr) {
+	l.Warn(message, append(
+		attributes,
+		slog.String(formattedMessageKey, formatMessage(message)),
+	)...)
+}
+
 // FormattedError logs an error message with the given attributes and automatically adds a formatted message attribute.
 // The formatted message attribute is used to send logs to Datadog.
 func (l *Logger) FormattedError(message string, attributes ...slog.Attr) {
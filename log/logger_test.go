@@ -11,8 +11,10 @@ func TestDefaultLogger(t *testing.T) {
 	assert.NotNil(t, Default())
 	assert.NotNil(t, NewAdapter(Default()))
 	assert.NotPanics(t, func() { Default().Debug("A debug message") })
+	assert.NotPanics(t, func() { Default().Warn("A warning message") })
 	assert.NotPanics(t, func() { Default().Error("An error message") })
 	assert.NotPanics(t, func() { Default().FormattedDebug("A formatted debug message") })
+	assert.NotPanics(t, func() { Default().FormattedWarn("A formatted warning message") })
 	assert.NotPanics(t, func() { Default().FormattedError("A formatted error message") })
 
 	l1 := Default().WithGroup("test")
@@ -0,0 +1,238 @@
+// Package outbox implements the transactional outbox pattern between PostgreSQL and NATS JetStream. Messages are
+// enqueued in the same transaction as the data they describe, and a relay publishes them to JetStream afterwards, so
+// no message is lost when a service crashes between the two.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/log"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Schema of the outbox table. It must be applied by the migrations of the services using the outbox. Messages are
+// ordered by their sequence, since the messages enqueued in the same transaction share the same created_at.
+const Schema = `CREATE TABLE IF NOT EXISTS outbox (
+	id UUID PRIMARY KEY,
+	sequence BIGSERIAL NOT NULL,
+	subject TEXT NOT NULL,
+	payload BYTEA NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (sequence) WHERE sent_at IS NULL;`
+
+// Enqueue a message in the outbox within the given transaction. The returned ID is used as the JetStream message ID
+// so duplicates can be detected.
+func Enqueue(tx *sql.Tx, subject string, payload []byte) (uuid.UUID, error) {
+	result := uuid.New()
+
+	if _, err := tx.Exec(
+		`INSERT INTO outbox (id, subject, payload) VALUES ($1, $2, $3)`,
+		result, subject, payload,
+	); err != nil {
+		return uuid.Nil, errors.MapSQLError(err)
+	}
+
+	return result, nil
+}
+
+// msgPublisher is the subset of the jetstream.JetStream interface used by the relay.
+type msgPublisher interface {
+	PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+// Relay publishes the messages of the outbox to JetStream.
+type Relay struct {
+	db        *sql.DB
+	js        msgPublisher
+	batchSize int
+	interval  time.Duration
+	logger    *log.Logger
+}
+
+type relayOption func(*Relay)
+
+// NewRelay creates a relay publishing the messages of the outbox of the given database. Messages are published with
+// their outbox ID as JetStream message ID, so a message published again after a failed commit is dropped by the
+// stream. JetStream only detects duplicates within the Duplicates window of the stream, which must therefore be longer
+// than the relay interval, plus the time to relay a batch.
+func NewRelay(db *sql.DB, js msgPublisher, options ...relayOption) *Relay {
+	const (
+		defaultBatchSize = 100
+		defaultInterval  = time.Second
+	)
+
+	result := &Relay{
+		db:        db,
+		js:        js,
+		batchSize: defaultBatchSize,
+		interval:  defaultInterval,
+		logger:    log.Default(),
+	}
+	for _, option := range options {
+		option(result)
+	}
+
+	return result
+}
+
+// WithRelayBatchSize sets the maximum number of messages relayed in a single transaction.
+func WithRelayBatchSize(size int) relayOption {
+	return func(relay *Relay) { relay.batchSize = size }
+}
+
+// WithRelayInterval sets the interval at which the outbox is polled when it is empty.
+func WithRelayInterval(interval time.Duration) relayOption {
+	return func(relay *Relay) { relay.interval = interval }
+}
+
+// WithRelayLogger sets the logger of the errors of Run. It defaults to log.Default().
+func WithRelayLogger(logger *log.Logger) relayOption {
+	return func(relay *Relay) { relay.logger = logger }
+}
+
+// Run the relay until the given context is canceled.
+func (relay *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := relay.RelayBatch(ctx)
+		if err != nil {
+			relay.logger.Error("Could not relay outbox messages", slog.String("error", err.Error()))
+		}
+
+		// Keep draining the outbox while full batches are relayed.
+		if err == nil && count == relay.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch publishes a batch of unsent messages, in the order they were enqueued, and marks them as sent. It returns
+// the number of relayed messages.
+//
+// Rows are locked with FOR UPDATE SKIP LOCKED, so several relays can run concurrently without publishing a message
+// twice. Only a single relay keeps the order of the messages though: concurrent relays publish consecutive batches in
+// parallel.
+func (relay *Relay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := relay.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.MapSQLError(err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	messages, err := readUnsentMessages(ctx, tx, relay.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sentIDs := []uuid.UUID{}
+
+	var publishError error
+
+	for _, message := range messages {
+		if _, err := relay.js.PublishMsg(ctx, &nats.Msg{
+			Subject: message.subject,
+			Data:    message.payload,
+			Header:  nats.Header{jetstream.MsgIDHeader: []string{message.id.String()}},
+		}); err != nil {
+			publishError = &errors.Error{
+				Code:            errors.CodeInternal,
+				Operation:       "outbox.RelayBatch",
+				Message:         "Could not publish outbox message " + message.id.String(),
+				UnderlyingError: err,
+			}
+
+			break // Do not publish the next messages before this one.
+		}
+
+		sentIDs = append(sentIDs, message.id)
+	}
+
+	if err := markMessagesAsSent(ctx, tx, sentIDs); err != nil {
+		if publishError != nil {
+			return 0, fmt.Errorf("%w; %w", publishError, err)
+		}
+
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.MapSQLError(err)
+	}
+
+	return len(sentIDs), publishError
+}
+
+type message struct {
+	id      uuid.UUID
+	subject string
+	payload []byte
+}
+
+func readUnsentMessages(ctx context.Context, tx *sql.Tx, limit int) ([]*message, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, subject, payload FROM outbox
+		WHERE sent_at IS NULL
+		ORDER BY sequence
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, errors.MapSQLError(err)
+	}
+	defer rows.Close()
+
+	result := []*message{}
+
+	for rows.Next() {
+		message := &message{}
+		if err := rows.Scan(&message.id, &message.subject, &message.payload); err != nil {
+			return nil, errors.MapSQLError(err)
+		}
+
+		result = append(result, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.MapSQLError(err)
+	}
+
+	return result, nil
+}
+
+func markMessagesAsSent(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rawIDs := []string{}
+	for _, id := range ids {
+		rawIDs = append(rawIDs, id.String())
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE outbox SET sent_at = NOW() WHERE id = ANY($1::uuid[])`,
+		pq.Array(rawIDs),
+	); err != nil {
+		return fmt.Errorf("could not mark outbox messages as sent: %w", errors.MapSQLError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testMessageID1 = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	testMessageID2 = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	testMessageID3 = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+)
+
+var (
+	selectUnsentMessagesQuery = regexp.QuoteMeta(`SELECT id, subject, payload FROM outbox`) + `[^;]+ORDER BY sequence`
+	markMessagesAsSentQuery   = regexp.QuoteMeta(`UPDATE outbox SET sent_at = NOW() WHERE id = ANY($1::uuid[])`)
+)
+
+func TestRelayBatch(t *testing.T) {
+	tests := map[string]struct {
+		messageIDs        []uuid.UUID
+		failingID         uuid.UUID
+		mockSQL           func(mock sqlmock.Sqlmock, rows *sqlmock.Rows)
+		wantPublished     []string
+		want              int
+		wantErrorCode     string
+		wantErrorsContain []string
+	}{
+		"empty": {
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			wantPublished: []string{},
+		},
+		"all published": {
+			messageIDs: []uuid.UUID{testMessageID1, testMessageID2},
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectExec(markMessagesAsSentQuery).
+					WithArgs(`{"` + testMessageID1.String() + `","` + testMessageID2.String() + `"}`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			wantPublished: []string{testMessageID1.String(), testMessageID2.String()},
+			want:          2,
+		},
+		"partially published": {
+			messageIDs: []uuid.UUID{testMessageID1, testMessageID2, testMessageID3},
+			failingID:  testMessageID2,
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectExec(markMessagesAsSentQuery).
+					WithArgs(`{"` + testMessageID1.String() + `"}`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			// The third message is not published, to keep the order of the messages.
+			wantPublished: []string{testMessageID1.String(), testMessageID2.String()},
+			want:          1,
+			wantErrorCode: errors.CodeInternal,
+		},
+		"first publish failed": {
+			messageIDs: []uuid.UUID{testMessageID1, testMessageID2},
+			failingID:  testMessageID1,
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			wantPublished: []string{testMessageID1.String()},
+			wantErrorCode: errors.CodeInternal,
+		},
+		"read failed": {
+			mockSQL: func(mock sqlmock.Sqlmock, _ *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantPublished: []string{},
+			wantErrorCode: errors.CodeInternal,
+		},
+		"mark failed": {
+			messageIDs: []uuid.UUID{testMessageID1},
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectExec(markMessagesAsSentQuery).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantPublished: []string{testMessageID1.String()},
+			wantErrorCode: errors.CodeInternal,
+		},
+		"partially published and mark failed": {
+			messageIDs: []uuid.UUID{testMessageID1, testMessageID2},
+			failingID:  testMessageID2,
+			mockSQL: func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(selectUnsentMessagesQuery).WithArgs(10).WillReturnRows(rows)
+				mock.ExpectExec(markMessagesAsSentQuery).WillReturnError(assert.AnError)
+				mock.ExpectRollback()
+			},
+			wantPublished: []string{testMessageID1.String(), testMessageID2.String()},
+			wantErrorsContain: []string{
+				"outbox.RelayBatch",
+				"could not mark outbox messages as sent",
+			},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			rows := sqlmock.NewRows([]string{"id", "subject", "payload"})
+			for _, id := range tt.messageIDs {
+				rows.AddRow(id.String(), "stream.default.test", []byte(`{}`))
+			}
+
+			tt.mockSQL(mock, rows)
+
+			published := []string{}
+			relay := NewRelay(db, &msgPublisherMock{
+				PublishMsgFunc: func(msg *nats.Msg) (*jetstream.PubAck, error) {
+					published = append(published, msg.Header.Get(jetstream.MsgIDHeader))
+
+					if msg.Header.Get(jetstream.MsgIDHeader) == tt.failingID.String() {
+						return nil, assert.AnError
+					}
+
+					return &jetstream.PubAck{}, nil
+				},
+			}, WithRelayBatchSize(10))
+
+			got, err := relay.RelayBatch(context.Background())
+			switch {
+			case len(tt.wantErrorsContain) > 0:
+				for _, wantError := range tt.wantErrorsContain {
+					assert.ErrorContains(t, err, wantError)
+				}
+			case tt.wantErrorCode == "":
+				assert.NoError(t, err)
+			default:
+				assert.Equal(t, tt.wantErrorCode, errors.ErrorCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPublished, published)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+type msgPublisherMock struct {
+	PublishMsgFunc func(msg *nats.Msg) (*jetstream.PubAck, error)
+}
+
+func (m *msgPublisherMock) PublishMsg(
+	_ context.Context,
+	msg *nats.Msg,
+	_ ...jetstream.PublishOpt,
+) (*jetstream.PubAck, error) {
+	return m.PublishMsgFunc(msg)
+}
@@ -0,0 +1,138 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+const cursorSignatureSeparator = "."
+
+// CursorCodec encodes the keys of a keyset pagination into opaque cursors: base64url encoded JSON, optionally signed
+// with HMAC-SHA256 and expiring. Composite keys are supported, such as the search_after values of Elasticsearch
+// ([]estypes.FieldValue), whose numbers are decoded as json.Number to keep their precision.
+type CursorCodec[K any] struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+type CursorCodecConfig struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+type CursorCodecOption func(config *CursorCodecConfig)
+
+// WithCursorCodecSecret signs the cursors with the given secret, so clients cannot tamper with them.
+func WithCursorCodecSecret(secret []byte) CursorCodecOption {
+	return func(config *CursorCodecConfig) { config.secret = secret }
+}
+
+// WithCursorCodecTTL makes the cursors expire after the given duration.
+func WithCursorCodecTTL(ttl time.Duration) CursorCodecOption {
+	return func(config *CursorCodecConfig) { config.ttl = ttl }
+}
+
+func WithCursorCodecNow(now func() time.Time) CursorCodecOption {
+	return func(config *CursorCodecConfig) { config.now = now }
+}
+
+func NewCursorCodec[K any](options ...CursorCodecOption) *CursorCodec[K] {
+	config := &CursorCodecConfig{now: time.Now}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return &CursorCodec[K]{
+		secret: config.secret,
+		ttl:    config.ttl,
+		now:    config.now,
+	}
+}
+
+type cursorPayload[K any] struct {
+	Key       K     `json:"k"`
+	ExpiresAt int64 `json:"e,omitempty"`
+}
+
+// Encode the given key into a cursor.
+func (c *CursorCodec[K]) Encode(key K) (string, error) {
+	payload := &cursorPayload[K]{Key: key}
+	if c.ttl > 0 {
+		payload.ExpiresAt = c.now().Add(c.ttl).Unix()
+	}
+
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", &errors.Error{Code: errors.CodeInternal, Message: "Could not encode cursor", UnderlyingError: err}
+	}
+
+	result := base64.RawURLEncoding.EncodeToString(encodedPayload)
+	if c.secret != nil {
+		result += cursorSignatureSeparator + base64.RawURLEncoding.EncodeToString(c.sign(encodedPayload))
+	}
+
+	return result, nil
+}
+
+// Decode the key of the given cursor. It fails with an invalid error if the cursor is malformed, tampered with or
+// expired.
+func (c *CursorCodec[K]) Decode(cursor string) (K, error) {
+	var result K
+
+	encodedPayload, encodedSignature, signed := strings.Cut(cursor, cursorSignatureSeparator)
+	if signed != (c.secret != nil) {
+		return result, newInvalidCursorError(nil)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return result, newInvalidCursorError(err)
+	}
+
+	if signed {
+		signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+		if err != nil {
+			return result, newInvalidCursorError(err)
+		}
+
+		if !hmac.Equal(signature, c.sign(payload)) {
+			return result, newInvalidCursorError(nil)
+		}
+	}
+
+	decodedPayload := &cursorPayload[K]{}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(decodedPayload); err != nil {
+		return result, newInvalidCursorError(err)
+	}
+
+	if decodedPayload.ExpiresAt != 0 && c.now().Unix() > decodedPayload.ExpiresAt {
+		return result, &errors.Error{Code: errors.CodeInvalid, Message: "Expired cursor"}
+	}
+
+	return decodedPayload.Key, nil
+}
+
+func (c *CursorCodec[K]) sign(payload []byte) []byte {
+	hash := hmac.New(sha256.New, c.secret)
+	hash.Write(payload)
+
+	return hash.Sum(nil)
+}
+
+func newInvalidCursorError(underlyingError error) error {
+	return &errors.Error{Code: errors.CodeInvalid, Message: "Invalid cursor", UnderlyingError: underlyingError}
+}
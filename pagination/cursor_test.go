@@ -0,0 +1,113 @@
+package pagination
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorCodec(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+
+	t.Run("composite key", func(t *testing.T) {
+		type guestKey struct {
+			GuestID uuid.UUID `json:"guestId"`
+			Arrival time.Time `json:"arrival"`
+		}
+
+		codec := NewCursorCodec[guestKey]()
+		key := guestKey{GuestID: uuid.New(), Arrival: now}
+
+		cursor, err := codec.Encode(key)
+		require.NoError(t, err)
+		assert.NotContains(t, cursor, key.GuestID.String(), "cursors must be opaque")
+
+		got, err := codec.Decode(cursor)
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
+
+	t.Run("search after", func(t *testing.T) {
+		codec := NewCursorCodec[[]estypes.FieldValue](WithCursorCodecSecret([]byte("secret")))
+
+		cursor, err := codec.Encode([]estypes.FieldValue{int64(9007199254740993), "abc"})
+		require.NoError(t, err)
+
+		got, err := codec.Decode(cursor)
+		require.NoError(t, err)
+
+		encodedGot, err := json.Marshal(got)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[9007199254740993, "abc"]`, string(encodedGot), "numbers must keep their precision")
+	})
+
+	t.Run("invalid cursors", func(t *testing.T) {
+		codec := NewCursorCodec[int](
+			WithCursorCodecSecret([]byte("secret")),
+			WithCursorCodecTTL(time.Hour),
+			WithCursorCodecNow(nowFunc),
+		)
+
+		cursor, err := codec.Encode(42)
+		require.NoError(t, err)
+
+		payload, signature, _ := strings.Cut(cursor, ".")
+		otherCursor, err := NewCursorCodec[int](WithCursorCodecSecret([]byte("other"))).Encode(43)
+		require.NoError(t, err)
+
+		_, otherSignature, _ := strings.Cut(otherCursor, ".")
+
+		expiredCursor, err := NewCursorCodec[int](
+			WithCursorCodecSecret([]byte("secret")),
+			WithCursorCodecTTL(time.Hour),
+			WithCursorCodecNow(func() time.Time { return now.Add(-2 * time.Hour) }),
+		).Encode(42)
+		require.NoError(t, err)
+
+		for name, in := range map[string]string{
+			"unsigned":         payload,
+			"tampered payload": otherCursor[:strings.Index(otherCursor, ".")] + "." + signature,
+			"tampered sign":    payload + "." + otherSignature,
+			"malformed":        "%%%." + signature,
+			"expired":          expiredCursor,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := codec.Decode(in)
+				assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+			})
+		}
+
+		got, err := codec.Decode(cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 42, got)
+	})
+}
+
+func TestKeysetSelectorCursorQuery(t *testing.T) {
+	codec := NewCursorCodec[[]estypes.FieldValue](WithCursorCodecSecret([]byte("secret")))
+
+	query, err := (&KeysetSelector[[]estypes.FieldValue]{
+		LastKey: []estypes.FieldValue{"2024-03-15", "7b5d9c4e-1f2a-4b3c-8d9e-0a1b2c3d4e5f"},
+		Size:    10,
+	}).CursorQuery(codec)
+	require.NoError(t, err)
+
+	got, err := ParseKeysetSelector(query, codec.Decode)
+	require.NoError(t, err)
+	assert.Equal(t, &KeysetSelector[[]estypes.FieldValue]{
+		LastKey: []estypes.FieldValue{"2024-03-15", "7b5d9c4e-1f2a-4b3c-8d9e-0a1b2c3d4e5f"},
+		Size:    10,
+	}, got)
+
+	_, err = ParseKeysetSelector(url.Values{"lastKey": {"eyJrIjpbXX0"}}, codec.Decode)
+	assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+}
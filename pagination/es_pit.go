@@ -0,0 +1,187 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/closepointintime"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
+)
+
+// closePITTimeout bounds the request closing a PIT, which is sent even if the context of the reader is done.
+const closePITTimeout = 5 * time.Second
+
+type ESPITPager[S ~[]E, E any] = Pager[S, E, *ESPITPageReader[S, E]]
+
+// ESPITPageReader reads the hits of a search with search_after, in a point in time (PIT) that it manages. The PIT is
+// opened on the first read, its keep-alive is extended by every read and it is closed when the last page is read, or on
+// reset, release or search error. Errors closing the PIT are ignored, as it expires after its keep-alive anyway.
+//
+// A read failing to map a hit keeps the PIT open, so retrying it reads the same page again. A read failing to search
+// closes the PIT, so retrying it restarts from the first page in a new PIT: the sort values of the hits (e.g.
+// _shard_doc) are only valid in the PIT returning them.
+type ESPITPageReader[S ~[]E, E any] struct {
+	ctx         context.Context //nolint:containedctx
+	client      *elasticsearch.TypedClient
+	indices     []string
+	mapHitFunc  func(hit estypes.Hit) (E, error)
+	keepAlive   string
+	query       *estypes.Query
+	sort        []estypes.SortCombinations
+	pitID       string
+	searchAfter []estypes.FieldValue
+	done        bool
+}
+
+type ESPITPageReaderConfig struct {
+	keepAlive string
+	query     *estypes.Query
+	sort      []estypes.SortCombinations
+}
+
+type ESPITPageReaderOption func(config *ESPITPageReaderConfig)
+
+// WithESPITPageReaderKeepAlive sets the duration for which the PIT is kept alive between reads (e.g. "5m").
+func WithESPITPageReaderKeepAlive(keepAlive string) ESPITPageReaderOption {
+	return func(config *ESPITPageReaderConfig) { config.keepAlive = keepAlive }
+}
+
+func WithESPITPageReaderQuery(query *estypes.Query) ESPITPageReaderOption {
+	return func(config *ESPITPageReaderConfig) { config.query = query }
+}
+
+// WithESPITPageReaderSort sets the sort of the hits. Elasticsearch adds the _shard_doc tiebreaker to the sort of
+// searches in a PIT, so hits are sorted by _shard_doc by default.
+func WithESPITPageReaderSort(sort ...estypes.SortCombinations) ESPITPageReaderOption {
+	return func(config *ESPITPageReaderConfig) { config.sort = sort }
+}
+
+// NewESPITPageReader creates a reader of the hits of the given indices (e.g. esutil.Indices.Strings()), which are
+// mapped to elements with the given function.
+func NewESPITPageReader[S ~[]E, E any](
+	ctx context.Context,
+	client *elasticsearch.TypedClient,
+	indices []string,
+	mapHitFunc func(hit estypes.Hit) (E, error),
+	options ...ESPITPageReaderOption,
+) *ESPITPageReader[S, E] {
+	config := &ESPITPageReaderConfig{
+		keepAlive: "1m",
+		sort: []estypes.SortCombinations{
+			estypes.SortOptions{SortOptions: map[string]estypes.FieldSort{"_shard_doc": {Order: &sortorder.Asc}}},
+		},
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return &ESPITPageReader[S, E]{
+		ctx:        ctx,
+		client:     client,
+		indices:    indices,
+		mapHitFunc: mapHitFunc,
+		keepAlive:  config.keepAlive,
+		query:      config.query,
+		sort:       config.sort,
+	}
+}
+
+var (
+	_ PageReader[[]any, any] = (*ESPITPageReader[[]any, any])(nil)
+	_ PageReleaser           = (*ESPITPageReader[[]any, any])(nil)
+)
+
+func (r *ESPITPageReader[S, E]) ReadPage(size int) (S, error) {
+	if r.done {
+		return nil, nil
+	}
+
+	hits, err := r.search(size)
+	if err != nil {
+		r.closePIT()
+
+		return nil, err
+	}
+
+	result := S{}
+
+	for _, hit := range hits {
+		element, err := r.mapHitFunc(hit)
+		if err != nil {
+			return nil, err // The PIT is kept open, so the page can be read again.
+		}
+
+		result = append(result, element)
+	}
+
+	// The page is only skipped once all of its hits are mapped, so a read retried after an error reads it again.
+	if len(hits) > 0 {
+		r.searchAfter = hits[len(hits)-1].Sort
+	}
+
+	if len(result) < size {
+		r.done = true
+		r.closePIT()
+	}
+
+	return result, nil
+}
+
+// Reset closes the PIT, so the next read starts from the first page in a new PIT.
+func (r *ESPITPageReader[S, E]) Reset() {
+	r.closePIT()
+	r.done = false
+}
+
+// Release closes the PIT.
+func (r *ESPITPageReader[S, E]) Release() { r.closePIT() }
+
+func (r *ESPITPageReader[S, E]) search(size int) ([]estypes.Hit, error) {
+	if r.pitID == "" {
+		response, err := r.client.OpenPointInTime(strings.Join(r.indices, ",")).KeepAlive(r.keepAlive).Do(r.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not open point in time: %w", err)
+		}
+
+		r.pitID = response.Id
+	}
+
+	response, err := r.client.Search().Request(&search.Request{
+		Pit:         &estypes.PointInTimeReference{Id: r.pitID, KeepAlive: r.keepAlive},
+		Query:       r.query,
+		SearchAfter: r.searchAfter,
+		Size:        &size,
+		Sort:        r.sort,
+	}).Do(r.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not search in point in time: %w", err)
+	}
+
+	// The ID of a PIT might change between searches.
+	if pitID := response.PitId; pitID != nil {
+		r.pitID = *pitID
+	}
+
+	return response.Hits.Hits, nil
+}
+
+func (r *ESPITPageReader[S, E]) closePIT() {
+	if r.pitID == "" {
+		return
+	}
+
+	// The PIT is also closed when a read failed because the context of the reader is done.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.ctx), closePITTimeout)
+	defer cancel()
+
+	_, _ = r.client.ClosePointInTime().Request(&closepointintime.Request{Id: r.pitID}).Do(ctx)
+
+	r.pitID = ""
+	r.searchAfter = nil // The sort values of the hits are only valid in the PIT.
+}
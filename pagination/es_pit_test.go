@@ -0,0 +1,263 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	estypes "github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestESPITPageReader(t *testing.T) {
+	t.Run("complete", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		ids := []string{}
+
+		for id, err := range NewPager(newTestPITPageReader(t, server.URL), WithPageSize(2)).All() {
+			require.NoError(t, err)
+
+			ids = append(ids, id)
+		}
+
+		assert.Equal(t, []string{"0", "1", "2", "3", "4"}, ids)
+		assert.Equal(t, []string{
+			"POST /a,b/_pit keep_alive=5m",
+			"POST /_search pit=pit-0 search_after=",
+			"POST /_search pit=pit-1 search_after=[1]",
+			"POST /_search pit=pit-2 search_after=[3]",
+			"DELETE /_pit pit=pit-3",
+		}, server.requests)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		for range NewPager(newTestPITPageReader(t, server.URL), WithPageSize(2)).All() {
+			break
+		}
+
+		assert.Equal(t, []string{
+			"POST /a,b/_pit keep_alive=5m",
+			"POST /_search pit=pit-0 search_after=",
+			"DELETE /_pit pit=pit-1",
+		}, server.requests)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		pager := NewPager(newTestPITPageReader(t, server.URL), WithPageSize(2))
+		require.True(t, pager.Next())
+
+		pager.Reset()
+		require.True(t, pager.Next())
+		assert.Equal(t, []string{"0", "1"}, pager.Page())
+
+		assert.Equal(t, []string{
+			"POST /a,b/_pit keep_alive=5m",
+			"POST /_search pit=pit-0 search_after=",
+			"DELETE /_pit pit=pit-1",
+			"POST /a,b/_pit keep_alive=5m",
+			"POST /_search pit=pit-1 search_after=",
+		}, server.requests)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		reader := NewESPITPageReader[[]string](
+			context.Background(),
+			newTestTypedClient(t, server.URL),
+			[]string{"a", "b"},
+			func(estypes.Hit) (string, error) { return "", assert.AnError },
+		)
+
+		_, err := reader.ReadPage(2)
+		require.ErrorIs(t, err, assert.AnError)
+
+		// The PIT is kept open until the reader is released.
+		reader.Release()
+		assert.Equal(t, []string{
+			"POST /a,b/_pit keep_alive=1m",
+			"POST /_search pit=pit-0 search_after=",
+			"DELETE /_pit pit=pit-1",
+		}, server.requests)
+	})
+
+	t.Run("retry after error", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		failed := false
+		reader := NewESPITPageReader[[]string](
+			context.Background(),
+			newTestTypedClient(t, server.URL),
+			[]string{"a", "b"},
+			func(hit estypes.Hit) (string, error) {
+				if *hit.Id_ == "3" && !failed {
+					failed = true
+
+					return "", assert.AnError
+				}
+
+				return *hit.Id_, nil
+			},
+		)
+
+		page, err := reader.ReadPage(2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0", "1"}, page)
+
+		_, err = reader.ReadPage(2)
+		require.ErrorIs(t, err, assert.AnError)
+
+		page, err = reader.ReadPage(2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2", "3"}, page)
+
+		assert.Equal(t, []string{
+			"POST /a,b/_pit keep_alive=1m",
+			"POST /_search pit=pit-0 search_after=",
+			"POST /_search pit=pit-1 search_after=[1]",
+			"POST /_search pit=pit-2 search_after=[1]",
+		}, server.requests)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		server := newPITServerMock(t, 5)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		reader := NewESPITPageReader[[]string](
+			ctx,
+			newTestTypedClient(t, server.URL),
+			[]string{"a", "b"},
+			func(hit estypes.Hit) (string, error) { return *hit.Id_, nil },
+		)
+
+		_, err := reader.ReadPage(2)
+		require.NoError(t, err)
+
+		cancel()
+
+		_, err = reader.ReadPage(2)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "DELETE /_pit pit=pit-1", server.requests[len(server.requests)-1])
+
+		// The sort values of the closed PIT are not sent to the next one.
+		assert.Nil(t, reader.searchAfter)
+	})
+}
+
+func newTestPITPageReader(t *testing.T, address string) *ESPITPageReader[[]string, string] {
+	t.Helper()
+
+	return NewESPITPageReader[[]string](
+		context.Background(),
+		newTestTypedClient(t, address),
+		[]string{"a", "b"},
+		func(hit estypes.Hit) (string, error) { return *hit.Id_, nil },
+		WithESPITPageReaderKeepAlive("5m"),
+	)
+}
+
+func newTestTypedClient(t *testing.T, address string) *elasticsearch.TypedClient {
+	t.Helper()
+
+	result, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{address}})
+	require.NoError(t, err)
+
+	return result
+}
+
+// pitServerMock returns the given number of documents, sorted by ID. The ID of the PIT changes on every search, and
+// searches with another PIT ID than the last one fail.
+type pitServerMock struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func newPITServerMock(t *testing.T, documentsCount int) *pitServerMock {
+	t.Helper()
+
+	result := &pitServerMock{}
+	pitVersion := 0
+	currentPITID := ""
+
+	result.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result.mu.Lock()
+		defer result.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		body := struct {
+			ID          string              `json:"id"`
+			Pit         struct{ ID string } `json:"pit"`
+			SearchAfter []int               `json:"search_after"`
+			Size        int                 `json:"size"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /a,b/_pit":
+			result.requests = append(result.requests, "POST /a,b/_pit keep_alive="+r.URL.Query().Get("keep_alive"))
+			currentPITID = "pit-" + strconv.Itoa(pitVersion)
+			_, _ = w.Write([]byte(`{"id": "` + currentPITID + `"}`))
+		case "DELETE /_pit":
+			result.requests = append(result.requests, "DELETE /_pit pit="+body.ID)
+			currentPITID = ""
+			_, _ = w.Write([]byte(`{"succeeded": true, "num_freed": 1}`))
+		case "POST /_search":
+			searchAfter := ""
+			start := 0
+
+			if len(body.SearchAfter) > 0 {
+				searchAfter = "[" + strconv.Itoa(body.SearchAfter[0]) + "]"
+				start = body.SearchAfter[0] + 1
+			}
+
+			result.requests = append(result.requests, "POST /_search pit="+body.Pit.ID+" search_after="+searchAfter)
+
+			if body.Pit.ID != currentPITID {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": {"type": "search_context_missing_exception"}, "status": 404}`))
+
+				return
+			}
+
+			pitVersion++
+			currentPITID = "pit-" + strconv.Itoa(pitVersion)
+
+			hits := []map[string]any{}
+			for i := start; i < min(start+body.Size, documentsCount); i++ {
+				hits = append(hits, map[string]any{"_index": "a", "_id": strconv.Itoa(i), "sort": []int{i}})
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"pit_id": currentPITID,
+				"hits":   map[string]any{"hits": hits},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return result
+}
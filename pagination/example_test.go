@@ -17,3 +17,17 @@ func Example() {
 		// Check for errors.
 	}
 }
+
+func ExamplePager_All() {
+	pager := NewPager(NewOffsetPageReader(func(size, offset int) ([]int, error) {
+		return []int{}, nil // Read a page with the given size and offset.
+	}))
+
+	for element, err := range pager.All() { // Iterate over the elements of every page.
+		if err != nil {
+			break // Handle the error.
+		}
+
+		_ = element // Process the element.
+	}
+}
@@ -2,6 +2,7 @@ package pagination
 
 import (
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 
@@ -85,6 +86,46 @@ func (p *Pager[S, E, R]) Next() bool {
 	return true
 }
 
+// Pages returns an iterator over the pages. Reading stops at the first error, which is yielded with a nil page, or when
+// the loop is stopped early. Readers implementing [PageReleaser] are released when the iteration stops.
+func (p *Pager[S, E, R]) Pages() iter.Seq2[S, error] {
+	return func(yield func(S, error) bool) {
+		if releaser, ok := any(p.Reader).(PageReleaser); ok {
+			defer releaser.Release()
+		}
+
+		for p.Next() {
+			if !yield(p.Page(), nil) {
+				return
+			}
+		}
+
+		if err := p.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// All returns an iterator over the elements of every page. It works like [Pager.Pages].
+func (p *Pager[S, E, R]) All() iter.Seq2[E, error] {
+	return func(yield func(E, error) bool) {
+		for page, err := range p.Pages() {
+			if err != nil {
+				var empty E
+				yield(empty, err)
+
+				return
+			}
+
+			for _, element := range page {
+				if !yield(element, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // Page returns the last page read by the [Pager.Next] method.
 func (p *Pager[S, E, R]) Page() S { return p.lastPage }
 
@@ -107,6 +148,19 @@ type PageReader[S ~[]E, E any] interface {
 	Reset()
 }
 
+// PageReleaser is implemented by the page readers holding resources (e.g. an ES PIT) that must be released when an
+// iteration stops, even early.
+type PageReleaser interface {
+	Release()
+}
+
+// KeysetReader is implemented by the keyset page readers, whose position is the last key they read. It is used to
+// save and restore the position of a reader.
+type KeysetReader[K any] interface {
+	GetLastKey() K
+	SetLastKey(lastKey K)
+}
+
 type KeysetPageReader[S ~[]E, E, K any] struct {
 	LastKey      K
 	readPageFunc func(size int, lastKey K) (S, K, error)
@@ -150,6 +204,11 @@ func (r *KeysetPageReader[S, E, K]) ReadPage(size int) (S, error) {
 	return result, nil
 }
 
+var _ KeysetReader[any] = (*KeysetPageReader[[]any, any, any])(nil)
+
+func (r *KeysetPageReader[S, E, K]) GetLastKey() K        { return r.LastKey }
+func (r *KeysetPageReader[S, E, K]) SetLastKey(lastKey K) { r.LastKey = lastKey }
+
 func (r *KeysetPageReader[S, E, K]) Reset() {
 	var emptyKey K
 	r.LastKey = emptyKey
@@ -213,6 +272,11 @@ func (r *ESKeysetPageReader[S, E, K]) ReadPage(size int) (S, error) {
 	return result, nil
 }
 
+var _ KeysetReader[any] = (*ESKeysetPageReader[[]any, any, any])(nil)
+
+func (r *ESKeysetPageReader[S, E, K]) GetLastKey() K        { return r.LastKey }
+func (r *ESKeysetPageReader[S, E, K]) SetLastKey(lastKey K) { r.LastKey = lastKey }
+
 func (r *ESKeysetPageReader[S, E, K]) Reset() {
 	var emptyKey K
 	r.LastKey = emptyKey
@@ -291,6 +355,11 @@ func (r *ESCompositeKeysetPageReader[S, E, K]) ReadPage(size int) (S, error) {
 	return result, nil
 }
 
+var _ KeysetReader[any] = (*ESCompositeKeysetPageReader[[]any, any, any])(nil)
+
+func (r *ESCompositeKeysetPageReader[S, E, K]) GetLastKey() K        { return r.LastKey }
+func (r *ESCompositeKeysetPageReader[S, E, K]) SetLastKey(lastKey K) { r.LastKey = lastKey }
+
 func (r *ESCompositeKeysetPageReader[S, E, K]) Reset() {
 	var emptyKey K
 	r.LastKey = emptyKey
@@ -339,6 +408,21 @@ func (s *KeysetSelector[T]) Query() url.Values {
 	return result
 }
 
+// CursorQuery works like Query, but encodes the last key into an opaque cursor with the given codec. Such selectors
+// are parsed by passing [CursorCodec.Decode] to [ParseKeysetSelector].
+func (s *KeysetSelector[T]) CursorQuery(codec *CursorCodec[T]) (url.Values, error) {
+	cursor, err := codec.Encode(s.LastKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := url.Values{}
+	result.Add(keysetSelectorLastKeyQuery, cursor)
+	result.Add(keysetSelectorSizeQuery, strconv.Itoa(s.Size))
+
+	return result, nil
+}
+
 func ParseKeysetSelector[T any](
 	query url.Values,
 	parseLastKeyFunc func(key string) (T, error),
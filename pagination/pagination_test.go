@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPager(t *testing.T) {
@@ -78,6 +79,76 @@ func TestPager(t *testing.T) {
 	})
 }
 
+func TestPagerIterators(t *testing.T) {
+	t.Run("pages", func(t *testing.T) {
+		pages := [][]int{}
+
+		for page, err := range NewPager(NewOffsetPageReader(readIntsPage), WithPageSize(2)).Pages() {
+			require.NoError(t, err)
+
+			pages = append(pages, page)
+		}
+
+		assert.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, pages)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		elements := []int{}
+
+		for element, err := range NewPager(NewOffsetPageReader(readIntsPage), WithPageSize(2)).All() {
+			require.NoError(t, err)
+
+			elements = append(elements, element)
+		}
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, elements)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		reader := &releasablePageReader{OffsetPagerReader: NewOffsetPageReader(readIntsPage)}
+		elements := []int{}
+
+		for element := range NewPager(reader, WithPageSize(2)).All() {
+			elements = append(elements, element)
+
+			if element == 2 {
+				break
+			}
+		}
+
+		assert.Equal(t, []int{0, 1, 2}, elements)
+		assert.Equal(t, 4, reader.offset, "the third page must not be read")
+		assert.True(t, reader.released)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		calls := 0
+		pager := NewPager(NewOffsetPageReader(func(size, offset int) ([]int, error) {
+			if calls++; calls > 1 {
+				return nil, assert.AnError
+			}
+
+			return readIntsPage(size, offset)
+		}), WithPageSize(2))
+
+		elements, errs := []int{}, []error{}
+
+		for element, err := range pager.All() {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			elements = append(elements, element)
+		}
+
+		assert.Equal(t, []int{0, 1}, elements)
+		assert.Equal(t, []error{assert.AnError}, errs)
+		assert.ErrorIs(t, pager.Err(), assert.AnError)
+	})
+}
+
 func TestBoundLimit(t *testing.T) {
 	tests := map[string]struct {
 		in, want int
@@ -117,3 +188,22 @@ func readIDsPage(_, offset int) (uuid.UUIDs, error) {
 
 	return uuid.UUIDs{uuid.New()}, nil
 }
+
+// readIntsPage reads pages of the integers from 0 to 4.
+func readIntsPage(size, offset int) ([]int, error) {
+	result := []int{}
+	for i := offset; i < min(offset+size, 5); i++ {
+		result = append(result, i)
+	}
+
+	return result, nil
+}
+
+type releasablePageReader struct {
+	*OffsetPagerReader[[]int, int]
+	released bool
+}
+
+var _ PageReleaser = (*releasablePageReader)(nil)
+
+func (r *releasablePageReader) Release() { r.released = true }
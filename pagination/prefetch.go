@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"context"
+	"iter"
+)
+
+// Prefetch returns an iterator over the pages of the pager, which are read in the background up to the given number of
+// pages ahead of the caller. Processing a page thus overlaps with reading the next ones, and at most ahead pages are
+// held in memory besides the one being processed.
+//
+// Errors are yielded after the pages read before them, and stop the iteration. The iteration also stops when the
+// context is canceled, yielding the error of the context. The pager must not be used until the iteration stops.
+func Prefetch[S ~[]E, E any, R PageReader[S, E]](
+	ctx context.Context,
+	pager *Pager[S, E, R],
+	ahead int,
+) iter.Seq2[S, error] {
+	return func(yield func(S, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+
+		// The reader holds a page while it is blocked sending it, so the buffer is one page shorter.
+		results := make(chan prefetchedPage[S], max(ahead, 1)-1)
+
+		go func() {
+			defer close(results)
+
+			for page, err := range pager.Pages() {
+				select {
+				case results <- prefetchedPage[S]{page, err}:
+				case <-ctx.Done():
+					return
+				}
+
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		}()
+
+		defer func() {
+			cancel()
+
+			// Wait for the reader to stop, so the pager can be used again.
+			for range results {
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+
+				return
+			case result, ok := <-results:
+				if !ok {
+					return
+				}
+
+				// Both cases might be ready, so the cancellation must be checked again.
+				if err := ctx.Err(); err != nil {
+					yield(nil, err)
+
+					return
+				}
+
+				if !yield(result.page, result.err) || result.err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+type prefetchedPage[S any] struct {
+	page S
+	err  error
+}
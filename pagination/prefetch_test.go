@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetch(t *testing.T) {
+	t.Run("pages", func(t *testing.T) {
+		pages := [][]int{}
+
+		for page, err := range Prefetch(context.Background(), NewPager(NewOffsetPageReader(readIntsPage), WithPageSize(2)), 2) {
+			require.NoError(t, err)
+
+			pages = append(pages, page)
+		}
+
+		assert.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, pages)
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		const ahead = 2
+
+		read, processed, maxAhead := atomic.Int32{}, atomic.Int32{}, atomic.Int32{}
+
+		pager := NewPager(NewOffsetPageReader(func(size, offset int) ([]int, error) {
+			if offset >= 20 {
+				return nil, nil
+			}
+
+			if current := read.Add(1) - processed.Load(); current > maxAhead.Load() {
+				maxAhead.Store(current)
+			}
+
+			return []int{offset}, nil
+		}), WithPageSize(1))
+
+		for _, err := range Prefetch(context.Background(), pager, ahead) {
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond) // Let the reader fill the buffer.
+			processed.Add(1)
+		}
+
+		assert.Equal(t, int32(20), processed.Load())
+		assert.LessOrEqual(t, maxAhead.Load(), int32(ahead+1), "the page being processed is also counted")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		pager := NewPager(NewOffsetPageReader(func(size, offset int) ([]int, error) {
+			if offset >= 2 {
+				return nil, assert.AnError
+			}
+
+			return readIntsPage(size, offset)
+		}), WithPageSize(1))
+
+		pages, errs := [][]int{}, []error{}
+
+		for page, err := range Prefetch(context.Background(), pager, 3) {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			pages = append(pages, page)
+		}
+
+		assert.Equal(t, [][]int{{0}, {1}}, pages)
+		assert.Equal(t, []error{assert.AnError}, errs)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		reader := &releasablePageReader{OffsetPagerReader: NewOffsetPageReader(readIntsPage)}
+
+		for range Prefetch(context.Background(), NewPager(reader, WithPageSize(1)), 1) {
+			break
+		}
+
+		assert.True(t, reader.released)
+		assert.LessOrEqual(t, reader.offset, 2)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		pager := NewPager(NewOffsetPageReader(func(size, offset int) ([]int, error) {
+			return []int{offset}, nil // Never ends.
+		}), WithPageSize(1))
+
+		pagesCount, errs := 0, []error{}
+
+		for _, err := range Prefetch(ctx, pager, 2) {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			if pagesCount++; pagesCount == 3 {
+				cancel()
+			}
+		}
+
+		assert.Equal(t, 3, pagesCount)
+		assert.Equal(t, []error{context.Canceled}, errs)
+	})
+}
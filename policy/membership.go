@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/resmodels"
+)
+
+// EntitiesReader is implemented by client.EntitiesManager.
+type EntitiesReader interface {
+	ReadEntity(selector *resmodels.EntitySelector) (*resmodels.Entity, error)
+	ReadAccountParents(selector *resmodels.EntitySelector) ([]*resmodels.Entity, error)
+}
+
+// AgentEntitiesReader reads the IDs of the entities an agent belongs to.
+type AgentEntitiesReader interface {
+	ReadAgentEntityIDs(agentID uuid.UUID) (uuid.UUIDs, error)
+}
+
+// IsEntityMember allows the agents belonging to the entity of the request, or to one of its ancestors: its chain, group
+// or reseller, and the resellers of the chain and group of an account. Requests without entity are denied.
+func IsEntityMember(entitiesReader EntitiesReader, agentEntitiesReader AgentEntitiesReader) Rule {
+	return RuleFunc(func(request *Request) (bool, error) {
+		if request.Token == nil || request.EntityID == uuid.Nil {
+			return false, nil
+		}
+
+		agentEntityIDs, err := agentEntitiesReader.ReadAgentEntityIDs(request.Token.AgentID)
+		if err != nil {
+			return false, err
+		}
+
+		if slices.Contains(agentEntityIDs, request.EntityID) {
+			return true, nil
+		}
+
+		ancestorIDs, err := readEntityAncestorIDs(entitiesReader, request.EntityID)
+		if err != nil {
+			return false, err
+		}
+
+		return slices.ContainsFunc(ancestorIDs, func(id uuid.UUID) bool {
+			return slices.Contains(agentEntityIDs, id)
+		}), nil
+	})
+}
+
+func readEntityAncestorIDs(entitiesReader EntitiesReader, entityID uuid.UUID) (uuid.UUIDs, error) {
+	selector := &resmodels.EntitySelector{EntityID: entityID}
+
+	entity, err := entitiesReader.ReadEntity(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := appendEntityParentIDs(uuid.UUIDs{}, entity)
+
+	if entity.Type == resmodels.EntityTypeAccount {
+		parents, err := entitiesReader.ReadAccountParents(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parent := range parents {
+			result = appendEntityParentIDs(result, parent)
+		}
+	}
+
+	return result, nil
+}
+
+func appendEntityParentIDs(ids uuid.UUIDs, entity *resmodels.Entity) uuid.UUIDs {
+	for _, id := range []uuid.UUID{entity.ChainID(), entity.GroupID(), entity.ResellerID()} {
+		if id != uuid.Nil && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,192 @@
+// Package policy authorizes the requests of agents with composable rules: roles, membership of the entity the request
+// acts on, and custom predicates. Decisions can be cached, and policies can guard RES calls or answer RES access
+// requests, so the same rules apply to both.
+package policy
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/cache"
+	"github.com/loungeup/go-loungeup/client/models"
+)
+
+// Actions of the requests, besides the methods of the calls.
+const ActionGet = "get"
+
+// Request to authorize.
+type Request struct {
+	Token *models.Token
+
+	// EntityID is the ID of the entity the request acts on, if any.
+	EntityID uuid.UUID
+
+	// Resource is the RID of the requested resource.
+	Resource string
+
+	// Action is ActionGet or the method of a call.
+	Action string
+}
+
+// Rule of a policy. It returns whether the request is allowed, or an error if it could not be evaluated.
+type Rule interface {
+	Evaluate(request *Request) (bool, error)
+}
+
+// RuleFunc is a custom rule.
+type RuleFunc func(request *Request) (bool, error)
+
+func (f RuleFunc) Evaluate(request *Request) (bool, error) { return f(request) }
+
+// Predicate is a custom rule which can't fail.
+func Predicate(predicate func(request *Request) bool) Rule {
+	return RuleFunc(func(request *Request) (bool, error) { return predicate(request), nil })
+}
+
+// HasAnyRole allows the agents with at least one of the given roles.
+func HasAnyRole(roles ...models.TokenAgentRole) Rule {
+	return Predicate(func(request *Request) bool {
+		if request.Token == nil {
+			return false
+		}
+
+		return slices.ContainsFunc(request.Token.AgentRoles, func(role models.TokenAgentRole) bool {
+			return slices.Contains(roles, role)
+		})
+	})
+}
+
+// HasGlobalRole allows the agents with a global role (e.g. staff), who may act on any entity.
+func HasGlobalRole() Rule {
+	return Predicate(func(request *Request) bool {
+		return request.Token != nil && request.Token.AgentRoles.ContainsGlobalRole()
+	})
+}
+
+// ActionIn allows the requests with one of the given actions (e.g. ActionGet).
+func ActionIn(actions ...string) Rule {
+	return Predicate(func(request *Request) bool { return slices.Contains(actions, request.Action) })
+}
+
+// AllOf allows the requests allowed by every given rule. Rules are evaluated in order, until one denies the request.
+func AllOf(rules ...Rule) Rule {
+	return RuleFunc(func(request *Request) (bool, error) {
+		for _, rule := range rules {
+			if allowed, err := rule.Evaluate(request); err != nil || !allowed {
+				return false, err
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// AnyOf allows the requests allowed by at least one of the given rules. Rules are evaluated in order, until one allows
+// the request.
+func AnyOf(rules ...Rule) Rule {
+	return RuleFunc(func(request *Request) (bool, error) {
+		for _, rule := range rules {
+			if allowed, err := rule.Evaluate(request); err != nil || allowed {
+				return allowed, err
+			}
+		}
+
+		return false, nil
+	})
+}
+
+func Not(rule Rule) Rule {
+	return RuleFunc(func(request *Request) (bool, error) {
+		allowed, err := rule.Evaluate(request)
+
+		return !allowed && err == nil, err
+	})
+}
+
+// Policy evaluates a rule, and caches its decisions if configured to.
+type Policy struct {
+	name          string
+	rule          Rule
+	cache         cache.ReadWriter
+	cacheDuration time.Duration
+}
+
+type PolicyConfig struct {
+	cache         cache.ReadWriter
+	cacheDuration time.Duration
+}
+
+type PolicyOption func(config *PolicyConfig)
+
+// WithPolicyCache caches the decisions for the given duration. Errors are not cached. Decisions are cached by policy
+// name, agent, roles, entity, resource and action, so rules must not depend on anything else.
+func WithPolicyCache(cache cache.ReadWriter, duration time.Duration) PolicyOption {
+	return func(config *PolicyConfig) {
+		config.cache = cache
+		config.cacheDuration = duration
+	}
+}
+
+// New creates a policy with the given name, which must be unique among the policies sharing a cache.
+func New(name string, rule Rule, options ...PolicyOption) *Policy {
+	config := &PolicyConfig{}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return &Policy{
+		name:          name,
+		rule:          rule,
+		cache:         config.cache,
+		cacheDuration: config.cacheDuration,
+	}
+}
+
+var _ Rule = (*Policy)(nil)
+
+// Evaluate the rule of the policy, so policies can be composed.
+func (p *Policy) Evaluate(request *Request) (bool, error) {
+	if p.cache == nil {
+		return p.rule.Evaluate(request)
+	}
+
+	key := p.makeDecisionCacheKey(request)
+	if cachedResult, ok := p.cache.Read(key).(bool); ok {
+		return cachedResult, nil
+	}
+
+	result, err := p.rule.Evaluate(request)
+	if err != nil {
+		return false, err
+	}
+
+	p.cache.WriteWithDuration(key, result, p.cacheDuration)
+
+	return result, nil
+}
+
+func (p *Policy) makeDecisionCacheKey(request *Request) string {
+	agentID, roles := uuid.Nil.String(), []string{}
+	if request.Token != nil {
+		agentID = request.Token.AgentID.String()
+
+		for _, role := range request.Token.AgentRoles {
+			roles = append(roles, string(role))
+		}
+	}
+
+	slices.Sort(roles)
+
+	return strings.Join([]string{
+		"policy",
+		p.name,
+		agentID,
+		strings.Join(roles, ","),
+		request.EntityID.String(),
+		request.Resource,
+		request.Action,
+	}, ":")
+}
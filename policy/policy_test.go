@@ -0,0 +1,257 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/restest"
+	"github.com/loungeup/go-loungeup/cache"
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/resmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testResellerID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	testChainID    = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	testGroupID    = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	testAccountID  = uuid.MustParse("00000000-0000-0000-0000-000000000004")
+	testOtherID    = uuid.MustParse("00000000-0000-0000-0000-000000000005")
+)
+
+func TestRules(t *testing.T) {
+	agent := &models.Token{AgentID: uuid.New(), AgentRoles: models.TokenAgentRoleSlice{models.TokenAgentRoleAgent}}
+	staff := &models.Token{AgentID: uuid.New(), AgentRoles: models.TokenAgentRoleSlice{models.TokenAgentRoleStaff}}
+
+	tests := map[string]struct {
+		rule Rule
+		in   *Request
+		want bool
+	}{
+		"any role":       {rule: HasAnyRole(models.TokenAgentRoleAgent), in: &Request{Token: agent}, want: true},
+		"other role":     {rule: HasAnyRole(models.TokenAgentRoleAgent), in: &Request{Token: staff}, want: false},
+		"no token":       {rule: HasAnyRole(models.TokenAgentRoleAgent), in: &Request{}, want: false},
+		"global role":    {rule: HasGlobalRole(), in: &Request{Token: staff}, want: true},
+		"no global role": {rule: HasGlobalRole(), in: &Request{Token: agent}, want: false},
+		"action":         {rule: ActionIn(ActionGet), in: &Request{Action: ActionGet}, want: true},
+		"other action":   {rule: ActionIn(ActionGet), in: &Request{Action: "delete"}, want: false},
+		"all of": {
+			rule: AllOf(HasGlobalRole(), ActionIn("delete")),
+			in:   &Request{Token: staff, Action: "delete"},
+			want: true,
+		},
+		"not all of": {
+			rule: AllOf(HasGlobalRole(), ActionIn("delete")),
+			in:   &Request{Token: agent, Action: "delete"},
+			want: false,
+		},
+		"any of": {
+			rule: AnyOf(HasGlobalRole(), ActionIn(ActionGet)),
+			in:   &Request{Token: agent, Action: ActionGet},
+			want: true,
+		},
+		"none of": {
+			rule: AnyOf(HasGlobalRole(), ActionIn(ActionGet)),
+			in:   &Request{Token: agent, Action: "set"},
+			want: false,
+		},
+		"not": {rule: Not(HasGlobalRole()), in: &Request{Token: agent}, want: true},
+		"custom predicate": {
+			rule: Predicate(func(r *Request) bool { return r.Resource == "foo" }),
+			in:   &Request{Resource: "foo"},
+			want: true,
+		},
+		"nested composition": {
+			rule: AnyOf(AllOf(Not(HasGlobalRole()), ActionIn(ActionGet))),
+			in:   &Request{Token: agent, Action: ActionGet},
+			want: true,
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, err := tt.rule.Evaluate(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		failingRule := RuleFunc(func(*Request) (bool, error) { return false, assert.AnError })
+
+		for _, rule := range []Rule{AllOf(failingRule), AnyOf(failingRule), Not(failingRule)} {
+			got, err := rule.Evaluate(&Request{})
+			assert.ErrorIs(t, err, assert.AnError)
+			assert.False(t, got)
+		}
+	})
+}
+
+func TestIsEntityMember(t *testing.T) {
+	rule := IsEntityMember(newTestEntitiesReader(), &agentEntitiesReaderMock{
+		ReadAgentEntityIDsFunc: func(uuid.UUID) (uuid.UUIDs, error) { return uuid.UUIDs{testResellerID}, nil },
+	})
+
+	tests := map[string]struct {
+		in   uuid.UUID
+		want bool
+	}{
+		"own entity":        {in: testResellerID, want: true},
+		"chain of reseller": {in: testChainID, want: true},
+		"account of chain":  {in: testAccountID, want: true},
+		"other entity":      {in: testOtherID, want: false},
+		"no entity":         {in: uuid.Nil, want: false},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, err := rule.Evaluate(&Request{Token: &models.Token{AgentID: uuid.New()}, EntityID: tt.in})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPolicyCache(t *testing.T) {
+	evaluations := 0
+	cachedValues := map[string]any{}
+
+	policy := New("test", Predicate(func(*Request) bool {
+		evaluations++
+
+		return true
+	}), WithPolicyCache(&cache.Mock{
+		ReadFunc: func(key string) any { return cachedValues[key] },
+		WriteWithDurationFunc: func(key string, value any, _ time.Duration) {
+			cachedValues[key] = value
+		},
+	}, time.Minute))
+
+	token := &models.Token{AgentID: uuid.New()}
+
+	for range 2 {
+		require.NoError(t, policy.Authorize(&Request{Token: token, EntityID: testChainID, Action: ActionGet}))
+	}
+
+	assert.Equal(t, 1, evaluations)
+
+	require.NoError(t, policy.Authorize(&Request{Token: token, EntityID: testChainID, Action: "set"}))
+	assert.Equal(t, 2, evaluations)
+}
+
+func TestPolicyRES(t *testing.T) {
+	policy := New("test", AnyOf(
+		HasGlobalRole(),
+		AllOf(
+			ActionIn(ActionGet, "set"),
+			IsEntityMember(newTestEntitiesReader(), &agentEntitiesReaderMock{
+				ReadAgentEntityIDsFunc: func(uuid.UUID) (uuid.UUIDs, error) { return uuid.UUIDs{testGroupID}, nil },
+			}),
+		),
+	))
+
+	parseEntityIDFunc := func(resource res.Resource) (uuid.UUID, error) {
+		result, err := uuid.Parse(resource.PathParam("entityID"))
+		if err != nil {
+			return uuid.Nil, &errors.Error{Code: errors.CodeInvalid, UnderlyingError: err}
+		}
+
+		return result, nil
+	}
+
+	service := res.NewService("authority")
+	service.Handle("entities.$entityID",
+		res.Access(policy.AccessHandler(parseEntityIDFunc, "set", "delete")),
+		res.Call("delete", policy.CallGuard(parseEntityIDFunc, func(request res.CallRequest) { request.OK(nil) })),
+	)
+
+	session := restest.NewSession(t, service)
+	defer session.Close()
+
+	agentToken := json.RawMessage(`{"agentRoles": ["agent"]}`)
+	staffToken := json.RawMessage(`{"agentRoles": ["staff"]}`)
+
+	session.Access("authority.entities."+testAccountID.String(), &restest.Request{Token: agentToken}).
+		Response().AssertAccess(true, "set")
+	session.Access("authority.entities."+testOtherID.String(), &restest.Request{Token: agentToken}).
+		Response().AssertError(res.ErrAccessDenied)
+	session.Access("authority.entities."+testOtherID.String(), &restest.Request{Token: staffToken}).
+		Response().AssertAccess(true, "set,delete")
+
+	session.Call("authority.entities."+testAccountID.String(), "delete", &restest.Request{Token: agentToken}).
+		Response().AssertError(res.ErrAccessDenied)
+	session.Call("authority.entities."+testAccountID.String(), "delete", &restest.Request{Token: staffToken}).
+		Response().AssertResult(nil)
+}
+
+// newTestEntitiesReader reads a reseller, its chain, a group of the chain, an account of both, and an unrelated account.
+func newTestEntitiesReader() *entitiesReaderMock {
+	entities := map[uuid.UUID]*resmodels.Entity{
+		testResellerID: {ID: testResellerID, Type: resmodels.EntityTypeReseller},
+		testChainID: {
+			ID:       testChainID,
+			Type:     resmodels.EntityTypeChain,
+			Reseller: res.SoftRef("authority.entities." + testResellerID.String()),
+		},
+		testGroupID: {
+			ID:    testGroupID,
+			Type:  resmodels.EntityTypeGroup,
+			Chain: res.SoftRef("authority.entities." + testChainID.String()),
+		},
+		testAccountID: {
+			ID:    testAccountID,
+			Type:  resmodels.EntityTypeAccount,
+			Chain: res.SoftRef("authority.entities." + testChainID.String()),
+			Group: res.SoftRef("authority.entities." + testGroupID.String()),
+		},
+		testOtherID: {ID: testOtherID, Type: resmodels.EntityTypeAccount},
+	}
+
+	return &entitiesReaderMock{
+		ReadEntityFunc: func(selector *resmodels.EntitySelector) (*resmodels.Entity, error) {
+			if result, ok := entities[selector.EntityID]; ok {
+				return result, nil
+			}
+
+			return nil, &errors.Error{Code: errors.CodeNotFound}
+		},
+		ReadAccountParentsFunc: func(selector *resmodels.EntitySelector) ([]*resmodels.Entity, error) {
+			account := entities[selector.EntityID]
+
+			result := []*resmodels.Entity{}
+			for _, id := range []uuid.UUID{account.ChainID(), account.GroupID()} {
+				if parent, ok := entities[id]; ok {
+					result = append(result, parent)
+				}
+			}
+
+			return result, nil
+		},
+	}
+}
+
+type entitiesReaderMock struct {
+	ReadEntityFunc         func(selector *resmodels.EntitySelector) (*resmodels.Entity, error)
+	ReadAccountParentsFunc func(selector *resmodels.EntitySelector) ([]*resmodels.Entity, error)
+}
+
+func (m *entitiesReaderMock) ReadEntity(selector *resmodels.EntitySelector) (*resmodels.Entity, error) {
+	return m.ReadEntityFunc(selector)
+}
+
+func (m *entitiesReaderMock) ReadAccountParents(selector *resmodels.EntitySelector) ([]*resmodels.Entity, error) {
+	return m.ReadAccountParentsFunc(selector)
+}
+
+type agentEntitiesReaderMock struct {
+	ReadAgentEntityIDsFunc func(agentID uuid.UUID) (uuid.UUIDs, error)
+}
+
+func (m *agentEntitiesReaderMock) ReadAgentEntityIDs(agentID uuid.UUID) (uuid.UUIDs, error) {
+	return m.ReadAgentEntityIDsFunc(agentID)
+}
@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jirenius/go-res"
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/log"
+)
+
+// ParseEntityIDFunc returns the ID of the entity a RES request acts on (e.g. with resutil.ParseUUIDPathParam).
+type ParseEntityIDFunc func(resource res.Resource) (uuid.UUID, error)
+
+// Authorize the given request. It returns res.ErrAccessDenied if the request is denied.
+func (p *Policy) Authorize(request *Request) error {
+	allowed, err := p.Evaluate(request)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return res.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CallGuard only calls the given handler if the policy allows the call. The action of the request is the method of
+// the call. The parse entity ID function is optional.
+func (p *Policy) CallGuard(parseEntityIDFunc ParseEntityIDFunc, next res.CallHandler) res.CallHandler {
+	return func(request res.CallRequest) {
+		policyRequest, err := newRESRequest(request, request.ParseToken, parseEntityIDFunc, request.Method())
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		allowed, err := p.Evaluate(policyRequest)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		if !allowed {
+			request.Error(res.ErrAccessDenied)
+
+			return
+		}
+
+		next(request)
+	}
+}
+
+// AccessHandler answers the RES access requests with the policy: get access is granted if the policy allows the
+// ActionGet action, and call access is granted for the given methods the policy allows. The parse entity ID function
+// is optional.
+func (p *Policy) AccessHandler(parseEntityIDFunc ParseEntityIDFunc, methods ...string) res.AccessHandler {
+	return func(request res.AccessRequest) {
+		policyRequest, err := newRESRequest(request, request.ParseToken, parseEntityIDFunc, ActionGet)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		get, err := p.Evaluate(policyRequest)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		allowedMethods := []string{}
+
+		for _, method := range methods {
+			policyRequest.Action = method
+
+			allowed, err := p.Evaluate(policyRequest)
+			if err != nil {
+				errors.LogAndWriteRESError(log.Default(), request, err)
+
+				return
+			}
+
+			if allowed {
+				allowedMethods = append(allowedMethods, method)
+			}
+		}
+
+		if !get && len(allowedMethods) == 0 {
+			request.AccessDenied()
+
+			return
+		}
+
+		request.Access(get, strings.Join(allowedMethods, ","))
+	}
+}
+
+// AccessGuard only calls the given handler if the policy allows the access request, which is denied otherwise. The
+// policy is evaluated once for every action, with the ActionGet action, so its rules must not depend on the action
+// (e.g. an entity scope). The parse entity ID function is optional.
+func (p *Policy) AccessGuard(parseEntityIDFunc ParseEntityIDFunc, next res.AccessHandler) res.AccessHandler {
+	return func(request res.AccessRequest) {
+		policyRequest, err := newRESRequest(request, request.ParseToken, parseEntityIDFunc, ActionGet)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		allowed, err := p.Evaluate(policyRequest)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		if !allowed {
+			request.AccessDenied()
+
+			return
+		}
+
+		next(request)
+	}
+}
+
+func newRESRequest(
+	resource res.Resource,
+	parseTokenFunc func(v any),
+	parseEntityIDFunc ParseEntityIDFunc,
+	action string,
+) (*Request, error) {
+	result := &Request{
+		Token:    &models.Token{},
+		Resource: resource.ResourceName(),
+		Action:   action,
+	}
+
+	parseTokenFunc(result.Token)
+
+	if parseEntityIDFunc != nil {
+		entityID, err := parseEntityIDFunc(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		result.EntityID = entityID
+	}
+
+	return result, nil
+}
@@ -0,0 +1,18 @@
+package resmodels
+
+import "github.com/jirenius/go-res"
+
+// PaginatedCollection is the envelope of a page of a collection. Unlike a bare collection, it tells clients whether more
+// pages exist, so they don't have to request an empty page to find out.
+type PaginatedCollection struct {
+	// Items are the references to the models of the page.
+	Items *res.DataValue[[]res.Ref] `json:"items"`
+	// NextCursor is the last key of the page, to pass as the 'lastKey' query parameter to read the next page.
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	// Total is the number of models in the collection, when it is cheap to count.
+	Total *int `json:"total,omitempty"`
+	// EstimatedTotal is an estimation of the number of models in the collection, when counting them is expensive (e.g.
+	// the lower bound of the total hits of an Elasticsearch search).
+	EstimatedTotal *int `json:"estimatedTotal,omitempty"`
+}
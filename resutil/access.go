@@ -0,0 +1,83 @@
+package resutil
+
+import (
+	"slices"
+
+	"github.com/jirenius/go-res"
+	lumodels "github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/policy"
+)
+
+type AccessConfig struct {
+	methods           []string
+	rulesByAction     map[string][]policy.Rule
+	parseEntityIDFunc policy.ParseEntityIDFunc
+	entityRule        policy.Rule
+	policyOptions     []policy.PolicyOption
+}
+
+type AccessOption func(config *AccessConfig)
+
+// WithAccessRoles grants the given actions (policy.ActionGet or the methods of calls) to the agents with at least one
+// of the given roles.
+func WithAccessRoles(roles lumodels.TokenAgentRoleSlice, actions ...string) AccessOption {
+	return func(config *AccessConfig) {
+		for _, action := range actions {
+			config.addActionRule(action, policy.HasAnyRole(roles...))
+		}
+	}
+}
+
+// WithAccessPolicy grants the given action (policy.ActionGet or the method of a call) to the requests allowed by the
+// given rule. Passing the policy given to the call guard of the method keeps both consistent.
+func WithAccessPolicy(action string, rule policy.Rule) AccessOption {
+	return func(config *AccessConfig) { config.addActionRule(action, rule) }
+}
+
+// WithAccessEntityScope only grants the actions if the given rule (e.g. policy.IsEntityMember) also allows the request.
+// The entity of the request is parsed with the given function.
+func WithAccessEntityScope(parseEntityIDFunc policy.ParseEntityIDFunc, rule policy.Rule) AccessOption {
+	return func(config *AccessConfig) {
+		config.parseEntityIDFunc = parseEntityIDFunc
+		config.entityRule = rule
+	}
+}
+
+// WithAccessPolicyOptions configures the policies of the handler (e.g. policy.WithPolicyCache).
+func WithAccessPolicyOptions(options ...policy.PolicyOption) AccessOption {
+	return func(config *AccessConfig) { config.policyOptions = append(config.policyOptions, options...) }
+}
+
+func (c *AccessConfig) addActionRule(action string, rule policy.Rule) {
+	if action != policy.ActionGet && !slices.Contains(c.methods, action) {
+		c.methods = append(c.methods, action)
+	}
+
+	c.rulesByAction[action] = append(c.rulesByAction[action], rule)
+}
+
+// UseAccessHandler returns a handler answering the RES access requests with the token of the agents. An action is
+// granted if one of its roles or policies allows it and, if configured, the entity scope allows the request too.
+// Actions without roles nor policies are denied.
+func UseAccessHandler(options ...AccessOption) res.AccessHandler {
+	config := &AccessConfig{rulesByAction: map[string][]policy.Rule{}}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	rule := policy.RuleFunc(func(request *policy.Request) (bool, error) {
+		return policy.AnyOf(config.rulesByAction[request.Action]...).Evaluate(request)
+	})
+
+	result := policy.New("resutil.access", rule, config.policyOptions...).
+		AccessHandler(config.parseEntityIDFunc, config.methods...)
+
+	if config.entityRule == nil {
+		return result
+	}
+
+	// The entity scope does not depend on the action, so it is evaluated once per request rather than per action.
+	return policy.New("resutil.access.entity", config.entityRule, config.policyOptions...).
+		AccessGuard(config.parseEntityIDFunc, result)
+}
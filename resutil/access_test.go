@@ -0,0 +1,96 @@
+package resutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/restest"
+	"github.com/loungeup/go-loungeup/cache"
+	lumodels "github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseAccessHandler(t *testing.T) {
+	memberEntityID, otherEntityID := uuid.New(), uuid.New()
+	scopeEvaluations := 0
+
+	deletePolicy := policy.New("delete", policy.HasAnyRole(lumodels.TokenAgentRoleStaff))
+
+	service := res.NewService("entities-manager")
+	service.Handle("entities.$entityID",
+		res.Access(UseAccessHandler(
+			WithAccessRoles(lumodels.TokenAgentRoleSlice{lumodels.TokenAgentRoleAgent}, policy.ActionGet),
+			WithAccessRoles(lumodels.TokenAgentRoleSlice{lumodels.TokenAgentRoleStaff}, policy.ActionGet, "set"),
+			WithAccessPolicy("delete", deletePolicy),
+			WithAccessEntityScope(
+				func(resource res.Resource) (uuid.UUID, error) { return ParseUUIDPathParam(resource, "entityID") },
+				policy.AnyOf(policy.HasGlobalRole(), policy.Predicate(func(request *policy.Request) bool {
+					scopeEvaluations++
+
+					return request.EntityID == memberEntityID
+				})),
+			),
+		)),
+	)
+
+	session := restest.NewSession(t, service)
+	defer session.Close()
+
+	agentToken := json.RawMessage(`{"agentRoles": ["agent"]}`)
+	staffToken := json.RawMessage(`{"agentRoles": ["staff"]}`)
+
+	session.Access("entities-manager.entities."+memberEntityID.String(), &restest.Request{Token: agentToken}).
+		Response().AssertAccess(true, "")
+	session.Access("entities-manager.entities."+otherEntityID.String(), &restest.Request{Token: agentToken}).
+		Response().AssertError(res.ErrAccessDenied)
+	session.Access("entities-manager.entities."+otherEntityID.String(), &restest.Request{Token: staffToken}).
+		Response().AssertAccess(true, "set,delete")
+	assert.Equal(t, 2, scopeEvaluations, "the entity scope must be evaluated once per request")
+	session.Access("entities-manager.entities."+memberEntityID.String(), &restest.Request{}).
+		Response().AssertError(res.ErrAccessDenied)
+	session.Access("entities-manager.entities.foo", &restest.Request{Token: staffToken}).
+		Response().AssertErrorCode(res.CodeInvalidParams)
+}
+
+func TestUseAccessHandlerPolicyOptions(t *testing.T) {
+	entityID := uuid.New()
+	scopeEvaluations := 0
+	cachedValues := map[string]any{}
+
+	service := res.NewService("entities-manager")
+	service.Handle("entities.$entityID",
+		res.Access(UseAccessHandler(
+			WithAccessRoles(lumodels.TokenAgentRoleSlice{lumodels.TokenAgentRoleAgent}, policy.ActionGet, "set"),
+			WithAccessEntityScope(
+				func(resource res.Resource) (uuid.UUID, error) { return ParseUUIDPathParam(resource, "entityID") },
+				policy.Predicate(func(*policy.Request) bool {
+					scopeEvaluations++
+
+					return true
+				}),
+			),
+			WithAccessPolicyOptions(policy.WithPolicyCache(&cache.Mock{
+				ReadFunc: func(key string) any { return cachedValues[key] },
+				WriteWithDurationFunc: func(key string, value any, _ time.Duration) {
+					cachedValues[key] = value
+				},
+			}, time.Minute)),
+		)),
+	)
+
+	session := restest.NewSession(t, service)
+	defer session.Close()
+
+	token := json.RawMessage(`{"agentId": "` + uuid.NewString() + `", "agentRoles": ["agent"]}`)
+
+	for range 2 {
+		session.Access("entities-manager.entities."+entityID.String(), &restest.Request{Token: token}).
+			Response().AssertAccess(true, "set")
+	}
+
+	assert.Equal(t, 1, scopeEvaluations)
+}
@@ -9,6 +9,7 @@ import (
 	lumodels "github.com/loungeup/go-loungeup/client/models"
 	"github.com/loungeup/go-loungeup/errors"
 	"github.com/loungeup/go-loungeup/log"
+	"github.com/loungeup/go-loungeup/resmodels"
 )
 
 type (
@@ -18,6 +19,13 @@ type (
 		ReadCollection(selector Selector) (Collection, error)
 	}
 
+	GetPaginatedCollectionProvider[Collection ~[]Model, Model, Selector any] interface {
+		MakeCollectionQuery(selector Selector) url.Values
+		MakeModelRID(model Model) string
+		ParseCollectionSelector(resource res.Resource) (Selector, error)
+		ReadCollectionPage(selector Selector) (*CollectionPage[Collection, Model], error)
+	}
+
 	GetModelProvider[Model, Selector any] interface {
 		MakeModelQuery(selector Selector) url.Values
 		ParseModelSelector(resource res.Resource) (Selector, error)
@@ -69,6 +77,51 @@ func UseGetCollectionHandler[Collection ~[]Model, Model, Selector any](
 	}
 }
 
+// CollectionPage read by a GetPaginatedCollectionProvider. NextCursor is the encoded last key of the page (e.g. with a
+// pagination.CursorCodec). Total and EstimatedTotal are optional.
+type CollectionPage[Collection ~[]Model, Model any] struct {
+	Items          Collection
+	NextCursor     string
+	HasMore        bool
+	Total          *int
+	EstimatedTotal *int
+}
+
+// UseGetPaginatedCollectionHandler works like UseGetCollectionHandler, but serves a resmodels.PaginatedCollection model
+// referencing the models of the page, instead of a bare collection.
+func UseGetPaginatedCollectionHandler[Collection ~[]Model, Model, Selector any](
+	provider GetPaginatedCollectionProvider[Collection, Model, Selector],
+) res.ModelHandler {
+	return func(request res.ModelRequest) {
+		selector, err := provider.ParseCollectionSelector(request)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		page, err := provider.ReadCollectionPage(selector)
+		if err != nil {
+			errors.LogAndWriteRESError(log.Default(), request, err)
+
+			return
+		}
+
+		items := []res.Ref{}
+		for _, model := range page.Items {
+			items = append(items, res.Ref(provider.MakeModelRID(model)))
+		}
+
+		request.QueryModel(&resmodels.PaginatedCollection{
+			Items:          &res.DataValue[[]res.Ref]{Data: items},
+			NextCursor:     page.NextCursor,
+			HasMore:        page.HasMore,
+			Total:          page.Total,
+			EstimatedTotal: page.EstimatedTotal,
+		}, provider.MakeCollectionQuery(selector).Encode())
+	}
+}
+
 func UseGetModelHandler[Model, Selector any](provider GetModelProvider[Model, Selector]) res.ModelHandler {
 	return func(request res.ModelRequest) {
 		selector, err := provider.ParseModelSelector(request)
@@ -0,0 +1,77 @@
+package resutil
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/restest"
+	"github.com/loungeup/go-loungeup/pagination"
+)
+
+func TestUseGetPaginatedCollectionHandler(t *testing.T) {
+	service := res.NewService("users-manager")
+	service.Handle("users",
+		testAccessHandler,
+		res.GetModel(UseGetPaginatedCollectionHandler(&usersPaginatedCollectionProvider{})),
+	)
+
+	session := restest.NewSession(t, service)
+	defer session.Close()
+
+	session.Get("users-manager.users?size=2").Response().AssertModel(json.RawMessage(`{
+		"items": {"data": [{"rid": "users-manager.users.john.doe"}, {"rid": "users-manager.users.jane.doe"}]},
+		"nextCursor": "jane.doe",
+		"hasMore": true,
+		"total": 3
+	}`))
+	session.Get("users-manager.users?lastKey=jane.doe&size=2").Response().AssertModel(json.RawMessage(`{
+		"items": {"data": [{"rid": "users-manager.users.max.doe"}]},
+		"hasMore": false,
+		"total": 3
+	}`))
+}
+
+type usersPaginatedCollectionProvider struct{}
+
+func (p *usersPaginatedCollectionProvider) MakeCollectionQuery(selector *pagination.KeysetSelector[string]) url.Values {
+	return selector.Query()
+}
+
+func (p *usersPaginatedCollectionProvider) MakeModelRID(model string) string {
+	return "users-manager.users." + model
+}
+
+func (p *usersPaginatedCollectionProvider) ParseCollectionSelector(
+	resource res.Resource,
+) (*pagination.KeysetSelector[string], error) {
+	return pagination.ParseKeysetSelector(resource.ParseQuery(), func(key string) (string, error) { return key, nil })
+}
+
+func (p *usersPaginatedCollectionProvider) ReadCollectionPage(
+	selector *pagination.KeysetSelector[string],
+) (*CollectionPage[[]string, string], error) {
+	users := []string{"john.doe", "jane.doe", "max.doe"}
+	total := len(users)
+
+	start := 0
+	for i, user := range users {
+		if user == selector.LastKey {
+			start = i + 1
+		}
+	}
+
+	end := min(start+selector.Size, total)
+	result := &CollectionPage[[]string, string]{
+		Items:   users[start:end],
+		HasMore: end < total,
+		Total:   &total,
+	}
+
+	if result.HasMore {
+		result.NextCursor = users[end-1]
+	}
+
+	return result, nil
+}
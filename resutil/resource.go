@@ -0,0 +1,291 @@
+package resutil
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/jirenius/go-res"
+	lumodels "github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/log"
+)
+
+// Methods of the calls registered by a Resource.
+const (
+	ResourceMethodCreate = "create"
+	ResourceMethodDelete = "delete"
+	ResourceMethodSet    = "set"
+)
+
+type ResourceProvider[Model, Selector, Params any] interface {
+	GetModelProvider[Model, Selector]
+	CreateModelProvider[Model, Params]
+	UpdateModelProvider[Model, Selector, Params]
+	DeleteModelProvider[Selector]
+}
+
+// resourceCollectionProvider is implemented by the providers of resources belonging to a collection, so a Resource
+// sends add and remove events to the collection.
+type resourceCollectionProvider[Model any] interface {
+	// MakeCollectionRID returns the RID of the collection containing the given model.
+	MakeCollectionRID(model Model) string
+	// ReadCollectionRIDs returns the RIDs of the models of the collection containing the given model, in order.
+	ReadCollectionRIDs(model Model) ([]string, error)
+}
+
+// Resource registers the get, set, create and delete handlers of a model resource against a RES service in one call,
+// and emits the matching events: a change event on set, a delete event on delete, and, if the provider implements
+// MakeCollectionRID and ReadCollectionRIDs, add and remove events on the collection containing the model. A set moving
+// the model within its sorted collection, or to another collection, sends a remove and an add event.
+type Resource[Model, Selector, Params any] struct {
+	provider          ResourceProvider[Model, Selector, Params]
+	modelPattern      string
+	modelOptions      []res.Option
+	collectionPattern string
+	collectionOptions []res.Option
+	rolesByMethod     map[string]lumodels.TokenAgentRoleSlice
+}
+
+type ResourceConfig struct {
+	modelOptions      []res.Option
+	collectionPattern string
+	collectionOptions []res.Option
+	rolesByMethod     map[string]lumodels.TokenAgentRoleSlice
+}
+
+type ResourceOption func(config *ResourceConfig)
+
+// WithResourceModelOptions adds the given options (e.g. res.Access or other calls) to the handler of the model pattern.
+func WithResourceModelOptions(options ...res.Option) ResourceOption {
+	return func(config *ResourceConfig) { config.modelOptions = append(config.modelOptions, options...) }
+}
+
+// WithResourceCollectionPattern registers the create call on the given collection pattern, with the given options
+// (e.g. res.Access and res.GetCollection).
+func WithResourceCollectionPattern(pattern string, options ...res.Option) ResourceOption {
+	return func(config *ResourceConfig) {
+		config.collectionPattern = pattern
+		config.collectionOptions = append(config.collectionOptions, options...)
+	}
+}
+
+// WithResourceRoles guards the call with the given method (e.g. ResourceMethodCreate) with WithRolesGuard.
+func WithResourceRoles(method string, roles lumodels.TokenAgentRoleSlice) ResourceOption {
+	return func(config *ResourceConfig) { config.rolesByMethod[method] = roles }
+}
+
+// NewResource creates a resource whose models are served on the given pattern (e.g. "users.$userID").
+func NewResource[Model, Selector, Params any](
+	provider ResourceProvider[Model, Selector, Params],
+	modelPattern string,
+	options ...ResourceOption,
+) *Resource[Model, Selector, Params] {
+	config := &ResourceConfig{rolesByMethod: map[string]lumodels.TokenAgentRoleSlice{}}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return &Resource[Model, Selector, Params]{
+		provider:          provider,
+		modelPattern:      modelPattern,
+		modelOptions:      config.modelOptions,
+		collectionPattern: config.collectionPattern,
+		collectionOptions: config.collectionOptions,
+		rolesByMethod:     config.rolesByMethod,
+	}
+}
+
+// Register the handlers of the resource against the given service.
+func (r *Resource[Model, Selector, Params]) Register(service *res.Service) {
+	service.Handle(r.modelPattern, slices.Concat(r.modelOptions, []res.Option{
+		res.GetModel(UseGetModelHandler[Model, Selector](r.provider)),
+		res.Call(ResourceMethodSet, r.guard(ResourceMethodSet, r.withCollectionEvents(
+			func(provider ResourceProvider[Model, Selector, Params]) res.CallHandler {
+				return UseUpdateModelHandler[Model, Selector, Params](provider)
+			},
+		))),
+		res.Call(ResourceMethodDelete, r.guard(ResourceMethodDelete, r.withCollectionEvents(
+			func(provider ResourceProvider[Model, Selector, Params]) res.CallHandler {
+				return UseDeleteModelHandler[Selector](provider)
+			},
+		))),
+	})...)
+
+	if r.collectionPattern != "" {
+		service.Handle(r.collectionPattern, slices.Concat(r.collectionOptions, []res.Option{
+			res.Call(ResourceMethodCreate, r.guard(ResourceMethodCreate, r.withCollectionEvents(
+				func(provider ResourceProvider[Model, Selector, Params]) res.CallHandler {
+					return UseCreateModelHandler[Model, Params](provider)
+				},
+			))),
+		})...)
+	}
+}
+
+// withCollectionEvents returns the handler made by the given function. If the provider implements MakeCollectionRID
+// and ReadCollectionRIDs, the handler is made for each call with a provider recording the positions of the model in
+// its collection, and the add and remove events are sent after it.
+func (r *Resource[Model, Selector, Params]) withCollectionEvents(
+	useHandler func(provider ResourceProvider[Model, Selector, Params]) res.CallHandler,
+) res.CallHandler {
+	collectionProvider, ok := r.provider.(resourceCollectionProvider[Model])
+	if !ok {
+		return useHandler(r.provider)
+	}
+
+	return func(request res.CallRequest) {
+		recorder := &collectionEventsRecorder[Model, Selector, Params]{
+			ResourceProvider:   r.provider,
+			collectionProvider: collectionProvider,
+		}
+
+		useHandler(recorder)(request)
+		recorder.sendEvents(request.Service())
+	}
+}
+
+// collectionPosition of a model, used to send the add and remove events of its collection.
+type collectionPosition struct {
+	collectionRID string
+	rid           string
+	index         int
+}
+
+// collectionEventsRecorder wraps the provider of a Resource during a call, to record where the created, updated or
+// deleted model was and is in its collection.
+type collectionEventsRecorder[Model, Selector, Params any] struct {
+	ResourceProvider[Model, Selector, Params]
+	collectionProvider resourceCollectionProvider[Model]
+
+	removed *collectionPosition
+	added   *collectionPosition
+}
+
+var _ modelMapper[any] = (*collectionEventsRecorder[any, any, any])(nil)
+
+// MapModel with the wrapped provider, so the change events are the same as without the recorder.
+func (r *collectionEventsRecorder[Model, Selector, Params]) MapModel(model Model) any {
+	return eventuallyMapModel(r.ResourceProvider, model)
+}
+
+func (r *collectionEventsRecorder[Model, Selector, Params]) CreateModel(params Params) (Model, error) {
+	result, err := r.ResourceProvider.CreateModel(params)
+	if err != nil {
+		return result, err
+	}
+
+	r.added = r.readPositionAfterChange(result)
+
+	return result, nil
+}
+
+// UpdateModel records a remove and an add event if the update moves the model within or out of its collection.
+func (r *collectionEventsRecorder[Model, Selector, Params]) UpdateModel(
+	existingModel Model,
+	params Params,
+) (Model, error) {
+	existingPosition, err := r.readPosition(existingModel)
+	if err != nil {
+		var zero Model
+
+		return zero, err
+	}
+
+	result, err := r.ResourceProvider.UpdateModel(existingModel, params)
+	if err != nil {
+		return result, err
+	}
+
+	updatedPosition := r.readPositionAfterChange(result)
+	if existingPosition != nil && updatedPosition != nil && *existingPosition == *updatedPosition {
+		return result, nil
+	}
+
+	r.removed = existingPosition
+	r.added = updatedPosition
+
+	return result, nil
+}
+
+// DeleteModel reads the position of the model before deleting it.
+func (r *collectionEventsRecorder[Model, Selector, Params]) DeleteModel(selector Selector) error {
+	model, err := r.ReadModel(selector)
+	if err != nil {
+		return err
+	}
+
+	position, err := r.readPosition(model)
+	if err != nil {
+		return err
+	}
+
+	if err := r.ResourceProvider.DeleteModel(selector); err != nil {
+		return err
+	}
+
+	r.removed = position
+
+	return nil
+}
+
+func (r *collectionEventsRecorder[Model, Selector, Params]) sendEvents(service *res.Service) {
+	if position := r.removed; position != nil {
+		withCollection(service, position.collectionRID, func(resource res.Resource) {
+			resource.RemoveEvent(position.index)
+		})
+	}
+
+	if position := r.added; position != nil {
+		withCollection(service, position.collectionRID, func(resource res.Resource) {
+			resource.AddEvent(res.Ref(position.rid), position.index)
+		})
+	}
+}
+
+// readPosition of the given model in its collection, or nil if it is not in the collection.
+func (r *collectionEventsRecorder[Model, Selector, Params]) readPosition(model Model) (*collectionPosition, error) {
+	rids, err := r.collectionProvider.ReadCollectionRIDs(model)
+	if err != nil {
+		return nil, err
+	}
+
+	rid := r.MakeModelRID(model)
+
+	index := slices.Index(rids, rid)
+	if index < 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return &collectionPosition{
+		collectionRID: r.collectionProvider.MakeCollectionRID(model),
+		rid:           rid,
+		index:         index,
+	}, nil
+}
+
+// readPositionAfterChange works like readPosition, but only logs errors since the model has already been changed.
+func (r *collectionEventsRecorder[Model, Selector, Params]) readPositionAfterChange(model Model) *collectionPosition {
+	result, err := r.readPosition(model)
+	if err != nil {
+		log.Default().Error("Could not read collection RIDs",
+			slog.Any("error", err),
+			slog.String("rid", r.MakeModelRID(model)),
+		)
+	}
+
+	return result
+}
+
+func (r *Resource[Model, Selector, Params]) guard(method string, handler res.CallHandler) res.CallHandler {
+	if roles, ok := r.rolesByMethod[method]; ok {
+		return WithRolesGuard(roles, handler)
+	}
+
+	return handler
+}
+
+func withCollection(service *res.Service, rid string, handler func(resource res.Resource)) {
+	if err := service.With(rid, handler); err != nil {
+		log.Default().Error("Could not send collection event", slog.Any("error", err), slog.String("rid", rid))
+	}
+}
@@ -0,0 +1,131 @@
+package resutil
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jirenius/go-res"
+	"github.com/jirenius/go-res/restest"
+	lumodels "github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+func TestResource(t *testing.T) {
+	service := res.NewService("users-manager")
+	NewResource[*testUser, string, *testUser](
+		&testUsersProvider{users: []*testUser{{ID: "john", Name: "John"}, {ID: "max", Name: "Max"}}},
+		"users.$userID",
+		WithResourceModelOptions(testAccessHandler),
+		WithResourceCollectionPattern("users", testAccessHandler),
+		WithResourceRoles(ResourceMethodDelete, lumodels.TokenAgentRoleSlice{lumodels.TokenAgentRoleStaff}),
+	).Register(service)
+
+	session := restest.NewSession(t, service)
+	defer session.Close()
+
+	session.Get("users-manager.users.john").Response().AssertModel(json.RawMessage(`{"id": "john", "name": "John"}`))
+
+	session.Call("users-manager.users", ResourceMethodCreate, &restest.Request{
+		Params: json.RawMessage(`{"id": "jane", "name": "Jane"}`),
+	}).Response().AssertResource("users-manager.users.jane")
+	session.GetMsg().AssertAddEvent("users-manager.users", res.Ref("users-manager.users.jane"), 0)
+
+	session.Call("users-manager.users.jane", ResourceMethodSet, &restest.Request{
+		Params: json.RawMessage(`{"name": "Jane Doe"}`),
+	}).Response().AssertResource("users-manager.users.jane")
+	session.GetMsg().AssertChangeEvent("users-manager.users.jane", json.RawMessage(`{"name": "Jane Doe"}`))
+
+	// Renaming Jane moves her to the end of the collection sorted by name.
+	session.Call("users-manager.users.jane", ResourceMethodSet, &restest.Request{
+		Params: json.RawMessage(`{"name": "Zoe"}`),
+	}).Response().AssertResource("users-manager.users.jane")
+	session.GetMsg().AssertChangeEvent("users-manager.users.jane", json.RawMessage(`{"name": "Zoe"}`))
+	session.GetMsg().AssertRemoveEvent("users-manager.users", 0)
+	session.GetMsg().AssertAddEvent("users-manager.users", res.Ref("users-manager.users.jane"), 2)
+
+	session.Call("users-manager.users.jane", ResourceMethodDelete, &restest.Request{
+		Token: json.RawMessage(`{"agentRoles": ["agent"]}`),
+	}).Response().AssertError(res.ErrAccessDenied)
+
+	session.Call("users-manager.users.jane", ResourceMethodDelete, &restest.Request{
+		Token: json.RawMessage(`{"agentRoles": ["staff"]}`),
+	}).Response().AssertResult(nil)
+	session.GetMsg().AssertDeleteEvent("users-manager.users.jane")
+	session.GetMsg().AssertRemoveEvent("users-manager.users", 2)
+
+	session.Get("users-manager.users.jane").Response().AssertErrorCode(res.CodeNotFound)
+}
+
+type testUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// testUsersProvider keeps the users sorted by name.
+type testUsersProvider struct{ users []*testUser }
+
+func (p *testUsersProvider) MakeModelQuery(string) url.Values { return url.Values{} }
+
+func (p *testUsersProvider) MakeModelRID(model *testUser) string {
+	return "users-manager.users." + model.ID
+}
+
+func (p *testUsersProvider) ParseModelSelector(resource res.Resource) (string, error) {
+	return resource.PathParam("userID"), nil
+}
+
+func (p *testUsersProvider) ParseModelParams(request res.CallRequest) (*testUser, error) {
+	result := &testUser{}
+	request.ParseParams(result)
+
+	return result, nil
+}
+
+func (p *testUsersProvider) ReadModel(selector string) (*testUser, error) {
+	for _, user := range p.users {
+		if user.ID == selector {
+			return user, nil
+		}
+	}
+
+	return nil, &errors.Error{Code: errors.CodeNotFound}
+}
+
+func (p *testUsersProvider) CreateModel(params *testUser) (*testUser, error) {
+	p.users = append(p.users, params)
+	p.sortUsers()
+
+	return params, nil
+}
+
+func (p *testUsersProvider) UpdateModel(existingModel, params *testUser) (*testUser, error) {
+	result := &testUser{ID: existingModel.ID, Name: params.Name}
+	p.users[slices.Index(p.users, existingModel)] = result
+	p.sortUsers()
+
+	return result, nil
+}
+
+func (p *testUsersProvider) DeleteModel(selector string) error {
+	p.users = slices.DeleteFunc(p.users, func(user *testUser) bool { return user.ID == selector })
+
+	return nil
+}
+
+func (p *testUsersProvider) sortUsers() {
+	slices.SortFunc(p.users, func(a, b *testUser) int { return strings.Compare(a.Name, b.Name) })
+}
+
+func (p *testUsersProvider) MakeCollectionRID(*testUser) string { return "users-manager.users" }
+
+func (p *testUsersProvider) ReadCollectionRIDs(*testUser) ([]string, error) {
+	result := []string{}
+	for _, user := range p.users {
+		result = append(result, p.MakeModelRID(user))
+	}
+
+	return result, nil
+}
@@ -7,11 +7,13 @@ import (
 	"encoding/json"
 	"log/slog"
 	"reflect"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jirenius/go-res"
 	"github.com/loungeup/go-loungeup/errors"
 	"github.com/loungeup/go-loungeup/log"
+	"github.com/loungeup/go-loungeup/validation"
 	"github.com/nats-io/nats.go"
 )
 
@@ -54,6 +56,12 @@ func NewRequestWithParams[T any](params T) *RequestWithParams[T] {
 	return &RequestWithParams[T]{Params: params}
 }
 
+// Validate the params with the rules of their "validate" struct tags. See the validation package.
+func (r *RequestWithParams[T]) Validate() error { return validation.Validate(r.Params) }
+
+// ParseRequestParams from the given request data. It returns a zero value if the data could not be decoded.
+//
+// Deprecated: Use ParseValidRequestParams, which reports decoding and validation errors.
 func ParseRequestParams[T any](data []byte) T {
 	request := &RequestWithParams[T]{}
 	if err := json.Unmarshal(data, request); err != nil {
@@ -65,6 +73,44 @@ func ParseRequestParams[T any](data []byte) T {
 	return request.Params
 }
 
+// ParseValidRequestParams from the given request data, and validates them with the rules of their "validate" struct
+// tags. It returns an error with the errors.CodeInvalid code, and the invalid fields, if the params are invalid.
+func ParseValidRequestParams[T any](data []byte) (T, error) {
+	request := &RequestWithParams[T]{}
+	if err := json.Unmarshal(data, request); err != nil {
+		return request.Params, newInvalidParamsError(err, "params.")
+	}
+
+	return request.Params, request.Validate()
+}
+
+// ParseCallRequestParams works like ParseValidRequestParams with the params of the given call request. It can be used
+// to implement the ParseModelParams method of the providers.
+func ParseCallRequestParams[T any](request res.CallRequest) (T, error) {
+	var result T
+
+	if params := request.RawParams(); len(params) > 0 {
+		if err := json.Unmarshal(params, &result); err != nil {
+			return result, newInvalidParamsError(err, "")
+		}
+	}
+
+	return result, validation.Validate(result)
+}
+
+func newInvalidParamsError(err error, fieldPrefix string) error {
+	result := &errors.Error{Code: errors.CodeInvalid, Message: "Invalid params", UnderlyingError: err}
+
+	if typeError := (&json.UnmarshalTypeError{}); errors.As(err, &typeError) && typeError.Field != "" {
+		result.Fields = []*errors.FieldError{{
+			Field:   strings.TrimPrefix(typeError.Field, fieldPrefix),
+			Message: "Must be of type " + typeError.Type.String(),
+		}}
+	}
+
+	return result
+}
+
 const natsMessagesChannelSize = 64
 
 type Deletable[T any] struct {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jirenius/go-res"
 	"github.com/jirenius/go-res/restest"
+	"github.com/loungeup/go-loungeup/errors"
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
 )
@@ -79,6 +80,53 @@ func TestDeletable(t *testing.T) {
 	}
 }
 
+func TestParseValidRequestParams(t *testing.T) {
+	type params struct {
+		Name     string `json:"name" validate:"required"`
+		Quantity int    `json:"quantity" validate:"min=1"`
+	}
+
+	tests := map[string]struct {
+		in         string
+		want       params
+		wantFields []*errors.FieldError
+	}{
+		"valid": {
+			in:   `{"params": {"name": "foo", "quantity": 2}}`,
+			want: params{Name: "foo", Quantity: 2},
+		},
+		"invalid": {
+			in:   `{"params": {"quantity": -1}}`,
+			want: params{Quantity: -1},
+			wantFields: []*errors.FieldError{
+				{Field: "name", Message: "Required"},
+				{Field: "quantity", Message: "Must be at least 1"},
+			},
+		},
+		"wrong type": {
+			in:         `{"params": {"name": "foo", "quantity": "2"}}`,
+			want:       params{Name: "foo"},
+			wantFields: []*errors.FieldError{{Field: "quantity", Message: "Must be of type int"}},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			got, err := ParseValidRequestParams[params]([]byte(tt.in))
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantFields == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+			assert.Equal(t, tt.wantFields, err.(*errors.Error).Fields)
+		})
+	}
+}
+
 func TestAddNATSMessageHandler(t *testing.T) {
 	service := res.NewService("test")
 	service.Handle("test.foo", testAccessHandler)
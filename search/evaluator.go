@@ -0,0 +1,521 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+const aDay = 24 * time.Hour
+
+// Evaluator evaluates search conditions against a guest and its bookings, without Elasticsearch.
+//
+// Like the Elasticsearch documents, which contain a booking and its guest, the conditions are evaluated against each
+// booking of the guest. The guest matches if the conditions match one of its bookings, or the guest alone when it has
+// no booking.
+type Evaluator struct {
+	now func() time.Time
+}
+
+type EvaluatorOption func(*Evaluator)
+
+func NewEvaluator(options ...EvaluatorOption) *Evaluator {
+	result := &Evaluator{now: time.Now}
+	for _, option := range options {
+		option(result)
+	}
+
+	return result
+}
+
+// WithEvaluatorNow sets the function returning the current time, used to resolve keyword and relative dates.
+func WithEvaluatorNow(now func() time.Time) EvaluatorOption {
+	return func(e *Evaluator) { e.now = now }
+}
+
+// Evaluation of search conditions.
+type Evaluation struct {
+	Matched bool
+
+	// Booking against which the conditions were evaluated: the first matching booking if any, the first booking
+	// otherwise. It is nil if the guest has no booking.
+	Booking *models.Booking
+
+	// Criteria evaluated against the booking, in the order of the conditions.
+	Criteria []*CriteriaEvaluation
+}
+
+// CriteriaEvaluation reports whether a criteria matched.
+type CriteriaEvaluation struct {
+	// Location of the criteria as a JSON path (e.g. "$.criteria[0].criteria[1]").
+	Location string
+	Criteria *models.SearchCriteria
+	Matched  bool
+}
+
+// Evaluate the given conditions against the given guest and bookings. Nil bookings are skipped. It fails if the guest
+// is nil, or if the conditions use unsupported fields, operators or values.
+func (e *Evaluator) Evaluate(
+	conditions *models.SearchConditions,
+	guest *models.Guest,
+	bookings []*models.Booking,
+) (*Evaluation, error) {
+	if guest == nil {
+		return nil, &errors.Error{Code: errors.CodeInvalid, Message: "Could not evaluate conditions without guest"}
+	}
+
+	bookings = slices.DeleteFunc(slices.Clone(bookings), func(booking *models.Booking) bool { return booking == nil })
+
+	subject := &evaluationSubject{guest: guest, bookings: bookings, today: e.now().UTC().Truncate(aDay)}
+
+	if len(bookings) == 0 {
+		return subject.evaluate(conditions)
+	}
+
+	var result *Evaluation
+
+	for _, booking := range bookings {
+		subject.booking = booking
+
+		evaluation, err := subject.evaluate(conditions)
+		if err != nil {
+			return nil, err
+		}
+
+		if evaluation.Matched {
+			return evaluation, nil
+		}
+
+		if result == nil {
+			result = evaluation
+		}
+	}
+
+	return result, nil
+}
+
+type evaluationSubject struct {
+	guest    *models.Guest
+	booking  *models.Booking
+	bookings []*models.Booking
+	today    time.Time
+}
+
+func (s *evaluationSubject) evaluate(conditions *models.SearchConditions) (*Evaluation, error) {
+	result := &Evaluation{Booking: s.booking, Criteria: []*CriteriaEvaluation{}}
+
+	groupsMatched := []bool{}
+
+	for i, criterion := range conditions.Criteria {
+		criteriaMatched := []bool{}
+
+		for j, criteria := range criterion.Criteria {
+			location := "$.criteria[" + strconv.Itoa(i) + "].criteria[" + strconv.Itoa(j) + "]"
+
+			matched, err := s.evaluateCriteria(criteria)
+			if err != nil {
+				return nil, &errors.Error{
+					Code:            errors.CodeInvalid,
+					Message:         fmt.Sprintf("Could not evaluate %s: %s", location, err),
+					UnderlyingError: err,
+				}
+			}
+
+			criteriaMatched = append(criteriaMatched, matched)
+			result.Criteria = append(result.Criteria, &CriteriaEvaluation{
+				Location: location,
+				Criteria: criteria,
+				Matched:  matched,
+			})
+		}
+
+		groupsMatched = append(groupsMatched, combineMatches(criterion.Logic, criteriaMatched))
+	}
+
+	result.Matched = combineMatches(conditions.Logic, groupsMatched)
+
+	return result, nil
+}
+
+func combineMatches(logic string, matches []bool) bool {
+	if strings.EqualFold(logic, string(models.SearchGuestsLogicOr)) {
+		return slices.Contains(matches, true)
+	}
+
+	return !slices.Contains(matches, false)
+}
+
+type evaluatedFieldKind int
+
+const (
+	evaluatedFieldKindKeyword evaluatedFieldKind = iota
+	evaluatedFieldKindNumber
+	evaluatedFieldKindDate
+	evaluatedFieldKindBoolean
+)
+
+type evaluatedField struct {
+	kind   evaluatedFieldKind
+	values func(s *evaluationSubject) []any
+}
+
+var evaluatedFields = map[models.SearchCriteriaField]evaluatedField{
+	models.SearchCriteriaFieldCity: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return addressValues(s.guest, func(address models.Address) string { return address.City })
+	}},
+	models.SearchCriteriaFieldCountry: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return addressValues(s.guest, func(address models.Address) string { return address.Country })
+	}},
+	models.SearchCriteriaFieldZipCode: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return addressValues(s.guest, func(address models.Address) string { return address.ZipCode })
+	}},
+	models.SearchCriteriaFieldLastName: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return stringValues(s.guest.Lastname.Data.Value)
+	}},
+	models.SearchCriteriaFieldEmail: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		result := []any{}
+		for _, email := range s.guest.Emails.Data {
+			result = append(result, stringValues(email.Value.Email)...)
+		}
+
+		return result
+	}},
+	models.SearchCriteriaFieldLangs: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		result := []any{}
+		for _, language := range s.guest.Languages.Data {
+			result = append(result, stringValues(language.Value)...)
+		}
+
+		return result
+	}},
+	models.SearchCriteriaFieldTags: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return stringValues(s.guest.Tags.Data...)
+	}},
+	models.SearchCriteriaFieldGuestTags: {evaluatedFieldKindKeyword, func(s *evaluationSubject) []any {
+		return stringValues(s.guest.Tags.Data...)
+	}},
+	models.SearchCriteriaFieldBirthdate: {evaluatedFieldKindDate, func(s *evaluationSubject) []any {
+		return dateValues(s.guest.Birthdate.Data.Value.BirthDate)
+	}},
+	models.SearchCriteriaFieldArrival: {evaluatedFieldKindDate, func(s *evaluationSubject) []any {
+		if s.booking == nil {
+			return nil
+		}
+
+		return dateValues(s.booking.Arrival)
+	}},
+	models.SearchCriteriaFieldDeparture: {evaluatedFieldKindDate, func(s *evaluationSubject) []any {
+		if s.booking == nil {
+			return nil
+		}
+
+		return dateValues(s.booking.Departure)
+	}},
+	models.SearchCriteriaFieldBookingDate: {evaluatedFieldKindDate, func(s *evaluationSubject) []any {
+		if s.booking == nil {
+			return nil
+		}
+
+		return dateValues(s.booking.BookingDate)
+	}},
+	models.SearchCriteriaFieldInStayDate: {evaluatedFieldKindDate, func(s *evaluationSubject) []any {
+		if s.booking == nil {
+			return nil
+		}
+
+		return dateValues(s.booking.InStayDates()...)
+	}},
+	models.SearchCriteriaFieldStayLength: {evaluatedFieldKindNumber, func(s *evaluationSubject) []any {
+		if s.booking == nil || s.booking.Arrival.IsZero() || s.booking.Departure.IsZero() {
+			return nil
+		}
+
+		return []any{float64(s.booking.Departure.Truncate(aDay).Sub(s.booking.Arrival.Truncate(aDay)) / aDay)}
+	}},
+	models.SearchCriteriaFieldInStay: {evaluatedFieldKindBoolean, func(s *evaluationSubject) []any {
+		return []any{s.booking != nil && isInStay(s.booking, s.today)}
+	}},
+	models.SearchCriteriaFieldNbStays: {evaluatedFieldKindNumber, func(s *evaluationSubject) []any {
+		return []any{float64(len(s.bookings))}
+	}},
+	models.SearchCriteriaFieldNbPreviousStays: {evaluatedFieldKindNumber, func(s *evaluationSubject) []any {
+		return []any{float64(countBookings(s.bookings, func(booking *models.Booking) bool {
+			return booking.Departure.Truncate(aDay).Before(s.today)
+		}))}
+	}},
+	models.SearchCriteriaFieldNbNextStays: {evaluatedFieldKindNumber, func(s *evaluationSubject) []any {
+		return []any{float64(countBookings(s.bookings, func(booking *models.Booking) bool {
+			return booking.Arrival.Truncate(aDay).After(s.today)
+		}))}
+	}},
+}
+
+func (s *evaluationSubject) evaluateCriteria(criteria *models.SearchCriteria) (bool, error) {
+	switch criteria.Operator {
+	case models.SearchOperatorAnonymous:
+		return !s.guest.AnonymizedAt.IsZero(), nil
+	case models.SearchOperatorNotAnonymous:
+		return s.guest.AnonymizedAt.IsZero(), nil
+	}
+
+	field, ok := evaluatedFields[criteria.Field]
+	if !ok {
+		return false, fmt.Errorf("unsupported field %q", criteria.Field)
+	}
+
+	values := field.values(s)
+
+	switch criteria.Operator {
+	case models.SearchOperatorFilled:
+		if field.kind != evaluatedFieldKindBoolean {
+			return len(values) > 0, nil
+		}
+	case models.SearchOperatorNotFilled:
+		if field.kind != evaluatedFieldKindBoolean {
+			return len(values) == 0, nil
+		}
+	}
+
+	switch field.kind {
+	case evaluatedFieldKindKeyword:
+		return evaluateKeywordCriteria(criteria, values)
+	case evaluatedFieldKindNumber:
+		return evaluateNumberCriteria(criteria, values)
+	case evaluatedFieldKindDate:
+		return s.evaluateDateCriteria(criteria, values)
+	default:
+		return evaluateBooleanCriteria(criteria, values)
+	}
+}
+
+// evaluateKeywordCriteria like the queries of the esutil search compiler: equality is case-sensitive, like term
+// queries, while contains and starts with are not, like the case-insensitive wildcard and prefix queries.
+func evaluateKeywordCriteria(criteria *models.SearchCriteria, values []any) (bool, error) {
+	wantValues, ok := models.ParseSearchStrings(criteria.Value)
+	if !ok || len(wantValues) == 0 {
+		return false, fmt.Errorf("invalid value %v", criteria.Value)
+	}
+
+	anyValue := func(match func(value, wantValue string) bool) bool {
+		for _, value := range values {
+			for _, wantValue := range wantValues {
+				if match(value.(string), wantValue) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	isEqual := func(value, wantValue string) bool { return value == wantValue }
+	contains := func(value, wantValue string) bool {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(wantValue))
+	}
+	hasPrefix := func(value, wantValue string) bool {
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(wantValue))
+	}
+
+	switch criteria.Operator {
+	case models.SearchOperatorEquals:
+		return anyValue(isEqual), nil
+	case models.SearchOperatorNotEquals:
+		return !anyValue(isEqual), nil
+	case models.SearchOperatorContains:
+		return anyValue(contains), nil
+	case models.SearchOperatorNotContains:
+		return !anyValue(contains), nil
+	case models.SearchOperatorStartsWith:
+		return anyValue(hasPrefix), nil
+	case models.SearchOperatorNotStartsWith:
+		return !anyValue(hasPrefix), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", criteria.Operator)
+	}
+}
+
+func evaluateNumberCriteria(criteria *models.SearchCriteria, values []any) (bool, error) {
+	var match func(value float64) bool
+
+	// Like the must_not queries of the esutil search compiler, "not equals" matches when no value equals.
+	if criteria.Operator == models.SearchOperatorNotEquals {
+		matched, err := evaluateNumberCriteria(&models.SearchCriteria{
+			Field:    criteria.Field,
+			Operator: models.SearchOperatorEquals,
+			Value:    criteria.Value,
+		}, values)
+
+		return !matched, err
+	}
+
+	if criteria.Operator == models.SearchOperatorRange {
+		bounds, ok := models.ParseSearchRange(criteria.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid range %v", criteria.Value)
+		}
+
+		from, fromOK := models.ParseSearchNumber(bounds[0])
+		to, toOK := models.ParseSearchNumber(bounds[1])
+
+		if !fromOK || !toOK {
+			return false, fmt.Errorf("invalid range %v", criteria.Value)
+		}
+
+		match = func(value float64) bool { return value >= from && value <= to }
+	} else {
+		wantValue, ok := models.ParseSearchNumber(criteria.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid value %v", criteria.Value)
+		}
+
+		switch criteria.Operator {
+		case models.SearchOperatorEquals:
+			match = func(value float64) bool { return value == wantValue }
+		case models.SearchOperatorInferior:
+			match = func(value float64) bool { return value < wantValue }
+		case models.SearchOperatorSuperior:
+			match = func(value float64) bool { return value > wantValue }
+		default:
+			return false, fmt.Errorf("unsupported operator %q", criteria.Operator)
+		}
+	}
+
+	return slices.ContainsFunc(values, func(value any) bool { return match(value.(float64)) }), nil
+}
+
+// evaluateDateCriteria on whole days. The dates of the criteria are either formatted as "2006-01-02" or are keywords
+// (e.g. "today") resolved with SearchKeywordDate.Duration.
+func (s *evaluationSubject) evaluateDateCriteria(criteria *models.SearchCriteria, values []any) (bool, error) {
+	// Each operator matches the days in [from, to), where a zero bound is unlimited.
+	var from, to time.Time
+
+	switch criteria.Operator {
+	case models.SearchOperatorRange:
+		bounds, ok := models.ParseSearchRange(criteria.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid range %v", criteria.Value)
+		}
+
+		rangeFrom, fromOK := models.ParseSearchDate(bounds[0], s.today)
+		rangeTo, toOK := models.ParseSearchDate(bounds[1], s.today)
+
+		if !fromOK || !toOK {
+			return false, fmt.Errorf("invalid range %v", criteria.Value)
+		}
+
+		from, to = rangeFrom, rangeTo.Add(aDay)
+	case models.SearchOperatorDateAwayMore, models.SearchOperatorDateAwayLess:
+		days, ok := models.ParseSearchNumber(criteria.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid number of days %v", criteria.Value)
+		}
+
+		limit := s.today.AddDate(0, 0, int(days))
+
+		if criteria.Operator == models.SearchOperatorDateAwayMore {
+			from = limit
+		} else {
+			from, to = s.today, limit
+		}
+	default:
+		if criteria.Operator == models.SearchOperatorDateAfterThanKeywordDay ||
+			criteria.Operator == models.SearchOperatorDateBeforeThanKeywordDay {
+			if models.ParseSearchKeywordDate(criteria.Value) == models.SearchKeywordDateUnknown {
+				return false, fmt.Errorf("invalid keyword date %v", criteria.Value)
+			}
+		}
+
+		day, ok := models.ParseSearchDate(criteria.Value, s.today)
+		if !ok {
+			return false, fmt.Errorf("invalid date %v", criteria.Value)
+		}
+
+		switch criteria.Operator {
+		case models.SearchOperatorDateEqual:
+			from, to = day, day.Add(aDay)
+		case models.SearchOperatorDateAfterThan, models.SearchOperatorDateAfterThanKeywordDay:
+			from = day.Add(aDay)
+		case models.SearchOperatorDateAfterThanEqual:
+			from = day
+		case models.SearchOperatorDateBeforeThan, models.SearchOperatorDateBeforeThanKeywordDay:
+			to = day
+		case models.SearchOperatorDateBeforeThanEqual:
+			to = day.Add(aDay)
+		default:
+			return false, fmt.Errorf("unsupported operator %q", criteria.Operator)
+		}
+	}
+
+	return slices.ContainsFunc(values, func(value any) bool {
+		day := value.(time.Time)
+
+		return (from.IsZero() || !day.Before(from)) && (to.IsZero() || day.Before(to))
+	}), nil
+}
+
+func evaluateBooleanCriteria(criteria *models.SearchCriteria, values []any) (bool, error) {
+	switch criteria.Operator {
+	case models.SearchOperatorYes:
+		return slices.Contains(values, any(true)), nil
+	case models.SearchOperatorNo:
+		return !slices.Contains(values, any(true)), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", criteria.Operator)
+	}
+}
+
+func addressValues(guest *models.Guest, value func(address models.Address) string) []any {
+	result := []any{}
+	for _, address := range guest.Addresses.Data {
+		result = append(result, stringValues(value(address.Value))...)
+	}
+
+	return result
+}
+
+// stringValues returns the non-empty given values.
+func stringValues(values ...string) []any {
+	result := []any{}
+
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// dateValues returns the days of the non-zero given times.
+func dateValues(values ...time.Time) []any {
+	result := []any{}
+
+	for _, value := range values {
+		if !value.IsZero() {
+			result = append(result, value.UTC().Truncate(aDay))
+		}
+	}
+
+	return result
+}
+
+func isInStay(booking *models.Booking, today time.Time) bool {
+	return !booking.Arrival.Truncate(aDay).After(today) && !booking.Departure.Truncate(aDay).Before(today)
+}
+
+func countBookings(bookings []*models.Booking, match func(booking *models.Booking) bool) int {
+	result := 0
+
+	for _, booking := range bookings {
+		if match(booking) {
+			result++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,35 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateNumberCriteria(t *testing.T) {
+	tests := map[string]struct {
+		operator models.SearchCriteriaOperator
+		values   []any
+		want     bool
+	}{
+		"equals one of several values":     {operator: models.SearchOperatorEquals, values: []any{2.0, 3.0}, want: true},
+		"not equals one of several values": {operator: models.SearchOperatorNotEquals, values: []any{2.0, 3.0}},
+		"not equals any value":             {operator: models.SearchOperatorNotEquals, values: []any{3.0, 4.0}, want: true},
+		"equals without values":            {operator: models.SearchOperatorEquals, values: []any{}},
+		"not equals without values":        {operator: models.SearchOperatorNotEquals, values: []any{}, want: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := evaluateNumberCriteria(&models.SearchCriteria{
+				Field:    models.SearchCriteriaFieldNbStays,
+				Operator: tt.operator,
+				Value:    float64(2),
+			}, tt.values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
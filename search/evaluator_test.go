@@ -0,0 +1,145 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluator(t *testing.T) {
+	evaluator := search.NewEvaluator(search.WithEvaluatorNow(func() time.Time {
+		return time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	}))
+
+	guest := &models.Guest{
+		Lastname: models.NewDataValue(models.StructuredValue[string]{Value: "Dupont"}),
+		Emails: models.NewDataValue(models.StructuredValueSlice[models.Email]{
+			{Value: models.Email{Email: "jean.dupont@example.com"}},
+		}),
+		Addresses: models.NewDataValue(models.StructuredValueSlice[models.Address]{
+			{Value: models.Address{City: "Paris", Country: "FR"}},
+		}),
+		Tags: models.NewDataValue([]string{"vip", "golf"}),
+	}
+
+	bookings := []*models.Booking{
+		{
+			ID:        1,
+			Arrival:   time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+			Departure: time.Date(2023, 7, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:        2,
+			Arrival:   time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC),
+			Departure: time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := map[string]struct {
+		in          string
+		bookings    []*models.Booking
+		wantMatched bool
+		wantBooking int
+		wantMatches []bool
+	}{
+		"guest fields": {
+			in:          `country = "FR" AND lastname S "dup" AND email % "@example" AND tags = ["vip","spa"]`,
+			bookings:    bookings,
+			wantMatched: true,
+			wantBooking: 1,
+			wantMatches: []bool{true, true, true, true},
+		},
+		// Like Elasticsearch, equality is case-sensitive while contains and starts with are not.
+		"keyword case": {
+			in:          `country = "fr" OR lastname S "DUP" OR email % "EXAMPLE" OR tags != 1`,
+			bookings:    bookings,
+			wantMatched: true,
+			wantBooking: 1,
+			wantMatches: []bool{false, true, true, true},
+		},
+		"booking fields on the same booking": {
+			in:          `arrival &= "yesterday" AND staylength = 2 AND instay Y`,
+			bookings:    bookings,
+			wantMatched: true,
+			wantBooking: 2,
+			wantMatches: []bool{true, true, true},
+		},
+		"booking fields on different bookings": {
+			in:          `arrival &< "2024-01-01" AND instay Y`,
+			bookings:    bookings,
+			wantMatched: false,
+			wantBooking: 1,
+			wantMatches: []bool{true, false},
+		},
+		"date ranges": {
+			in:          `departure RANGE ["2024-03-01","tomorrow"] AND departure - 7 AND departure &>% "today"`,
+			bookings:    bookings,
+			wantMatched: true,
+			wantBooking: 2,
+			wantMatches: []bool{true, true, true},
+		},
+		"aggregations": {
+			in:          `(nbstays > 2 OR nbpreviousstays = 1) AND nbnextstays = 0`,
+			bookings:    bookings,
+			wantMatched: true,
+			wantBooking: 1,
+			wantMatches: []bool{false, true, true},
+		},
+		"without bookings": {
+			in:          `arrival !F AND city != "Lyon" AND email !@ AND staylength != 2`,
+			wantMatched: true,
+			wantMatches: []bool{true, true, true, true},
+		},
+		"nil bookings": {
+			in:          `nbstays = 1 AND staylength = 2`,
+			bookings:    []*models.Booking{nil, bookings[1], nil},
+			wantMatched: true,
+			wantBooking: 2,
+			wantMatches: []bool{true, true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conditions, err := search.Parse(tt.in)
+			require.NoError(t, err)
+
+			got, err := evaluator.Evaluate(conditions, guest, tt.bookings)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatched, got.Matched)
+
+			if tt.wantBooking == 0 {
+				assert.Nil(t, got.Booking)
+			} else {
+				assert.Equal(t, tt.wantBooking, got.Booking.ID)
+			}
+
+			gotMatches := []bool{}
+			for _, criteria := range got.Criteria {
+				gotMatches = append(gotMatches, criteria.Matched)
+			}
+
+			assert.Equal(t, tt.wantMatches, gotMatches)
+		})
+	}
+
+	t.Run("without guest", func(t *testing.T) {
+		conditions, err := search.Parse(`country = "FR"`)
+		require.NoError(t, err)
+
+		_, err = evaluator.Evaluate(conditions, nil, bookings)
+		assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("unsupported field", func(t *testing.T) {
+		_, err := evaluator.Evaluate(&models.SearchConditions{Criteria: []*models.SearchCriterion{{
+			Criteria: []*models.SearchCriteria{{Field: models.SearchCriteriaFieldSegment, Operator: "=", Value: "1"}},
+		}}}, guest, bookings)
+		assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+	})
+}
@@ -0,0 +1,295 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+// operators of the textual language, sorted so the longest ones are matched first.
+var operators = []models.SearchCriteriaOperator{
+	models.SearchOperatorRange,
+	models.SearchOperatorDow,
+	models.SearchOperatorDateAfterThanEqual,
+	models.SearchOperatorDateAfterThanKeywordDay,
+	models.SearchOperatorDateBeforeThanEqual,
+	models.SearchOperatorDateBeforeThanKeywordDay,
+	models.SearchOperatorDateAfterThan,
+	models.SearchOperatorDateBeforeThan,
+	models.SearchOperatorDateEqual,
+	models.SearchOperatorNotEquals,
+	models.SearchOperatorNotContains,
+	models.SearchOperatorNotAnonymous,
+	models.SearchOperatorNotFilled,
+	models.SearchOperatorNotStartsWith,
+	models.SearchOperatorCampaignNotAnswered,
+	models.SearchOperatorCampaignNotOpened,
+	models.SearchOperatorCampaignNotReceived,
+	models.SearchOperatorNotHadSession,
+	models.SearchOperatorEquals,
+	models.SearchOperatorContains,
+	models.SearchOperatorAnonymous,
+	models.SearchOperatorInferior,
+	models.SearchOperatorSuperior,
+	models.SearchOperatorDateAwayMore,
+	models.SearchOperatorDateAwayLess,
+	models.SearchOperatorFilled,
+	models.SearchOperatorStartsWith,
+	models.SearchOperatorCampaignAnswered,
+	models.SearchOperatorCampaignOpened,
+	models.SearchOperatorCampaignReceived,
+	models.SearchOperatorHadSession,
+	models.SearchOperatorHasCurrentSession,
+	models.SearchOperatorYes,
+	models.SearchOperatorNo,
+}
+
+// Format the given conditions in the textual language. Values are formatted as JSON.
+func Format(conditions *models.SearchConditions) string {
+	groups := []string{}
+
+	for _, criterion := range conditions.Criteria {
+		criteria := []string{}
+		for _, c := range criterion.Criteria {
+			criteria = append(criteria, formatCriteria(c))
+		}
+
+		if len(criteria) == 1 {
+			groups = append(groups, criteria[0])
+		} else {
+			groups = append(groups, "("+strings.Join(criteria, " "+formatLogic(criterion.Logic)+" ")+")")
+		}
+	}
+
+	return strings.Join(groups, " "+formatLogic(conditions.Logic)+" ")
+}
+
+func formatCriteria(criteria *models.SearchCriteria) string {
+	result := string(criteria.Field) + " " + string(criteria.Operator)
+
+	if criteria.Value != nil {
+		value, err := json.Marshal(criteria.Value)
+		if err != nil {
+			value = []byte(fmt.Sprintf("%q", fmt.Sprint(criteria.Value)))
+		}
+
+		result += " " + string(value)
+	}
+
+	return result
+}
+
+func formatLogic(logic string) string {
+	if logic == "" {
+		return string(models.SearchGuestsLogicAnd)
+	}
+
+	return strings.ToUpper(logic)
+}
+
+// Parse conditions written in the textual language, e.g.:
+//
+//	country = "FR" AND (nbstays > 2 OR tags % "vip")
+//
+// A criteria is a field, an operator and a JSON value, which is optional for operators like F or Y. Criteria can be
+// grouped with parentheses, and the groups are combined with AND or OR. A single logic is allowed at each level.
+func Parse(text string) (*models.SearchConditions, error) {
+	p := &parser{text: text}
+
+	result, err := p.parseConditions()
+	if err != nil {
+		return nil, &errors.Error{
+			Code:            errors.CodeInvalid,
+			Message:         fmt.Sprintf("Invalid search query at position %d: %s", p.position, err),
+			UnderlyingError: err,
+		}
+	}
+
+	return result, nil
+}
+
+type parser struct {
+	text     string
+	position int
+}
+
+func (p *parser) parseConditions() (*models.SearchConditions, error) {
+	result := &models.SearchConditions{Criteria: []*models.SearchCriterion{}}
+
+	for {
+		group, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Criteria = append(result.Criteria, group)
+
+		if p.skipSpaces(); p.position == len(p.text) {
+			break
+		}
+
+		logic, err := p.parseLogic(result.Logic)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Logic = logic
+	}
+
+	result.Logic = formatLogic(result.Logic)
+
+	return result, nil
+}
+
+func (p *parser) parseGroup() (*models.SearchCriterion, error) {
+	result := &models.SearchCriterion{Criteria: []*models.SearchCriteria{}}
+
+	if !p.consume("(") {
+		criteria, err := p.parseCriteria()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Logic = string(models.SearchGuestsLogicAnd)
+		result.Criteria = append(result.Criteria, criteria)
+
+		return result, nil
+	}
+
+	for {
+		criteria, err := p.parseCriteria()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Criteria = append(result.Criteria, criteria)
+
+		if p.consume(")") {
+			break
+		}
+
+		logic, err := p.parseLogic(result.Logic)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Logic = logic
+	}
+
+	result.Logic = formatLogic(result.Logic)
+
+	return result, nil
+}
+
+func (p *parser) parseCriteria() (*models.SearchCriteria, error) {
+	field := p.parseWord()
+	if field == "" {
+		return nil, fmt.Errorf("expected a field")
+	}
+
+	operator, ok := p.parseOperator()
+	if !ok {
+		return nil, fmt.Errorf("expected an operator after field %q", field)
+	}
+
+	result := &models.SearchCriteria{Field: models.SearchCriteriaField(field), Operator: operator}
+
+	if p.skipSpaces(); p.position == len(p.text) || p.text[p.position] == ')' || p.peekLogic() {
+		return result, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(p.text[p.position:]))
+	if err := decoder.Decode(&result.Value); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	p.position += int(decoder.InputOffset())
+
+	return result, nil
+}
+
+// parseLogic returns the logic at the current position. It must match the given one, unless it is empty.
+func (p *parser) parseLogic(current string) (string, error) {
+	result := strings.ToUpper(p.parseWord())
+
+	switch models.SearchGuestsLogic(result) {
+	case models.SearchGuestsLogicAnd, models.SearchGuestsLogicOr:
+	default:
+		return "", fmt.Errorf("expected AND or OR, got %q", result)
+	}
+
+	if current != "" && current != result {
+		return "", fmt.Errorf("cannot mix %s and %s without parentheses", current, result)
+	}
+
+	return result, nil
+}
+
+func (p *parser) parseOperator() (models.SearchCriteriaOperator, bool) {
+	p.skipSpaces()
+
+	for _, operator := range operators {
+		end := p.position + len(operator)
+		if !strings.HasPrefix(p.text[p.position:], string(operator)) {
+			continue
+		}
+
+		// Operators made of letters or digits must not be followed by another one (e.g. "F" in "FOO").
+		if isWordRune(rune(operator[len(operator)-1])) && end < len(p.text) && isWordRune(rune(p.text[end])) {
+			continue
+		}
+
+		p.position = end
+
+		return operator, true
+	}
+
+	return "", false
+}
+
+func (p *parser) parseWord() string {
+	p.skipSpaces()
+
+	start := p.position
+	for p.position < len(p.text) && isWordRune(rune(p.text[p.position])) {
+		p.position++
+	}
+
+	return p.text[start:p.position]
+}
+
+func (p *parser) peekLogic() bool {
+	start := p.position
+	defer func() { p.position = start }()
+
+	switch models.SearchGuestsLogic(strings.ToUpper(p.parseWord())) {
+	case models.SearchGuestsLogicAnd, models.SearchGuestsLogicOr:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	p.skipSpaces()
+
+	if !strings.HasPrefix(p.text[p.position:], token) {
+		return false
+	}
+
+	p.position += len(token)
+
+	return true
+}
+
+func (p *parser) skipSpaces() {
+	for p.position < len(p.text) && unicode.IsSpace(rune(p.text[p.position])) {
+		p.position++
+	}
+}
+
+func isWordRune(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
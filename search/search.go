@@ -0,0 +1,451 @@
+// Package search provides a fluent builder and a small textual language for guest search conditions.
+//
+// The conditions are made of groups of criteria: the criteria of a group are combined with the logic of the group,
+// and the groups are combined with the logic of the conditions. For example:
+//
+//	country = "FR" AND (nbstays > 2 OR tags % "vip")
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+type fields struct {
+	App                  models.SearchCriteriaField
+	Arrival              models.SearchCriteriaField
+	ArrivalDOW           models.SearchCriteriaField
+	Balance              models.SearchCriteriaField
+	Birthdate            models.SearchCriteriaField
+	Birthday             models.SearchCriteriaField
+	BookingDate          models.SearchCriteriaField
+	BookingID            models.SearchCriteriaField
+	BookingPurpose       models.SearchCriteriaField
+	BookingStatus        models.SearchCriteriaField
+	BookingStatusDiff    models.SearchCriteriaField
+	BookingTags          models.SearchCriteriaField
+	BookingWindow        models.SearchCriteriaField
+	CampaignApp          models.SearchCriteriaField
+	CampaignEmail        models.SearchCriteriaField
+	CampaignNewsletter   models.SearchCriteriaField
+	CampaignScheduled    models.SearchCriteriaField
+	CampaignSearch       models.SearchCriteriaField
+	CampaignSMS          models.SearchCriteriaField
+	CampaignWhatsApp     models.SearchCriteriaField
+	Channel              models.SearchCriteriaField
+	City                 models.SearchCriteriaField
+	Company              models.SearchCriteriaField
+	Country              models.SearchCriteriaField
+	CustomFieldsBooking  models.SearchCriteriaField
+	CustomFieldsGuest    models.SearchCriteriaField
+	Departure            models.SearchCriteriaField
+	DepartureDOW         models.SearchCriteriaField
+	Email                models.SearchCriteriaField
+	EmailCollected       models.SearchCriteriaField
+	EmailDomain          models.SearchCriteriaField
+	EntityGuestUUIDs     models.SearchCriteriaField
+	EntityID             models.SearchCriteriaField
+	EntityObjectUUID     models.SearchCriteriaField
+	Fare                 models.SearchCriteriaField
+	FareAvg              models.SearchCriteriaField
+	FareCode             models.SearchCriteriaField
+	FareRatio            models.SearchCriteriaField
+	FareSum              models.SearchCriteriaField
+	Fidelity             models.SearchCriteriaField
+	FidelityStatus       models.SearchCriteriaField
+	GuestTags            models.SearchCriteriaField
+	GuestUUID            models.SearchCriteriaField
+	HasFacebook          models.SearchCriteriaField
+	HasLinkedIn          models.SearchCriteriaField
+	HasPersonnalEmail    models.SearchCriteriaField
+	HasTwitter           models.SearchCriteriaField
+	IDMasterResa         models.SearchCriteriaField
+	IDResa               models.SearchCriteriaField
+	InStay               models.SearchCriteriaField
+	InStayDate           models.SearchCriteriaField
+	InStayDates          models.SearchCriteriaField
+	Langs                models.SearchCriteriaField
+	LastConnexion        models.SearchCriteriaField
+	LastName             models.SearchCriteriaField
+	LinkedInFollowers    models.SearchCriteriaField
+	Metadata             models.SearchCriteriaField
+	Nationality          models.SearchCriteriaField
+	NbNextStays          models.SearchCriteriaField
+	NbPreviousStays      models.SearchCriteriaField
+	NbStays              models.SearchCriteriaField
+	NextStay             models.SearchCriteriaField
+	OptinAuto            models.SearchCriteriaField
+	OptinCustomerAccount models.SearchCriteriaField
+	OptinLoyalty         models.SearchCriteriaField
+	OptinMarketing       models.SearchCriteriaField
+	OptinSendInBlue      models.SearchCriteriaField
+	Partner              models.SearchCriteriaField
+	PaxAdults            models.SearchCriteriaField
+	PaxBabies            models.SearchCriteriaField
+	PaxChildren          models.SearchCriteriaField
+	Phone                models.SearchCriteriaField
+	PreferredEmail       models.SearchCriteriaField
+	PreviousStay         models.SearchCriteriaField
+	PushNotification     models.SearchCriteriaField
+	RoomNumber           models.SearchCriteriaField
+	RoomType             models.SearchCriteriaField
+	Search               models.SearchCriteriaField
+	Segment              models.SearchCriteriaField
+	SourceImport         models.SearchCriteriaField
+	SourceType           models.SearchCriteriaField
+	StayLength           models.SearchCriteriaField
+	Tags                 models.SearchCriteriaField
+	TouristTax           models.SearchCriteriaField
+	TwitterFollowers     models.SearchCriteriaField
+	UpdatedAt            models.SearchCriteriaField
+	Weekend              models.SearchCriteriaField
+	ZipCode              models.SearchCriteriaField
+}
+
+// Field contains the well-known search fields.
+var Field = fields{
+	App:                  models.SearchCriteriaFieldApp,
+	Arrival:              models.SearchCriteriaFieldArrival,
+	ArrivalDOW:           models.SearchCriteriaFieldArrivalDOW,
+	Balance:              models.SearchCriteriaFieldBalance,
+	Birthdate:            models.SearchCriteriaFieldBirthdate,
+	Birthday:             models.SearchCriteriaFieldBirthday,
+	BookingDate:          models.SearchCriteriaFieldBookingDate,
+	BookingID:            models.SearchCriteriaFieldBookingID,
+	BookingPurpose:       models.SearchCriteriaFieldBookingPurpose,
+	BookingStatus:        models.SearchCriteriaFieldBookingStatus,
+	BookingStatusDiff:    models.SearchCriteriaFieldBookingStatusDiff,
+	BookingTags:          models.SearchCriteriaFieldBookingTags,
+	BookingWindow:        models.SearchCriteriaFieldBookingWindow,
+	CampaignApp:          models.SearchCriteriaFieldCampaignApp,
+	CampaignEmail:        models.SearchCriteriaFieldCampaignEmail,
+	CampaignNewsletter:   models.SearchCriteriaFieldCampaignNewsletter,
+	CampaignScheduled:    models.SearchCriteriaFieldCampaignScheduled,
+	CampaignSearch:       models.SearchCriteriaFieldCampaignSearch,
+	CampaignSMS:          models.SearchCriteriaFieldCampaignSMS,
+	CampaignWhatsApp:     models.SearchCriteriaFieldCampaignWhatsApp,
+	Channel:              models.SearchCriteriaFieldChannel,
+	City:                 models.SearchCriteriaFieldCity,
+	Company:              models.SearchCriteriaFieldCompany,
+	Country:              models.SearchCriteriaFieldCountry,
+	CustomFieldsBooking:  models.SearchCriteriaFieldCustomFieldsBooking,
+	CustomFieldsGuest:    models.SearchCriteriaFieldCustomFieldsGuest,
+	Departure:            models.SearchCriteriaFieldDeparture,
+	DepartureDOW:         models.SearchCriteriaFieldDepartureDOW,
+	Email:                models.SearchCriteriaFieldEmail,
+	EmailCollected:       models.SearchCriteriaFieldEmailCollected,
+	EmailDomain:          models.SearchCriteriaFieldEmailDomain,
+	EntityGuestUUIDs:     models.SearchCriteriaFieldEntityGuestUUIDs,
+	EntityID:             models.SearchCriteriaFieldEntityID,
+	EntityObjectUUID:     models.SearchCriteriaFieldEntityObjectUUID,
+	Fare:                 models.SearchCriteriaFieldFare,
+	FareAvg:              models.SearchCriteriaFieldFareAvg,
+	FareCode:             models.SearchCriteriaFieldFareCode,
+	FareRatio:            models.SearchCriteriaFieldFareRatio,
+	FareSum:              models.SearchCriteriaFieldFareSum,
+	Fidelity:             models.SearchCriteriaFieldFidelity,
+	FidelityStatus:       models.SearchCriteriaFieldFidelityStatus,
+	GuestTags:            models.SearchCriteriaFieldGuestTags,
+	GuestUUID:            models.SearchCriteriaFieldGuestUUID,
+	HasFacebook:          models.SearchCriteriaFieldHasFacebook,
+	HasLinkedIn:          models.SearchCriteriaFieldHasLinkedIn,
+	HasPersonnalEmail:    models.SearchCriteriaFieldHasPersonnalEmail,
+	HasTwitter:           models.SearchCriteriaFieldHasTwitter,
+	IDMasterResa:         models.SearchCriteriaFieldIDMasterResa,
+	IDResa:               models.SearchCriteriaFieldIDResa,
+	InStay:               models.SearchCriteriaFieldInStay,
+	InStayDate:           models.SearchCriteriaFieldInStayDate,
+	InStayDates:          models.SearchCriteriaFieldInStayDates,
+	Langs:                models.SearchCriteriaFieldLangs,
+	LastConnexion:        models.SearchCriteriaFieldLastConnexion,
+	LastName:             models.SearchCriteriaFieldLastName,
+	LinkedInFollowers:    models.SearchCriteriaFieldLinkedInFollowers,
+	Metadata:             models.SearchCriteriaFieldMetadata,
+	Nationality:          models.SearchCriteriaFieldNationality,
+	NbNextStays:          models.SearchCriteriaFieldNbNextStays,
+	NbPreviousStays:      models.SearchCriteriaFieldNbPreviousStays,
+	NbStays:              models.SearchCriteriaFieldNbStays,
+	NextStay:             models.SearchCriteriaFieldNextStay,
+	OptinAuto:            models.SearchCriteriaFieldOptinAuto,
+	OptinCustomerAccount: models.SearchCriteriaFieldOptinCustomerAccount,
+	OptinLoyalty:         models.SearchCriteriaFieldOptinLoyalty,
+	OptinMarketing:       models.SearchCriteriaFieldOptinMarketing,
+	OptinSendInBlue:      models.SearchCriteriaFieldOptinSendInBlue,
+	Partner:              models.SearchCriteriaFieldPartner,
+	PaxAdults:            models.SearchCriteriaFieldPaxAdults,
+	PaxBabies:            models.SearchCriteriaFieldPaxBabies,
+	PaxChildren:          models.SearchCriteriaFieldPaxChildren,
+	Phone:                models.SearchCriteriaFieldPhone,
+	PreferredEmail:       models.SearchCriteriaFieldPreferredEmail,
+	PreviousStay:         models.SearchCriteriaFieldPreviousStay,
+	PushNotification:     models.SearchCriteriaFieldPushNotification,
+	RoomNumber:           models.SearchCriteriaFieldRoomNumber,
+	RoomType:             models.SearchCriteriaFieldRoomType,
+	Search:               models.SearchCriteriaFieldSearch,
+	Segment:              models.SearchCriteriaFieldSegment,
+	SourceImport:         models.SearchCriteriaFieldSourceImport,
+	SourceType:           models.SearchCriteriaFieldSourceType,
+	StayLength:           models.SearchCriteriaFieldStayLength,
+	Tags:                 models.SearchCriteriaFieldTags,
+	TouristTax:           models.SearchCriteriaFieldTouristTax,
+	TwitterFollowers:     models.SearchCriteriaFieldTwitterFollowers,
+	UpdatedAt:            models.SearchCriteriaFieldUpdatedAt,
+	Weekend:              models.SearchCriteriaFieldWeekend,
+	ZipCode:              models.SearchCriteriaFieldZipCode,
+}
+
+// Query being built. Queries are immutable: every method returns a new query.
+type Query struct {
+	logic  models.SearchGuestsLogic
+	groups []*models.SearchCriterion
+	err    error
+}
+
+// Where starts a criteria on the given field.
+func Where(field models.SearchCriteriaField) *CriteriaBuilder { return &CriteriaBuilder{field: field} }
+
+// AllOf groups the criteria of the given queries, which must all match.
+func AllOf(queries ...*Query) *Query { return newGroupQuery(models.SearchGuestsLogicAnd, queries) }
+
+// AnyOf groups the criteria of the given queries, at least one of which must match.
+func AnyOf(queries ...*Query) *Query { return newGroupQuery(models.SearchGuestsLogicOr, queries) }
+
+// And combines the query with the given ones, which must all match.
+func (q *Query) And(queries ...*Query) *Query { return q.combine(models.SearchGuestsLogicAnd, queries) }
+
+// Or combines the query with the given ones, at least one of which must match.
+func (q *Query) Or(queries ...*Query) *Query { return q.combine(models.SearchGuestsLogicOr, queries) }
+
+// Build the search conditions. It fails if the query cannot be represented with groups of criteria (e.g. an OR
+// between groups mixed with an AND).
+func (q *Query) Build() (*models.SearchConditions, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	result := &models.SearchConditions{Logic: string(q.logicOrDefault()), Criteria: []*models.SearchCriterion{}}
+	for _, group := range q.groups {
+		result.Criteria = append(result.Criteria, &models.SearchCriterion{
+			Logic:    group.Logic,
+			Criteria: append([]*models.SearchCriteria{}, group.Criteria...),
+		})
+	}
+
+	return result, nil
+}
+
+// BuildGuestsQuery builds the query used to search guests.
+func (q *Query) BuildGuestsQuery() (*models.SearchGuestsQuery, error) {
+	conditions, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SearchGuestsQuery{
+		Logic:    models.SearchGuestsLogic(conditions.Logic),
+		Criteria: []*models.SearchGuestsCriteria{},
+	}
+	for _, criterion := range conditions.Criteria {
+		group := &models.SearchGuestsCriteria{
+			Logic:    models.SearchGuestsLogic(criterion.Logic),
+			Criteria: []*models.SearchGuestsSubCriteria{},
+		}
+		for _, criteria := range criterion.Criteria {
+			group.Criteria = append(group.Criteria, &models.SearchGuestsSubCriteria{
+				Field:    string(criteria.Field),
+				Operator: models.SearchGuestOperator(criteria.Operator),
+				Value:    criteria.Value,
+			})
+		}
+
+		result.Criteria = append(result.Criteria, group)
+	}
+
+	return result, nil
+}
+
+// String returns the query in the textual language, or the error preventing it from being built.
+func (q *Query) String() string {
+	conditions, err := q.Build()
+	if err != nil {
+		return err.Error()
+	}
+
+	return Format(conditions)
+}
+
+func (q *Query) logicOrDefault() models.SearchGuestsLogic {
+	if q.logic == "" {
+		return models.SearchGuestsLogicAnd
+	}
+
+	return q.logic
+}
+
+func (q *Query) combine(logic models.SearchGuestsLogic, queries []*Query) *Query {
+	if q.err != nil {
+		return q
+	}
+
+	if len(q.groups) > 1 && q.logicOrDefault() != logic {
+		return newQueryError("cannot combine groups with both %s and %s", q.logicOrDefault(), logic)
+	}
+
+	result := &Query{logic: logic, groups: append([]*models.SearchCriterion{}, q.groups...)}
+
+	for _, query := range queries {
+		if query.err != nil {
+			return query
+		}
+
+		if len(query.groups) > 1 && query.logicOrDefault() != logic {
+			return newQueryError("cannot nest groups combined with %s in groups combined with %s",
+				query.logicOrDefault(), logic,
+			)
+		}
+
+		result.groups = append(result.groups, query.groups...)
+	}
+
+	return result
+}
+
+func newGroupQuery(logic models.SearchGuestsLogic, queries []*Query) *Query {
+	group := &models.SearchCriterion{Logic: string(logic), Criteria: []*models.SearchCriteria{}}
+
+	for _, query := range queries {
+		if query.err != nil {
+			return query
+		}
+
+		if len(query.groups) > 1 {
+			return newQueryError("cannot nest several groups in a group")
+		}
+
+		for _, queryGroup := range query.groups {
+			if len(queryGroup.Criteria) > 1 && !strings.EqualFold(queryGroup.Logic, string(logic)) {
+				return newQueryError("cannot nest a group combined with %s in a group combined with %s",
+					queryGroup.Logic, logic,
+				)
+			}
+
+			group.Criteria = append(group.Criteria, queryGroup.Criteria...)
+		}
+	}
+
+	return &Query{groups: []*models.SearchCriterion{group}}
+}
+
+func newQueryError(format string, args ...any) *Query {
+	return &Query{err: &errors.Error{
+		Code:    errors.CodeInvalid,
+		Message: "Invalid search query: " + fmt.Sprintf(format, args...),
+	}}
+}
+
+// CriteriaBuilder builds a criteria on a field.
+type CriteriaBuilder struct {
+	field models.SearchCriteriaField
+}
+
+// Is builds a criteria with the given operator and value. It can be used with operators having no dedicated method.
+func (b *CriteriaBuilder) Is(operator models.SearchCriteriaOperator, value any) *Query {
+	return &Query{groups: []*models.SearchCriterion{{
+		Logic:    string(models.SearchGuestsLogicAnd),
+		Criteria: []*models.SearchCriteria{{Field: b.field, Operator: operator, Value: value}},
+	}}}
+}
+
+// Equals matches any of the given values.
+func (b *CriteriaBuilder) Equals(values ...any) *Query {
+	return b.Is(models.SearchOperatorEquals, singleOrSlice(values))
+}
+
+// NotEquals matches none of the given values.
+func (b *CriteriaBuilder) NotEquals(values ...any) *Query {
+	return b.Is(models.SearchOperatorNotEquals, singleOrSlice(values))
+}
+
+func (b *CriteriaBuilder) Contains(value string) *Query {
+	return b.Is(models.SearchOperatorContains, value)
+}
+
+func (b *CriteriaBuilder) NotContains(value string) *Query {
+	return b.Is(models.SearchOperatorNotContains, value)
+}
+
+func (b *CriteriaBuilder) StartsWith(value string) *Query {
+	return b.Is(models.SearchOperatorStartsWith, value)
+}
+
+func (b *CriteriaBuilder) NotStartsWith(value string) *Query {
+	return b.Is(models.SearchOperatorNotStartsWith, value)
+}
+
+func (b *CriteriaBuilder) LessThan(value float64) *Query {
+	return b.Is(models.SearchOperatorInferior, value)
+}
+
+func (b *CriteriaBuilder) GreaterThan(value float64) *Query {
+	return b.Is(models.SearchOperatorSuperior, value)
+}
+
+// Between matches the values between the given bounds, included. Bounds are numbers or dates.
+func (b *CriteriaBuilder) Between(from, to any) *Query {
+	return b.Is(models.SearchOperatorRange, []any{from, to})
+}
+
+func (b *CriteriaBuilder) Filled() *Query    { return b.Is(models.SearchOperatorFilled, nil) }
+func (b *CriteriaBuilder) NotFilled() *Query { return b.Is(models.SearchOperatorNotFilled, nil) }
+func (b *CriteriaBuilder) Yes() *Query       { return b.Is(models.SearchOperatorYes, nil) }
+func (b *CriteriaBuilder) No() *Query        { return b.Is(models.SearchOperatorNo, nil) }
+
+// On matches the given day. Days are formatted as "2006-01-02" or are keywords (e.g. "today").
+func (b *CriteriaBuilder) On(day string) *Query { return b.Is(models.SearchOperatorDateEqual, day) }
+
+func (b *CriteriaBuilder) After(day string) *Query {
+	return b.Is(models.SearchOperatorDateAfterThan, day)
+}
+
+func (b *CriteriaBuilder) OnOrAfter(day string) *Query {
+	return b.Is(models.SearchOperatorDateAfterThanEqual, day)
+}
+
+func (b *CriteriaBuilder) Before(day string) *Query {
+	return b.Is(models.SearchOperatorDateBeforeThan, day)
+}
+
+func (b *CriteriaBuilder) OnOrBefore(day string) *Query {
+	return b.Is(models.SearchOperatorDateBeforeThanEqual, day)
+}
+
+// InMoreThan matches the dates more than the given number of days away from today.
+func (b *CriteriaBuilder) InMoreThan(days int) *Query {
+	return b.Is(models.SearchOperatorDateAwayMore, float64(days))
+}
+
+// InLessThan matches the dates less than the given number of days away from today.
+func (b *CriteriaBuilder) InLessThan(days int) *Query {
+	return b.Is(models.SearchOperatorDateAwayLess, float64(days))
+}
+
+// OnDaysOfWeek matches the given days of week, from "1" (Monday) to "7" (Sunday).
+func (b *CriteriaBuilder) OnDaysOfWeek(days ...string) *Query {
+	values := []any{}
+	for _, day := range days {
+		values = append(values, day)
+	}
+
+	return b.Is(models.SearchOperatorDow, values)
+}
+
+func singleOrSlice(values []any) any {
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return values
+}
@@ -0,0 +1,116 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/loungeup/go-loungeup/client/models"
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/loungeup/go-loungeup/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	conditions, err := search.Where(search.Field.Country).Equals("FR").
+		And(search.AnyOf(
+			search.Where(search.Field.NbStays).GreaterThan(2),
+			search.Where(search.Field.Tags).Contains("vip"),
+		)).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, &models.SearchConditions{
+		Logic: "AND",
+		Criteria: []*models.SearchCriterion{
+			{Logic: "AND", Criteria: []*models.SearchCriteria{
+				{Field: models.SearchCriteriaFieldCountry, Operator: models.SearchOperatorEquals, Value: "FR"},
+			}},
+			{Logic: "OR", Criteria: []*models.SearchCriteria{
+				{Field: models.SearchCriteriaFieldNbStays, Operator: models.SearchOperatorSuperior, Value: float64(2)},
+				{Field: models.SearchCriteriaFieldTags, Operator: models.SearchOperatorContains, Value: "vip"},
+			}},
+		},
+	}, conditions)
+
+	t.Run("mixed logics", func(t *testing.T) {
+		_, err := search.Where(search.Field.City).Equals("Paris").
+			Or(search.Where(search.Field.City).Equals("Lyon")).
+			And(search.Where(search.Field.Weekend).Yes()).
+			Build()
+		assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("guests query", func(t *testing.T) {
+		query, err := search.Where(search.Field.Email).Filled().BuildGuestsQuery()
+		require.NoError(t, err)
+		assert.Equal(t, &models.SearchGuestsQuery{
+			Logic: models.SearchGuestsLogicAnd,
+			Criteria: []*models.SearchGuestsCriteria{{
+				Logic:    models.SearchGuestsLogicAnd,
+				Criteria: []*models.SearchGuestsSubCriteria{{Field: "email", Operator: "F"}},
+			}},
+		}, query)
+	})
+}
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want *search.Query
+	}{
+		"example": {
+			in: `country = "FR" AND (nbstays > 2 OR tags % "vip")`,
+			want: search.Where(search.Field.Country).Equals("FR").And(search.AnyOf(
+				search.Where(search.Field.NbStays).GreaterThan(2),
+				search.Where(search.Field.Tags).Contains("vip"),
+			)),
+		},
+		"or groups": {
+			in: `(arrival &>= "today" AND arrival - 7) OR instay Y OR email @`,
+			want: search.AllOf(
+				search.Where(search.Field.Arrival).OnOrAfter("today"),
+				search.Where(search.Field.Arrival).InLessThan(7),
+			).Or(
+				search.Where(search.Field.InStay).Yes(),
+				search.Where(search.Field.Email).Is(models.SearchOperatorAnonymous, nil),
+			),
+		},
+		"values": {
+			in: `langs = ["fr","en"] AND fare RANGE [10,20.5] AND arrivaldow DOW ["6","7"] AND company !F`,
+			want: search.Where(search.Field.Langs).Equals("fr", "en").And(
+				search.Where(search.Field.Fare).Between(float64(10), 20.5),
+				search.Where(search.Field.ArrivalDOW).OnDaysOfWeek("6", "7"),
+				search.Where(search.Field.Company).NotFilled(),
+			),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			want, err := tt.want.Build()
+			require.NoError(t, err)
+
+			got, err := search.Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+			assert.Equal(t, tt.in, search.Format(got))
+			assert.Equal(t, tt.in, tt.want.String())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`country`,
+		`country ~ "FR"`,
+		`country = "FR`,
+		`country = "FR" AND city = "Paris" OR zipcode = "75"`,
+		`(country = "FR" AND city = "Paris"`,
+		`country = "FR" XOR city = "Paris"`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			_, err := search.Parse(in)
+			assert.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+		})
+	}
+}
@@ -0,0 +1,324 @@
+// Package validation validates structures (e.g. the params of RES call requests) with the rules of their "validate"
+// struct tags. Rules are separated by commas:
+//
+//	type Params struct {
+//		Name     string   `json:"name" validate:"required,max=64"`
+//		Quantity int      `json:"quantity" validate:"min=1,max=10"`
+//		Status   string   `json:"status" validate:"enum=draft|published"`
+//		EntityID string   `json:"entityId" validate:"required,uuid"`
+//		StartsAt string   `json:"startsAt" validate:"rfc3339"`
+//		Tags     []string `json:"tags" validate:"max=5"`
+//		Address  *Address `json:"address"`
+//	}
+//
+// Except for required, rules are only checked on non-zero values, but min and max also bound zero numbers: an optional
+// number must be a pointer, which is only checked when it is set. Min and max bound numbers, or the length of strings,
+// slices and maps. Nested structures, and slices of structures, are validated recursively. Fields are named after their
+// JSON name.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/loungeup/go-loungeup/errors"
+)
+
+const (
+	tagName = "validate"
+
+	ruleEnum     = "enum"
+	ruleMax      = "max"
+	ruleMin      = "min"
+	ruleRequired = "required"
+	ruleRFC3339  = "rfc3339"
+	ruleUUID     = "uuid"
+)
+
+// Validate the given value, which should be a structure or a pointer to a structure. It returns an error with the
+// errors.CodeInvalid code and the invalid fields, or an internal error if a rule is malformed.
+func Validate(value any) error {
+	fieldErrors := []*errors.FieldError{}
+	if err := validateValue(reflect.ValueOf(value), "", &fieldErrors); err != nil {
+		return err
+	}
+
+	if len(fieldErrors) > 0 {
+		return &errors.Error{Code: errors.CodeInvalid, Message: "Invalid params", Fields: fieldErrors}
+	}
+
+	return nil
+}
+
+func validateValue(value reflect.Value, path string, fieldErrors *[]*errors.FieldError) error {
+	value = indirect(value)
+
+	switch value.Kind() {
+	case reflect.Struct:
+		fields, err := readStructFields(value.Type())
+		if err != nil {
+			return err
+		}
+
+		for _, field := range fields {
+			// A field promoted from a nil embedded pointer is considered as zero.
+			fieldValue, _ := value.FieldByIndexErr(field.index)
+
+			if err := field.validate(fieldValue, joinPath(path, field.name), fieldErrors); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if indirectType(value.Type().Elem()).Kind() != reflect.Struct {
+			return nil
+		}
+
+		for i := range value.Len() {
+			if err := validateValue(value.Index(i), path+"["+strconv.Itoa(i)+"]", fieldErrors); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type structField struct {
+	index []int
+	name  string
+	rules []*rule
+}
+
+func (f *structField) validate(value reflect.Value, path string, fieldErrors *[]*errors.FieldError) error {
+	// Non-nil pointers are set, even if they point to zero values.
+	if !value.IsValid() || value.IsZero() {
+		if slices.ContainsFunc(f.rules, func(rule *rule) bool { return rule.name == ruleRequired }) {
+			*fieldErrors = append(*fieldErrors, &errors.FieldError{Field: path, Message: "Required"})
+
+			return nil
+		}
+
+		// Zero numbers are values like any other, so their bounds are checked.
+		if !value.IsValid() || !isNumberKind(value.Kind()) {
+			return nil
+		}
+	}
+
+	value = indirect(value)
+
+	for _, rule := range f.rules {
+		message, err := rule.check(value)
+		if err != nil {
+			return fmt.Errorf("could not check rule %q of field %q: %w", rule.name, path, err)
+		}
+
+		if message != "" {
+			*fieldErrors = append(*fieldErrors, &errors.FieldError{Field: path, Message: message})
+
+			// Only the first broken rule of a field is reported.
+			return nil
+		}
+	}
+
+	return validateValue(value, path, fieldErrors)
+}
+
+type rule struct{ name, argument string }
+
+// check the given value against the rule. It returns a message if the value breaks the rule, or an error if the rule
+// does not apply to the value.
+func (r *rule) check(value reflect.Value) (string, error) {
+	switch r.name {
+	case ruleRequired:
+		return "", nil
+	case ruleMin, ruleMax:
+		return r.checkBound(value)
+	case ruleEnum:
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported kind %s", value.Kind())
+		}
+
+		if values := strings.Split(r.argument, "|"); !slices.Contains(values, value.String()) {
+			return "Must be one of: " + strings.Join(values, ", "), nil
+		}
+	case ruleRFC3339:
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported kind %s", value.Kind())
+		}
+
+		if _, err := time.Parse(time.RFC3339, value.String()); err != nil {
+			return "Must be an RFC 3339 date", nil
+		}
+	case ruleUUID:
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported kind %s", value.Kind())
+		}
+
+		if err := uuid.Validate(value.String()); err != nil {
+			return "Must be a UUID", nil
+		}
+	default:
+		return "", fmt.Errorf("unknown rule")
+	}
+
+	return "", nil
+}
+
+func (r *rule) checkBound(value reflect.Value) (string, error) {
+	bound, err := strconv.ParseFloat(r.argument, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid bound: %w", err)
+	}
+
+	var (
+		got    float64
+		suffix string
+	)
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = value.Float()
+	case reflect.String:
+		got, suffix = float64(utf8.RuneCountInString(value.String())), " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		got, suffix = float64(value.Len()), " elements long"
+	default:
+		return "", fmt.Errorf("unsupported kind %s", value.Kind())
+	}
+
+	switch {
+	case r.name == ruleMin && got < bound:
+		return "Must be at least " + r.argument + suffix, nil
+	case r.name == ruleMax && got > bound:
+		return "Must be at most " + r.argument + suffix, nil
+	default:
+		return "", nil
+	}
+}
+
+// structFieldsCache stores the fields of the structures by type, so tags are only parsed once.
+var structFieldsCache sync.Map
+
+func readStructFields(structType reflect.Type) ([]*structField, error) {
+	if result, ok := structFieldsCache.Load(structType); ok {
+		return result.([]*structField), nil //nolint:forcetypeassert
+	}
+
+	result, err := appendStructFields(nil, structType, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	structFieldsCache.Store(structType, result)
+
+	return result, nil
+}
+
+func appendStructFields(fields []*structField, structType reflect.Type, index []int) ([]*structField, error) {
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(slices.Clone(index), i)
+
+		// Like with JSON, the fields of embedded structures without name are promoted.
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			var err error
+			if fields, err = appendStructFields(fields, indirectType(field.Type), fieldIndex); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		rules, err := parseRules(field.Tag.Get(tagName))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse rules of field %q: %w", field.Name, err)
+		}
+
+		fields = append(fields, &structField{index: fieldIndex, name: name, rules: rules})
+	}
+
+	return fields, nil
+}
+
+func parseRules(tag string) ([]*rule, error) {
+	result := []*rule{}
+
+	if tag == "" {
+		return result, nil
+	}
+
+	for _, rawRule := range strings.Split(tag, ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(rawRule), "=")
+
+		switch name {
+		case ruleRequired, ruleRFC3339, ruleUUID:
+		case ruleEnum, ruleMax, ruleMin:
+			if argument == "" {
+				return nil, fmt.Errorf("missing argument of rule %q", name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+
+		result = append(result, &rule{name: name, argument: argument})
+	}
+
+	return result, nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/loungeup/go-loungeup/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	PostalCode string `json:"postalCode" validate:"required,max=5"`
+}
+
+type testAudit struct {
+	CreatedAt string `json:"createdAt" validate:"rfc3339"`
+}
+
+type testParams struct {
+	testAudit
+
+	Name     string         `json:"name" validate:"required,max=8"`
+	Quantity int            `json:"quantity,omitempty" validate:"min=1,max=10"`
+	Limit    *int           `json:"limit" validate:"min=1"`
+	Status   string         `json:"status" validate:"enum=draft|published"`
+	EntityID string         `json:"entityId" validate:"uuid"`
+	Tags     []string       `json:"tags" validate:"max=2"`
+	Address  *testAddress   `json:"address"`
+	Items    []*testAddress `json:"items"`
+	Ignored  string         `json:"-" validate:"required"`
+	Untagged string         `validate:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		in   *testParams
+		want []*errors.FieldError
+	}{
+		"valid": {
+			in: &testParams{
+				testAudit: testAudit{CreatedAt: "2024-03-15T10:30:00Z"},
+				Name:      "John",
+				Quantity:  5,
+				Status:    "draft",
+				EntityID:  "7b5d9c4e-1f2a-4b3c-8d9e-0a1b2c3d4e5f",
+				Tags:      []string{"a", "b"},
+				Address:   &testAddress{PostalCode: "75001"},
+				Items:     []*testAddress{{PostalCode: "69001"}},
+				Untagged:  "foo",
+			},
+		},
+		"zero": {
+			in: &testParams{},
+			want: []*errors.FieldError{
+				{Field: "name", Message: "Required"},
+				{Field: "quantity", Message: "Must be at least 1"},
+				{Field: "Untagged", Message: "Required"},
+			},
+		},
+		"zero numbers": {
+			in: &testParams{
+				Name:     "John",
+				Limit:    new(int),
+				Untagged: "foo",
+			},
+			want: []*errors.FieldError{
+				{Field: "quantity", Message: "Must be at least 1"},
+				{Field: "limit", Message: "Must be at least 1"},
+			},
+		},
+		"invalid": {
+			in: &testParams{
+				testAudit: testAudit{CreatedAt: "2024-03-15"},
+				Name:      "John Fitzgerald",
+				Quantity:  11,
+				Status:    "archived",
+				EntityID:  "foo",
+				Tags:      []string{"a", "b", "c"},
+				Address:   &testAddress{},
+				Items:     []*testAddress{{PostalCode: "69001"}, {PostalCode: "690010"}},
+				Untagged:  "foo",
+			},
+			want: []*errors.FieldError{
+				{Field: "createdAt", Message: "Must be an RFC 3339 date"},
+				{Field: "name", Message: "Must be at most 8 characters long"},
+				{Field: "quantity", Message: "Must be at most 10"},
+				{Field: "status", Message: "Must be one of: draft, published"},
+				{Field: "entityId", Message: "Must be a UUID"},
+				{Field: "tags", Message: "Must be at most 2 elements long"},
+				{Field: "address.postalCode", Message: "Required"},
+				{Field: "items[1].postalCode", Message: "Must be at most 5 characters long"},
+			},
+		},
+	}
+
+	for test, tt := range tests {
+		t.Run(test, func(t *testing.T) {
+			err := Validate(tt.in)
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Equal(t, errors.CodeInvalid, errors.ErrorCode(err))
+			assert.Equal(t, tt.want, err.(*errors.Error).Fields)
+		})
+	}
+}
+
+func TestValidateMalformedRules(t *testing.T) {
+	for test, in := range map[string]any{
+		"unknown rule": &struct {
+			Foo string `validate:"foo"`
+		}{Foo: "bar"},
+		"missing argument": &struct {
+			Foo string `validate:"min"`
+		}{Foo: "bar"},
+		"unsupported kind": &struct {
+			Foo int `validate:"uuid"`
+		}{Foo: 1},
+	} {
+		t.Run(test, func(t *testing.T) {
+			assert.Equal(t, errors.CodeInternal, errors.ErrorCode(Validate(in)))
+		})
+	}
+}